@@ -30,6 +30,7 @@ func upsert(n *Node23, kvItems KeyValues, stats *Stats) (nodes []*Node23, newFir
 	if n == nil {
 		n = makeEmptyLeafNode()
 	}
+	n.load()
 	if n.isLeaf {
 		return upsertLeaf(n, kvItems, stats)
 	} else {
@@ -112,12 +113,12 @@ func upsertInternal(n *Node23, kvItems KeyValues, stats *Stats) (nodes []*Node23
 				// Handle newFirstKey here
 				previousChild := n.children[i-1]
 				if previousChild.isLeaf {
-					ensure(len(previousChild.keys) > 0, "upsertInternal: previousChild has no keys")
+					ensure(previousChild.keyCount() > 0, "upsertInternal: previousChild has no keys")
 					if previousChild.nextKey() != childNewFirstKey {
 						previousChild.setNextKey(childNewFirstKey, stats)
 					}
 				} else {
-					ensure(len(previousChild.children) > 0, "upsertInternal: previousChild has no children")
+					ensure(previousChild.childrenCount() > 0, "upsertInternal: previousChild has no children")
 					lastLeaf := previousChild.lastLeaf()
 					if lastLeaf.nextKey() != childNewFirstKey {
 						lastLeaf.setNextKey(childNewFirstKey, stats)
@@ -327,6 +328,7 @@ func del(n *Node23, keysToDelete []Felt, stats *Stats) (deleted *Node23, nextKey
 	if n == nil {
 		return n, nil, intermediateKeys
 	}
+	n.load()
 	if n.isLeaf {
 		return deleteLeaf(n, keysToDelete, stats)
 	} else {
@@ -440,12 +442,12 @@ func deleteInternal(n *Node23, keysToDelete []Felt, stats *Stats) (deleted *Node
 			}
 			if child == nil || childNextKey != nil {
 				if previousChild.isLeaf {
-					ensure(len(previousChild.keys) > 0, "delete: previousChild has no keys")
+					ensure(previousChild.keyCount() > 0, "delete: previousChild has no keys")
 					if previousChild.nextKey() != childNextKey {
 						previousChild.setNextKey(childNextKey, stats)
 					}
 				} else {
-					ensure(len(previousChild.children) > 0, "delete: previousChild has no children")
+					ensure(previousChild.childrenCount() > 0, "delete: previousChild has no children")
 					lastLeaf := previousChild.lastLeaf()
 					if lastLeaf.nextKey() != childNextKey {
 						lastLeaf.setNextKey(childNextKey, stats)
@@ -499,6 +501,8 @@ func deleteInternal(n *Node23, keysToDelete []Felt, stats *Stats) (deleted *Node
 }
 
 func mergeLeft2Right(left, right *Node23, stats *Stats) (newLeft, newRight *Node23) {
+	left.load()
+	right.load()
 	ensure(!left.isLeaf, "mergeLeft2Right: left is leaf")
 	ensure(left.childrenCount() > 0, "mergeLeft2Right: left has no children")
 
@@ -556,6 +560,8 @@ func mergeLeft2Right(left, right *Node23, stats *Stats) (newLeft, newRight *Node
 }
 
 func mergeRight2Left(left, right *Node23, stats *Stats) (newLeft, newRight *Node23) {
+	left.load()
+	right.load()
 	ensure(!right.isLeaf, "mergeRight2Left: right is leaf")
 	ensure(right.childrenCount() > 0, "mergeRight2Left: right has no children")
 
@@ -781,7 +787,9 @@ func update3Node(n *Node23, newKeys []*Felt, nextKey *Felt, intermediateKeys []*
 func demote(node *Node23, nextKey *Felt, intermediateKeys []*Felt, stats *Stats) (*Node23, *Felt) {
 	if node == nil {
 		return nil, nextKey
-	} else if len(node.children) == 0 {
+	}
+	node.load()
+	if len(node.children) == 0 {
 		if len(node.keys) == 0 {
 			return nil, nextKey
 		} else {
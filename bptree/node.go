@@ -83,6 +83,9 @@ type Node23 struct {
 	isLeaf   bool
 	exposed  bool
 	updated  bool
+	id       uint64      // id of stored node, zero if node has never been stored
+	hash     []byte      // hash of stored node, valid until node is changed
+	loader   *nodeLoader // not nil until node content is loaded from store
 }
 
 func (n *Node23) String() string {
@@ -133,7 +136,20 @@ func promote(nodes []*Node23, intermediateKeys []*Felt, stats *Stats) *Node23 {
 	return promotedRoot
 }
 
+// load reads node content from store if node is not loaded yet, store errors are raised as panics of loadError type
+func (n *Node23) load() {
+	if n.loader == nil {
+		return
+	}
+	n.loader.loadNode(n)
+	n.loader = nil
+}
+
 func (n *Node23) reset() {
+	if n.loader != nil {
+		// Not loaded nodes cannot be exposed or updated
+		return
+	}
 	n.exposed = false
 	n.updated = false
 	if !n.isLeaf {
@@ -144,6 +160,7 @@ func (n *Node23) reset() {
 }
 
 func (n *Node23) isValid() (bool, error) {
+	n.load()
 	ensure(n.exposed || !n.updated, "isValid: node is not exposed but updated")
 	if n.isLeaf {
 		return n.isValidLeaf()
@@ -224,28 +241,34 @@ func (n *Node23) isValidInternal() (bool, error) {
 }
 
 func (n *Node23) keyCount() int {
+	n.load()
 	return len(n.keys)
 }
 
 func (n *Node23) childrenCount() int {
+	n.load()
 	return len(n.children)
 }
 
 func (n *Node23) valueCount() int {
+	n.load()
 	return len(n.values)
 }
 
 func (n *Node23) firstKey() *Felt {
+	n.load()
 	ensure(len(n.keys) > 0, "firstKey: node has no key")
 	return n.keys[0]
 }
 
 func (n *Node23) firstValue() *Felt {
+	n.load()
 	ensure(len(n.values) > 0, "firstValue: node has no value")
 	return n.values[0]
 }
 
 func (n *Node23) firstChild() *Node23 {
+	n.load()
 	ensure(len(n.children) > 0, "firstChild: node has no children")
 	return n.children[0]
 }
@@ -263,6 +286,7 @@ func (n *Node23) firstLeaf() *Node23 {
 }
 
 func (n *Node23) lastChild() *Node23 {
+	n.load()
 	ensure(len(n.children) > 0, "lastChild: node has no children")
 	return n.children[len(n.children)-1]
 }
@@ -280,11 +304,13 @@ func (n *Node23) lastLeaf() *Node23 {
 }
 
 func (n *Node23) nextKey() *Felt {
+	n.load()
 	ensure(len(n.keys) > 0, "nextKey: node has no key")
 	return n.keys[len(n.keys)-1]
 }
 
 func (n *Node23) nextValue() *Felt {
+	n.load()
 	ensure(len(n.values) > 0, "nextValue: node has no value")
 	return n.values[len(n.values)-1]
 }
@@ -294,6 +320,7 @@ func (n *Node23) rawPointer() uintptr {
 }
 
 func (n *Node23) setNextKey(nextKey *Felt, stats *Stats) {
+	n.load()
 	ensure(len(n.keys) > 0, "setNextKey: node has no key")
 	n.keys[len(n.keys)-1] = nextKey
 	if !n.exposed {
@@ -306,6 +333,7 @@ func (n *Node23) setNextKey(nextKey *Felt, stats *Stats) {
 }
 
 func (n *Node23) canonicalKeys() []Felt {
+	n.load()
 	if n.isLeaf {
 		ensure(len(n.keys) > 0, "canonicalKeys: node has no key")
 		return deref(n.keys[:len(n.keys)-1])
@@ -315,6 +343,7 @@ func (n *Node23) canonicalKeys() []Felt {
 }
 
 func (n *Node23) hasKey(targetKey *Felt) bool {
+	n.load()
 	var keys []*Felt
 	if n.isLeaf {
 		ensure(len(n.keys) > 0, "hasKey: node has no key")
@@ -331,6 +360,7 @@ func (n *Node23) hasKey(targetKey *Felt) bool {
 }
 
 func (n *Node23) isEmpty() bool {
+	n.load()
 	if n.isLeaf {
 		// At least next key is always present
 		return n.keyCount() == 1
@@ -340,6 +370,7 @@ func (n *Node23) isEmpty() bool {
 }
 
 func (n *Node23) height() int {
+	n.load()
 	if n.isLeaf {
 		return 1
 	} else {
@@ -357,6 +388,7 @@ func (n *Node23) keysInLevelOrder() []Felt {
 }
 
 func (n *Node23) keysByLevel(level int) []Felt {
+	n.load()
 	if level == 0 {
 		return n.canonicalKeys()
 	} else {
@@ -372,6 +404,7 @@ func (n *Node23) keysByLevel(level int) []Felt {
 type Walker func(*Node23) interface{}
 
 func (n *Node23) walkPostOrder(w Walker) []interface{} {
+	n.load()
 	items := make([]interface{}, 0)
 	if !n.isLeaf {
 		for _, child := range n.children {
@@ -383,6 +416,22 @@ func (n *Node23) walkPostOrder(w Walker) []interface{} {
 	return items
 }
 
+// walkLoadedPostOrder is like walkPostOrder but it does not descend into nodes not loaded from store
+func (n *Node23) walkLoadedPostOrder(w Walker) []interface{} {
+	items := make([]interface{}, 0)
+	if n.loader != nil {
+		return items
+	}
+	if !n.isLeaf {
+		for _, child := range n.children {
+			childItems := child.walkLoadedPostOrder(w)
+			items = append(items, childItems...)
+		}
+	}
+	items = append(items, w(n))
+	return items
+}
+
 func (n *Node23) walkNodesPostOrder() []*Node23 {
 	nodeItems := n.walkPostOrder(func(n *Node23) interface{} { return n })
 	nodes := make([]*Node23, len(nodeItems))
@@ -393,6 +442,7 @@ func (n *Node23) walkNodesPostOrder() []*Node23 {
 }
 
 func (n *Node23) howManyHashes() uint {
+	n.load()
 	if n.isLeaf {
 		// all leaves except last one: 2 or 3 keys + 1 or 2 values => 3 or 5 data => 2 or 4 hashes
 		// last leaf: 1 or 2 keys + 1 or 2 values => 2 or 4 data => 1 or 3 hashes
@@ -430,6 +480,9 @@ func (n *Node23) howManyHashes() uint {
 }
 
 func (n *Node23) hashNode() []byte {
+	if n.loader != nil {
+		return n.hash
+	}
 	if n.isLeaf {
 		return n.hashLeaf()
 	} else {
@@ -0,0 +1,380 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package bptree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// PersistentTree23 is Tree23 which nodes are kept in NodeStore. Nodes are loaded lazily when bulk upsert/delete
+// reach them, changed nodes are written copy-on-write on Commit, so tree opened at any non-pruned version keeps
+// seeing the state committed at that version.
+type PersistentTree23 struct {
+	tree      *Tree23
+	store     NodeStore
+	loader    *nodeLoader
+	version   uint64
+	committed bool // version has been committed
+}
+
+// OpenTree23 opens tree at given committed version
+func OpenTree23(store NodeStore, version uint64) (*PersistentTree23, error) {
+	encodedRoot, err := store.GetRoot(version)
+	if err != nil {
+		return nil, err
+	}
+	if encodedRoot == nil {
+		return nil, fmt.Errorf("tree version %d not found", version)
+	}
+	t := &PersistentTree23{tree: NewEmptyTree23(), store: store, version: version, committed: true}
+	t.loader = newNodeLoader(store)
+	if t.tree.root, _, err = t.loader.decodeNodeRef(encodedRoot, 0); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// OpenLatestTree23 opens tree at latest committed version, empty tree is returned if there are no versions yet
+func OpenLatestTree23(store NodeStore) (*PersistentTree23, error) {
+	version, ok, err := store.LastVersion()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &PersistentTree23{tree: NewEmptyTree23(), store: store, loader: newNodeLoader(store)}, nil
+	}
+	return OpenTree23(store, version)
+}
+
+// Version returns version the tree has been opened at or committed last
+func (t *PersistentTree23) Version() uint64 {
+	return t.version
+}
+
+func (t *PersistentTree23) Upsert(kvItems KeyValues) error {
+	return t.UpsertWithStats(kvItems, &Stats{})
+}
+
+func (t *PersistentTree23) UpsertWithStats(kvItems KeyValues, stats *Stats) (err error) {
+	defer recoverLoad(&err)
+	t.tree.UpsertWithStats(kvItems, stats)
+	return nil
+}
+
+func (t *PersistentTree23) Delete(keysToDelete []Felt) error {
+	return t.DeleteWithStats(keysToDelete, &Stats{})
+}
+
+func (t *PersistentTree23) DeleteWithStats(keysToDelete []Felt, stats *Stats) (err error) {
+	defer recoverLoad(&err)
+	t.tree.DeleteWithStats(keysToDelete, stats)
+	return nil
+}
+
+func (t *PersistentTree23) RootHash() (rootHash []byte, err error) {
+	defer recoverLoad(&err)
+	return t.tree.RootHash(), nil
+}
+
+// IsValid checks 2-3-tree properties, it loads the whole tree
+func (t *PersistentTree23) IsValid() (valid bool, err error) {
+	defer recoverLoad(&err)
+	return t.tree.IsValid()
+}
+
+// WalkKeysPostOrder returns all keys of the tree, it loads the whole tree
+func (t *PersistentTree23) WalkKeysPostOrder() (keys []Felt, err error) {
+	defer recoverLoad(&err)
+	return t.tree.WalkKeysPostOrder(), nil
+}
+
+// Commit writes nodes changed since the tree was opened or committed last time and records the new root as given
+// version. Nodes replaced by this version are marked as stale to be removed by Prune once older versions are
+// not needed anymore. Loaded nodes are dropped from memory and get loaded again on demand.
+// Only the tree at latest version can be committed: versions form a line, not a tree of branches.
+func (t *PersistentTree23) Commit(version uint64) (err error) {
+	defer recoverLoad(&err)
+	lastVersion, ok, err := t.store.LastVersion()
+	if err != nil {
+		return err
+	}
+	if ok && (!t.committed || lastVersion != t.version) {
+		return fmt.Errorf("tree is not at the latest version %d and cannot be committed", lastVersion)
+	}
+	if t.committed && version <= t.version {
+		return fmt.Errorf("version %d must be greater than last committed version %d", version, t.version)
+	}
+
+	retained := make(map[uint64]struct{})
+	if t.tree.root != nil {
+		if _, err = t.writeNode(t.tree.root, retained); err != nil {
+			return err
+		}
+	}
+	stale := make([]uint64, 0)
+	for id := range t.loader.loaded {
+		if _, ok := retained[id]; !ok {
+			stale = append(stale, id)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i] < stale[j] })
+	if err = t.store.PutStale(version, stale); err != nil {
+		return err
+	}
+	if err = t.store.PutRoot(version, appendNodeRef(nil, t.tree.root)); err != nil {
+		return err
+	}
+	t.version, t.committed = version, true
+
+	t.loader = newNodeLoader(t.store)
+	if root := t.tree.root; root != nil {
+		t.tree.root = t.loader.stub(root.id, root.isLeaf, root.hash)
+	}
+	return nil
+}
+
+// Prune removes versions less than before together with the nodes reachable only from them
+func (t *PersistentTree23) Prune(before uint64) error {
+	return t.store.Prune(before)
+}
+
+// writeNode stores changed nodes of the subtree in post-order, returns true if node has been stored with new id
+func (t *PersistentTree23) writeNode(n *Node23, retained map[uint64]struct{}) (bool, error) {
+	if n.loader != nil {
+		retained[n.id] = struct{}{}
+		return false, nil
+	}
+	changed := n.id == 0 || n.exposed || n.updated
+	for _, child := range n.children {
+		childChanged, err := t.writeNode(child, retained)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || childChanged
+	}
+	if !changed {
+		retained[n.id] = struct{}{}
+		return false, nil
+	}
+	if n.isLeaf {
+		n.hash = n.hashLeaf()
+	} else {
+		n.hash = hashStoredChildren(n.children)
+	}
+	id, err := t.store.NewNodeID()
+	if err != nil {
+		return false, err
+	}
+	n.id = id
+	return true, t.store.PutNode(id, encodeNode(n))
+}
+
+func hashStoredChildren(children []*Node23) []byte {
+	switch len(children) {
+	case 2:
+		return hash2(children[0].hash, children[1].hash)
+	case 3:
+		return hash2(hash2(children[0].hash, children[1].hash), children[2].hash)
+	default:
+		ensure(false, fmt.Sprintf("hashStoredChildren: unexpected childrenCount=%d\n", len(children)))
+		return []byte{}
+	}
+}
+
+// loadError carries store error through bulk upsert/delete which have no error results
+type loadError struct {
+	err error
+}
+
+func recoverLoad(err *error) {
+	if r := recover(); r != nil {
+		le, ok := r.(loadError)
+		if !ok {
+			panic(r)
+		}
+		*err = le.err
+	}
+}
+
+// nodeLoader loads node content from store, it interns keys because tree invariants rely on pointer equality of keys
+type nodeLoader struct {
+	store  NodeStore
+	keys   map[Felt]*Felt
+	loaded map[uint64]struct{}
+}
+
+func newNodeLoader(store NodeStore) *nodeLoader {
+	return &nodeLoader{store: store, keys: make(map[Felt]*Felt), loaded: make(map[uint64]struct{})}
+}
+
+func (l *nodeLoader) stub(id uint64, isLeaf bool, hash []byte) *Node23 {
+	return &Node23{id: id, isLeaf: isLeaf, hash: hash, loader: l}
+}
+
+func (l *nodeLoader) loadNode(n *Node23) {
+	encoded, err := l.store.GetNode(n.id)
+	if err != nil {
+		panic(loadError{err})
+	}
+	if len(encoded) == 0 {
+		panic(loadError{fmt.Errorf("node %d not found", n.id)})
+	}
+	if err = l.decodeNode(n, encoded); err != nil {
+		panic(loadError{fmt.Errorf("node %d: %w", n.id, err)})
+	}
+	l.loaded[n.id] = struct{}{}
+}
+
+const (
+	nodeFlagLeaf byte = 1
+
+	nodeRefLen = 8 + 1 + 32 // id, flags, hash
+)
+
+// encodeNode: flags, keys, then values for leaf and children references for internal node
+func encodeNode(n *Node23) []byte {
+	var flags byte
+	if n.isLeaf {
+		flags |= nodeFlagLeaf
+	}
+	encoded := appendFelts([]byte{flags}, n.keys)
+	if n.isLeaf {
+		return appendFelts(encoded, n.values)
+	}
+	ensure(len(n.children) < 256, "encodeNode: too many children")
+	encoded = append(encoded, byte(len(n.children)))
+	for _, child := range n.children {
+		encoded = appendNodeRef(encoded, child)
+	}
+	return encoded
+}
+
+// appendFelts encodes count followed by presence byte and value for each felt
+func appendFelts(encoded []byte, felts []*Felt) []byte {
+	ensure(len(felts) < 256, "appendFelts: too many felts")
+	encoded = append(encoded, byte(len(felts)))
+	for _, f := range felts {
+		if f == nil {
+			encoded = append(encoded, 0)
+			continue
+		}
+		encoded = append(encoded, 1)
+		encoded = binary.BigEndian.AppendUint64(encoded, uint64(*f))
+	}
+	return encoded
+}
+
+// appendNodeRef encodes id, flags and hash of stored node, nil node is encoded with zero id
+func appendNodeRef(encoded []byte, n *Node23) []byte {
+	if n == nil {
+		return append(encoded, make([]byte, nodeRefLen)...)
+	}
+	ensure(n.id != 0 && len(n.hash) == 32, "appendNodeRef: node is not stored")
+	encoded = binary.BigEndian.AppendUint64(encoded, n.id)
+	if n.isLeaf {
+		encoded = append(encoded, nodeFlagLeaf)
+	} else {
+		encoded = append(encoded, 0)
+	}
+	return append(encoded, n.hash...)
+}
+
+func (l *nodeLoader) decodeNode(n *Node23, encoded []byte) (err error) {
+	if isLeaf := encoded[0]&nodeFlagLeaf != 0; isLeaf != n.isLeaf {
+		return fmt.Errorf("unexpected leaf flag %t", isLeaf)
+	}
+	pos := 1
+	if n.keys, pos, err = l.decodeFelts(encoded, pos, true); err != nil {
+		return err
+	}
+	if n.isLeaf {
+		n.children = make([]*Node23, 0)
+		if n.values, pos, err = l.decodeFelts(encoded, pos, false); err != nil {
+			return err
+		}
+	} else {
+		n.values = make([]*Felt, 0)
+		if pos >= len(encoded) {
+			return fmt.Errorf("children count missing")
+		}
+		count := int(encoded[pos])
+		pos++
+		n.children = make([]*Node23, count)
+		for i := range n.children {
+			if n.children[i], pos, err = l.decodeNodeRef(encoded, pos); err != nil {
+				return err
+			}
+			if n.children[i] == nil {
+				return fmt.Errorf("empty child reference")
+			}
+		}
+	}
+	if pos != len(encoded) {
+		return fmt.Errorf("unexpected %d trailing bytes", len(encoded)-pos)
+	}
+	return nil
+}
+
+func (l *nodeLoader) decodeFelts(encoded []byte, pos int, intern bool) ([]*Felt, int, error) {
+	if pos >= len(encoded) {
+		return nil, pos, fmt.Errorf("felts count missing")
+	}
+	felts := make([]*Felt, encoded[pos])
+	pos++
+	for i := range felts {
+		if pos >= len(encoded) {
+			return nil, pos, fmt.Errorf("felt %d missing", i)
+		}
+		present := encoded[pos] != 0
+		pos++
+		if !present {
+			continue
+		}
+		if pos+8 > len(encoded) {
+			return nil, pos, fmt.Errorf("felt %d truncated", i)
+		}
+		f := Felt(binary.BigEndian.Uint64(encoded[pos:]))
+		pos += 8
+		if !intern {
+			felts[i] = &f
+			continue
+		}
+		if interned, ok := l.keys[f]; ok {
+			felts[i] = interned
+		} else {
+			felts[i] = &f
+			l.keys[f] = &f
+		}
+	}
+	return felts, pos, nil
+}
+
+func (l *nodeLoader) decodeNodeRef(encoded []byte, pos int) (*Node23, int, error) {
+	if pos+nodeRefLen > len(encoded) {
+		return nil, pos, fmt.Errorf("node reference truncated")
+	}
+	id := binary.BigEndian.Uint64(encoded[pos:])
+	isLeaf := encoded[pos+8]&nodeFlagLeaf != 0
+	hash := append([]byte{}, encoded[pos+9:pos+nodeRefLen]...)
+	pos += nodeRefLen
+	if id == 0 {
+		return nil, pos, nil
+	}
+	return l.stub(id, isLeaf, hash), pos, nil
+}
@@ -0,0 +1,205 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package bptree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gateway-fm/cdk-erigon-lib/kv"
+	"github.com/gateway-fm/cdk-erigon-lib/kv/memdb"
+)
+
+type countingNodeStore struct {
+	NodeStore
+	reads int
+}
+
+func (s *countingNodeStore) GetNode(id uint64) ([]byte, error) {
+	s.reads++
+	return s.NodeStore.GetNode(id)
+}
+
+func newTestNodeStore(t *testing.T) *countingNodeStore {
+	_, tx := memdb.NewTestTx(t)
+	return &countingNodeStore{NodeStore: NewKvNodeStore(tx)}
+}
+
+func randomKeys(rnd *rand.Rand, count int, max uint64) []Felt {
+	unique := make(map[Felt]struct{})
+	for len(unique) < count {
+		unique[Felt(rnd.Uint64()%max)] = struct{}{}
+	}
+	keys := make([]Felt, 0, count)
+	for k := range unique {
+		keys = append(keys, k)
+	}
+	sort.Sort(Keys(keys))
+	return keys
+}
+
+func randomKV(rnd *rand.Rand, keys []Felt) KeyValues {
+	values := make([]Felt, len(keys))
+	for i := range values {
+		values[i] = Felt(rnd.Uint64())
+	}
+	return KV(append([]Felt{}, keys...), values)
+}
+
+func TestPersistentTree23_UpsertDeleteAsInMemory(t *testing.T) {
+	store := newTestNodeStore(t)
+	rnd := rand.New(rand.NewSource(1))
+
+	tree := NewEmptyTree23()
+	persistent, err := OpenLatestTree23(store)
+	require.NoError(t, err)
+	rootHashes := make(map[uint64][]byte)
+	keysByVersion := make(map[uint64][]Felt)
+	for version := uint64(1); version <= 11; version++ {
+		if version%3 == 0 {
+			keys := randomKeys(rnd, 1+rnd.Intn(20), 256)
+			tree.Delete(append([]Felt{}, keys...))
+			require.NoError(t, persistent.Delete(keys))
+		} else {
+			keys := randomKeys(rnd, 1+rnd.Intn(30), 256)
+			values := randomKV(rnd, keys)
+			persistentValues := KV(deref(values.keys), deref(values.values))
+			tree.Upsert(values)
+			require.NoError(t, persistent.Upsert(persistentValues))
+		}
+		rootHash, err := persistent.RootHash()
+		require.NoError(t, err)
+		require.Equal(t, tree.RootHash(), rootHash, "version %d", version)
+		require.NoError(t, persistent.Commit(version))
+		rootHashes[version], keysByVersion[version] = rootHash, tree.WalkKeysPostOrder()
+
+		reopened, err := OpenLatestTree23(store)
+		require.NoError(t, err)
+		require.Equal(t, version, reopened.Version())
+		valid, err := reopened.IsValid()
+		require.True(t, valid, "version %d: %v", version, err)
+		keys, err := reopened.WalkKeysPostOrder()
+		require.NoError(t, err)
+		require.Equal(t, keysByVersion[version], keys)
+	}
+
+	// Old versions are readable as committed
+	for version, rootHash := range rootHashes {
+		old, err := OpenTree23(store, version)
+		require.NoError(t, err)
+		oldRootHash, err := old.RootHash()
+		require.NoError(t, err)
+		require.Equal(t, rootHash, oldRootHash, "version %d", version)
+		keys, err := old.WalkKeysPostOrder()
+		require.NoError(t, err)
+		require.Equal(t, keysByVersion[version], keys, "version %d", version)
+		if version != 11 {
+			require.Error(t, old.Commit(100))
+		}
+	}
+	require.Error(t, persistent.Commit(11))
+}
+
+func TestPersistentTree23_LazyLoading(t *testing.T) {
+	store := newTestNodeStore(t)
+	rnd := rand.New(rand.NewSource(2))
+
+	keys := make([]Felt, 1000)
+	for i := range keys {
+		keys[i] = Felt(i * 2)
+	}
+	persistent, err := OpenLatestTree23(store)
+	require.NoError(t, err)
+	require.NoError(t, persistent.Upsert(randomKV(rnd, keys)))
+	require.NoError(t, persistent.Commit(1))
+
+	persistent, err = OpenTree23(store, 1)
+	require.NoError(t, err)
+	store.reads = 0
+	_, err = persistent.RootHash()
+	require.NoError(t, err)
+	require.Zero(t, store.reads, "root hash of stored root must not load nodes")
+
+	// Single key upsert loads the path to the leaf and right spines of siblings on the path (for intermediate keys)
+	require.NoError(t, persistent.Upsert(randomKV(rnd, []Felt{501})))
+	inMemory := NewTree23(randomKV(rnd, keys))
+	require.LessOrEqual(t, store.reads, inMemory.Height()*inMemory.Height())
+	require.Less(t, store.reads, inMemory.Size()/10)
+	require.NoError(t, persistent.Commit(2))
+
+	expected := NewTree23(randomKV(rnd, append(append(append([]Felt{}, keys[:251]...), 501), keys[251:]...)))
+	persistent, err = OpenLatestTree23(store)
+	require.NoError(t, err)
+	require.Equal(t, expected.WalkKeysPostOrder(), mustWalkKeys(t, persistent))
+}
+
+func TestPersistentTree23_Prune(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	store := NewKvNodeStore(tx)
+	rnd := rand.New(rand.NewSource(3))
+
+	persistent, err := OpenLatestTree23(store)
+	require.NoError(t, err)
+	keysByVersion := make(map[uint64][]Felt)
+	for version := uint64(1); version <= 10; version++ {
+		require.NoError(t, persistent.Upsert(randomKV(rnd, randomKeys(rnd, 50, 500))))
+		require.NoError(t, persistent.Commit(version))
+		keysByVersion[version] = mustWalkKeys(t, persistent)
+	}
+	nodesBefore := countTable(t, tx, kv.Tree23Nodes)
+
+	require.Error(t, store.Prune(11))
+	require.NoError(t, persistent.Prune(8))
+	require.Less(t, countTable(t, tx, kv.Tree23Nodes), nodesBefore)
+	for version := uint64(1); version <= 10; version++ {
+		old, err := OpenTree23(store, version)
+		if version < 8 {
+			require.Error(t, err, "version %d", version)
+			continue
+		}
+		require.NoError(t, err)
+		valid, err := old.IsValid()
+		require.True(t, valid, "version %d: %v", version, err)
+		require.Equal(t, keysByVersion[version], mustWalkKeys(t, old), "version %d", version)
+	}
+
+	// Pruning up to the latest version keeps exactly the nodes of the latest tree
+	require.NoError(t, persistent.Prune(10))
+	latest, err := OpenLatestTree23(store)
+	require.NoError(t, err)
+	size := 0
+	latest.tree.WalkPostOrder(func(n *Node23) interface{} { size++; return nil })
+	require.Equal(t, size, countTable(t, tx, kv.Tree23Nodes))
+	require.Equal(t, 0, countTable(t, tx, kv.Tree23Stale))
+}
+
+func mustWalkKeys(t *testing.T, tree *PersistentTree23) []Felt {
+	t.Helper()
+	keys, err := tree.WalkKeysPostOrder()
+	require.NoError(t, err)
+	return keys
+}
+
+func countTable(t *testing.T, tx kv.Tx, table string) int {
+	t.Helper()
+	count := 0
+	require.NoError(t, tx.ForEach(table, nil, func(k, v []byte) error { count++; return nil }))
+	return count
+}
@@ -0,0 +1,237 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package bptree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Proof shows that key has given value in the tree with some root hash, or that key is not present there.
+// It contains the whole leaf where the key is (or would be): its canonical keys, values and next key are enough
+// to prove absence because next key is the first key of the following leaf. Path holds sibling hashes from leaf
+// up to the root.
+type Proof struct {
+	Keys    []Felt
+	Values  []Felt
+	NextKey *Felt // nil for the last leaf
+	Path    []ProofStep
+}
+
+// ProofStep - position of the node on the path among children of its parent and hashes of the other children
+type ProofStep struct {
+	Index    int
+	Siblings [][]byte
+}
+
+// Prove builds membership proof for present key and non-membership proof for absent one
+func (t *Tree23) Prove(key Felt) *Proof {
+	proof := &Proof{}
+	if t.root == nil {
+		return proof
+	}
+	n := t.root
+	for !n.isLeaf {
+		index := 0
+		for index < n.keyCount() && *n.keys[index] <= key {
+			index++
+		}
+		step := ProofStep{Index: index}
+		for i, child := range n.children {
+			if i != index {
+				step.Siblings = append(step.Siblings, child.hashNode())
+			}
+		}
+		proof.Path = append([]ProofStep{step}, proof.Path...)
+		n = n.children[index]
+	}
+	proof.Keys, proof.Values = n.canonicalKeys(), deref(n.values[:n.valueCount()-1])
+	if nextKey := n.nextKey(); nextKey != nil {
+		next := *nextKey
+		proof.NextKey = &next
+	}
+	return proof
+}
+
+// Prove builds proof loading only nodes on the path to the key
+func (t *PersistentTree23) Prove(key Felt) (proof *Proof, err error) {
+	defer recoverLoad(&err)
+	return t.tree.Prove(key), nil
+}
+
+// Verify checks proof against root hash: value nil means the key must be absent, otherwise present with value
+func Verify(rootHash []byte, key Felt, value *Felt, proof *Proof) error {
+	if len(proof.Keys) == 0 {
+		if len(rootHash) != 0 || len(proof.Path) != 0 || proof.NextKey != nil || value != nil {
+			return fmt.Errorf("proof of empty tree")
+		}
+		return nil
+	}
+	if len(proof.Keys) > 2 || len(proof.Keys) != len(proof.Values) {
+		return fmt.Errorf("invalid leaf with %d keys and %d values", len(proof.Keys), len(proof.Values))
+	}
+	for i := 1; i < len(proof.Keys); i++ {
+		if proof.Keys[i-1] >= proof.Keys[i] {
+			return fmt.Errorf("leaf keys not sorted")
+		}
+	}
+	lastKey := proof.Keys[len(proof.Keys)-1]
+	if proof.NextKey != nil && *proof.NextKey <= lastKey {
+		return fmt.Errorf("next key %d not greater than leaf keys", *proof.NextKey)
+	}
+
+	index := -1
+	for i, k := range proof.Keys {
+		if k == key {
+			index = i
+		}
+	}
+	if value != nil {
+		if index < 0 {
+			return fmt.Errorf("key %d not in the leaf", key)
+		}
+		if proof.Values[index] != *value {
+			return fmt.Errorf("key %d has value %d, not %d", key, proof.Values[index], *value)
+		}
+	} else {
+		if index >= 0 {
+			return fmt.Errorf("key %d is present", key)
+		}
+		if proof.NextKey != nil && key >= *proof.NextKey {
+			return fmt.Errorf("key %d not covered by the leaf", key)
+		}
+		// Key before the first leaf key is absent only if the leaf is the leftmost one
+		if key < proof.Keys[0] {
+			for _, step := range proof.Path {
+				if step.Index != 0 {
+					return fmt.Errorf("key %d not covered by the leaf", key)
+				}
+			}
+		}
+	}
+
+	hash := hashProofLeaf(proof)
+	for _, step := range proof.Path {
+		if len(step.Siblings) != 1 && len(step.Siblings) != 2 || step.Index < 0 || step.Index > len(step.Siblings) {
+			return fmt.Errorf("invalid path step: index %d of %d children", step.Index, len(step.Siblings)+1)
+		}
+		children := make([][]byte, 0, len(step.Siblings)+1)
+		children = append(children, step.Siblings[:step.Index]...)
+		children = append(children, hash)
+		children = append(children, step.Siblings[step.Index:]...)
+		if len(children) == 2 {
+			hash = hash2(children[0], children[1])
+		} else {
+			hash = hash2(hash2(children[0], children[1]), children[2])
+		}
+	}
+	if !bytes.Equal(hash, rootHash) {
+		return fmt.Errorf("root hash mismatch")
+	}
+	return nil
+}
+
+// hashProofLeaf computes the same hash as hashLeaf
+func hashProofLeaf(proof *Proof) []byte {
+	h := hash2(proof.Keys[0].Binary(), proof.Values[0].Binary())
+	if len(proof.Keys) == 2 {
+		h = hash2(h, hash2(proof.Keys[1].Binary(), proof.Values[1].Binary()))
+	}
+	if proof.NextKey != nil {
+		h = hash2(h, proof.NextKey.Binary())
+	}
+	return h
+}
+
+// Encode - compact proof encoding: leaf keys count and flags, keys and values, optional next key,
+// then for each path step its index and children count in one byte followed by sibling hashes
+func (p *Proof) Encode() []byte {
+	header := byte(len(p.Keys))
+	if p.NextKey != nil {
+		header |= 0x80
+	}
+	encoded := []byte{header}
+	for i := range p.Keys {
+		encoded = binary.BigEndian.AppendUint64(encoded, uint64(p.Keys[i]))
+		encoded = binary.BigEndian.AppendUint64(encoded, uint64(p.Values[i]))
+	}
+	if p.NextKey != nil {
+		encoded = binary.BigEndian.AppendUint64(encoded, uint64(*p.NextKey))
+	}
+	for _, step := range p.Path {
+		encoded = append(encoded, byte(step.Index)<<4|byte(len(step.Siblings)+1))
+		for _, sibling := range step.Siblings {
+			encoded = append(encoded, sibling...)
+		}
+	}
+	return encoded
+}
+
+func DecodeProof(encoded []byte) (*Proof, error) {
+	if len(encoded) == 0 {
+		return nil, fmt.Errorf("empty proof")
+	}
+	header := encoded[0]
+	keyCount := int(header & 0x7f)
+	if keyCount > 2 {
+		return nil, fmt.Errorf("invalid leaf keys count %d", keyCount)
+	}
+	pos := 1
+	readFelt := func() (Felt, error) {
+		if pos+8 > len(encoded) {
+			return 0, fmt.Errorf("proof truncated at %d", pos)
+		}
+		f := Felt(binary.BigEndian.Uint64(encoded[pos:]))
+		pos += 8
+		return f, nil
+	}
+	p := &Proof{Keys: make([]Felt, keyCount), Values: make([]Felt, keyCount)}
+	var err error
+	for i := 0; i < keyCount; i++ {
+		if p.Keys[i], err = readFelt(); err != nil {
+			return nil, err
+		}
+		if p.Values[i], err = readFelt(); err != nil {
+			return nil, err
+		}
+	}
+	if header&0x80 != 0 {
+		nextKey, err := readFelt()
+		if err != nil {
+			return nil, err
+		}
+		p.NextKey = &nextKey
+	}
+	for pos < len(encoded) {
+		index, childrenCount := int(encoded[pos]>>4), int(encoded[pos]&0x0f)
+		pos++
+		if childrenCount != 2 && childrenCount != 3 || index >= childrenCount {
+			return nil, fmt.Errorf("invalid path step: index %d of %d children", index, childrenCount)
+		}
+		step := ProofStep{Index: index, Siblings: make([][]byte, childrenCount-1)}
+		for i := range step.Siblings {
+			if pos+32 > len(encoded) {
+				return nil, fmt.Errorf("proof truncated at %d", pos)
+			}
+			step.Siblings[i] = encoded[pos : pos+32]
+			pos += 32
+		}
+		p.Path = append(p.Path, step)
+	}
+	return p, nil
+}
@@ -0,0 +1,156 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package bptree
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ProofTest struct {
+	initialItems KeyValues
+	absentKeys   []Felt
+}
+
+var proofTestTable = []ProofTest{
+	{K([]Felt{}), []Felt{0, 1}},
+	{K([]Felt{1}), []Felt{0, 2}},
+	{K([]Felt{1, 2}), []Felt{0, 3}},
+	{K([]Felt{1, 3, 5}), []Felt{0, 2, 4, 6}},
+	{K([]Felt{2, 4, 6, 8, 10, 12, 14}), []Felt{0, 1, 3, 5, 7, 9, 11, 13, 15}},
+	{K([]Felt{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17}), []Felt{0, 18, 100}},
+	{KV([]Felt{10, 20, 30, 40, 50}, []Felt{1, 2, 3, 4, 5}), []Felt{5, 15, 25, 35, 45, 55}},
+}
+
+func assertProofs(t *testing.T, tree *Tree23, presentItems KeyValues, absentKeys []Felt) {
+	t.Helper()
+	rootHash := tree.RootHash()
+	for i, key := range presentItems.keys {
+		proof := tree.Prove(*key)
+		assert.NoError(t, Verify(rootHash, *key, presentItems.values[i], proof), "key %d", *key)
+		otherValue := *presentItems.values[i] + 1
+		assert.Error(t, Verify(rootHash, *key, &otherValue, proof), "key %d with wrong value", *key)
+		assert.Error(t, Verify(rootHash, *key, nil, proof), "key %d proven absent", *key)
+	}
+	for _, key := range absentKeys {
+		proof := tree.Prove(key)
+		assert.NoError(t, Verify(rootHash, key, nil, proof), "absent key %d", key)
+		value := key
+		assert.Error(t, Verify(rootHash, key, &value, proof), "absent key %d proven present", key)
+	}
+}
+
+func TestProof(t *testing.T) {
+	for _, data := range proofTestTable {
+		presentItems := KeyValues{append([]*Felt{}, data.initialItems.keys...), append([]*Felt{}, data.initialItems.values...)}
+		tree := NewTree23(data.initialItems)
+		assertTwoThreeTree(t, tree, nil)
+		assertProofs(t, tree, presentItems, data.absentKeys)
+	}
+}
+
+func TestProofEncoding(t *testing.T) {
+	tree := NewTree23(K([]Felt{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}))
+	rootHash := tree.RootHash()
+	for _, key := range []Felt{0, 1, 5, 6, 11, 12} {
+		proof := tree.Prove(key)
+		decoded, err := DecodeProof(proof.Encode())
+		require.NoError(t, err)
+		require.Equal(t, proof, decoded)
+
+		var value *Felt
+		if key >= 1 && key <= 11 {
+			value = &key
+		}
+		require.NoError(t, Verify(rootHash, key, value, decoded))
+
+		// Any corruption of sibling hashes or leaf invalidates the proof
+		encoded := proof.Encode()
+		for _, pos := range []int{1, len(encoded) - 1} {
+			corrupted := append([]byte{}, encoded...)
+			corrupted[pos] ^= 0x01
+			if decoded, err := DecodeProof(corrupted); err == nil {
+				require.Error(t, Verify(rootHash, key, value, decoded), "key %d corrupted at %d", key, pos)
+			}
+		}
+		_, err = DecodeProof(encoded[:len(encoded)-1])
+		require.Error(t, err)
+	}
+}
+
+func TestProofOfNeighbourLeaf(t *testing.T) {
+	tree := NewTree23(K([]Felt{2, 4, 6, 8, 10, 12, 14}))
+	rootHash := tree.RootHash()
+	// Proof of another leaf cannot show absence of the key outside of its range
+	proof := tree.Prove(14)
+	require.NoError(t, Verify(rootHash, 15, nil, proof))
+	require.Error(t, Verify(rootHash, 1, nil, proof))
+	require.Error(t, Verify(rootHash, 3, nil, proof))
+	proof = tree.Prove(2)
+	require.NoError(t, Verify(rootHash, 1, nil, proof))
+	require.Error(t, Verify(rootHash, 15, nil, proof))
+}
+
+func TestPersistentTree23_Prove(t *testing.T) {
+	store := newTestNodeStore(t)
+	items := K([]Felt{1, 3, 5, 7, 9, 11, 13, 15, 17, 19, 21})
+	persistent, err := OpenLatestTree23(store)
+	require.NoError(t, err)
+	require.NoError(t, persistent.Upsert(K([]Felt{1, 3, 5, 7, 9, 11, 13, 15, 17, 19, 21})))
+	require.NoError(t, persistent.Commit(1))
+
+	persistent, err = OpenTree23(store, 1)
+	require.NoError(t, err)
+	rootHash, err := persistent.RootHash()
+	require.NoError(t, err)
+	tree := NewTree23(items)
+	for _, key := range []Felt{0, 1, 2, 11, 21, 22} {
+		proof, err := persistent.Prove(key)
+		require.NoError(t, err)
+		require.Equal(t, tree.Prove(key), proof)
+		var value *Felt
+		if key%2 == 1 {
+			value = &key
+		}
+		require.NoError(t, Verify(rootHash, key, value, proof))
+	}
+}
+
+func FuzzProof(f *testing.F) {
+	f.Fuzz(func(t *testing.T, input1, input2 []byte) {
+		keyFactory := NewKeyBinaryFactory(1)
+		kvStatePairs := keyFactory.NewUniqueKeyValues(bufio.NewReader(bytes.NewReader(input1)))
+		keysToCheck := keyFactory.NewUniqueKeys(bufio.NewReader(bytes.NewReader(input2)))
+		presentItems := KeyValues{append([]*Felt{}, kvStatePairs.keys...), append([]*Felt{}, kvStatePairs.values...)}
+		present := make(map[Felt]bool)
+		for _, key := range presentItems.keys {
+			present[*key] = true
+		}
+		absentKeys := make([]Felt, 0)
+		for _, key := range keysToCheck {
+			if !present[key] {
+				absentKeys = append(absentKeys, key)
+			}
+		}
+		tree := NewTree23(kvStatePairs)
+		assertProofs(t, tree, presentItems, absentKeys)
+	})
+}
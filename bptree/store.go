@@ -0,0 +1,156 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package bptree
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gateway-fm/cdk-erigon-lib/kv"
+)
+
+// NodeStore keeps encoded nodes of PersistentTree23. Nodes are never overwritten: each changed node gets new id,
+// so every committed version keeps reading the nodes it was committed with until it gets pruned.
+type NodeStore interface {
+	// NewNodeID returns unique non-zero id for the node to be stored
+	NewNodeID() (uint64, error)
+	// GetNode returns encoded node or nil if node is not present
+	GetNode(id uint64) ([]byte, error)
+	PutNode(id uint64, encoded []byte) error
+	// GetRoot returns encoded root reference of given version or nil if version is not present
+	GetRoot(version uint64) ([]byte, error)
+	PutRoot(version uint64, encoded []byte) error
+	// LastVersion returns the latest committed version, ok is false if there are no versions yet
+	LastVersion() (version uint64, ok bool, err error)
+	// PutStale records ids of nodes unreachable from given version and all the following ones
+	PutStale(version uint64, ids []uint64) error
+	// Prune removes roots of versions less than before and nodes which became stale not later than before
+	Prune(before uint64) error
+}
+
+// KvNodeStore - NodeStore on top of kv.RwTx, uses Tree23Nodes, Tree23Roots and Tree23Stale tables
+type KvNodeStore struct {
+	tx kv.RwTx
+}
+
+func NewKvNodeStore(tx kv.RwTx) *KvNodeStore {
+	return &KvNodeStore{tx: tx}
+}
+
+func (s *KvNodeStore) NewNodeID() (uint64, error) {
+	id, err := s.tx.IncrementSequence(kv.Tree23Nodes, 1)
+	if err != nil {
+		return 0, err
+	}
+	return id + 1, nil
+}
+
+func (s *KvNodeStore) GetNode(id uint64) ([]byte, error) {
+	return s.tx.GetOne(kv.Tree23Nodes, encodeUint64(id))
+}
+
+func (s *KvNodeStore) PutNode(id uint64, encoded []byte) error {
+	return s.tx.Put(kv.Tree23Nodes, encodeUint64(id), encoded)
+}
+
+func (s *KvNodeStore) GetRoot(version uint64) ([]byte, error) {
+	return s.tx.GetOne(kv.Tree23Roots, encodeUint64(version))
+}
+
+func (s *KvNodeStore) PutRoot(version uint64, encoded []byte) error {
+	return s.tx.Put(kv.Tree23Roots, encodeUint64(version), encoded)
+}
+
+func (s *KvNodeStore) LastVersion() (uint64, bool, error) {
+	c, err := s.tx.Cursor(kv.Tree23Roots)
+	if err != nil {
+		return 0, false, err
+	}
+	defer c.Close()
+	k, _, err := c.Last()
+	if err != nil {
+		return 0, false, err
+	}
+	if k == nil {
+		return 0, false, nil
+	}
+	return binary.BigEndian.Uint64(k), true, nil
+}
+
+func (s *KvNodeStore) PutStale(version uint64, ids []uint64) error {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, version)
+	for _, id := range ids {
+		binary.BigEndian.PutUint64(key[8:], id)
+		if err := s.tx.Put(kv.Tree23Stale, key, []byte{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *KvNodeStore) Prune(before uint64) error {
+	last, ok, err := s.LastVersion()
+	if err != nil {
+		return err
+	}
+	if ok && before > last {
+		return fmt.Errorf("cannot prune latest version %d", last)
+	}
+
+	roots, err := s.tx.RwCursor(kv.Tree23Roots)
+	if err != nil {
+		return err
+	}
+	defer roots.Close()
+	k, _, err := roots.First()
+	for ; err == nil && k != nil; k, _, err = roots.Next() {
+		if binary.BigEndian.Uint64(k) >= before {
+			break
+		}
+		if err = roots.DeleteCurrent(); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	stale, err := s.tx.RwCursor(kv.Tree23Stale)
+	if err != nil {
+		return err
+	}
+	defer stale.Close()
+	for k, _, err = stale.First(); err == nil && k != nil; k, _, err = stale.Next() {
+		if binary.BigEndian.Uint64(k[:8]) > before {
+			break
+		}
+		if err = s.tx.Delete(kv.Tree23Nodes, k[8:]); err != nil {
+			return err
+		}
+		if err = stale.DeleteCurrent(); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func encodeUint64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
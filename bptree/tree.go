@@ -147,8 +147,15 @@ func (t *Tree23) DeleteWithStats(keysToDelete []Felt, stats *Stats) *Tree23 {
 	return t
 }
 
+func (t *Tree23) walkLoadedPostOrder(w Walker) []interface{} {
+	if t.root == nil {
+		return make([]interface{}, 0)
+	}
+	return t.root.walkLoadedPostOrder(w)
+}
+
 func (t *Tree23) countUpsertRehashedNodes() (rehashedCount uint, closingHashes uint) {
-	t.WalkPostOrder(func(n *Node23) interface{} {
+	t.walkLoadedPostOrder(func(n *Node23) interface{} {
 		if n.exposed {
 			rehashedCount++
 			closingHashes += n.howManyHashes()
@@ -159,7 +166,7 @@ func (t *Tree23) countUpsertRehashedNodes() (rehashedCount uint, closingHashes u
 }
 
 func (t *Tree23) countDeleteRehashedNodes() (rehashedCount uint, closingHashes uint) {
-	t.WalkPostOrder(func(n *Node23) interface{} {
+	t.walkLoadedPostOrder(func(n *Node23) interface{} {
 		if n.updated {
 			rehashedCount++
 			closingHashes += n.howManyHashes()
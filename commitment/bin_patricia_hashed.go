@@ -112,6 +112,7 @@ type BinPatriciaHashed struct {
 	accountFn func(plainKey []byte, cell *BinaryCell) error
 	// Function used to fetch account with given plain key
 	storageFn func(plainKey []byte, cell *BinaryCell) error
+	witness   *Witness // if set, records branches and leaves read by the trie
 }
 
 func NewBinPatriciaHashed(accountKeyLen int,
@@ -119,15 +120,14 @@ func NewBinPatriciaHashed(accountKeyLen int,
 	accountFn func(plainKey []byte, cell *Cell) error,
 	storageFn func(plainKey []byte, cell *Cell) error,
 ) *BinPatriciaHashed {
-	return &BinPatriciaHashed{
+	bph := &BinPatriciaHashed{
 		keccak:        sha3.NewLegacyKeccak256().(keccakState),
 		keccak2:       sha3.NewLegacyKeccak256().(keccakState),
 		accountKeyLen: accountKeyLen,
-		branchFn:      branchFn,
-		accountFn:     wrapAccountStorageFn(accountFn),
-		storageFn:     wrapAccountStorageFn(storageFn),
 		auxBuffer:     bytes.NewBuffer(make([]byte, 8192)),
 	}
+	bph.ResetFns(branchFn, accountFn, storageFn)
+	return bph
 }
 
 type BinaryCell struct {
@@ -825,7 +825,7 @@ func (bph *BinPatriciaHashed) needUnfolding(hashedKey []byte) int {
 
 // unfoldBranchNode returns true if unfolding has been done
 func (bph *BinPatriciaHashed) unfoldBranchNode(row int, deleted bool, depth int) (bool, error) {
-	branchData, err := bph.branchFn(binToCompact(bph.currentKey[:bph.currentKeyLen]))
+	branchData, err := bph.readBranch(binToCompact(bph.currentKey[:bph.currentKeyLen]))
 	if err != nil {
 		return false, err
 	}
@@ -1350,6 +1350,31 @@ func (bph *BinPatriciaHashed) ReviewKeys(plainKeys, hashedKeys [][]byte) (rootHa
 
 func (bph *BinPatriciaHashed) SetTrace(trace bool) { bph.trace = trace }
 
+// SetParallel is not supported by binary trie, keys are always processed sequentially
+func (bph *BinPatriciaHashed) SetParallel(bool) {}
+
+// SetWitness starts recording of branches and leaves read by the trie into given witness (nil stops recording)
+func (bph *BinPatriciaHashed) SetWitness(w *Witness) { bph.witness = w }
+
+func (bph *BinPatriciaHashed) readBranch(prefix []byte) ([]byte, error) {
+	branchData, err := bph.branchFn(prefix)
+	if err == nil && bph.witness != nil {
+		bph.witness.addBranch(prefix, branchData)
+	}
+	return branchData, err
+}
+
+// recordLeaf wraps accountFn or storageFn to record leaves it reads into witness
+func (bph *BinPatriciaHashed) recordLeaf(fn func(plainKey []byte, cell *Cell) error, account bool) func(plainKey []byte, cell *Cell) error {
+	return func(plainKey []byte, cell *Cell) error {
+		err := fn(plainKey, cell)
+		if err == nil && bph.witness != nil {
+			bph.witness.addLeaf(plainKey, cell, account)
+		}
+		return err
+	}
+}
+
 func (bph *BinPatriciaHashed) Variant() TrieVariant { return VariantBinPatriciaTrie }
 
 // Reset allows BinPatriciaHashed instance to be reused for the new commitment calculation
@@ -1374,8 +1399,8 @@ func (bph *BinPatriciaHashed) ResetFns(
 	storageFn func(plainKey []byte, cell *Cell) error,
 ) {
 	bph.branchFn = branchFn
-	bph.accountFn = wrapAccountStorageFn(accountFn)
-	bph.storageFn = wrapAccountStorageFn(storageFn)
+	bph.accountFn = wrapAccountStorageFn(bph.recordLeaf(accountFn, true))
+	bph.storageFn = wrapAccountStorageFn(bph.recordLeaf(storageFn, false))
 }
 
 func (c *BinaryCell) bytes() []byte {
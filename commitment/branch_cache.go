@@ -0,0 +1,178 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commitment
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+var (
+	mxBranchCacheHit   = metrics.GetOrCreateCounter("commitment_branch_cache_hit")
+	mxBranchCacheMiss  = metrics.GetOrCreateCounter("commitment_branch_cache_miss")
+	mxBranchCacheEvict = metrics.GetOrCreateCounter("commitment_branch_cache_evict")
+)
+
+// BranchCache keeps branch nodes returned by branchFn between trie evaluations, so nodes touched in every block
+// (top levels of the trie) are not read from the commitment domain again and again.
+// Each entry is tagged with the step it was read or written at: entry is served only for reads at the same
+// or later step, so after the state goes back (unwind, seek of commitment) outdated entries are not visible.
+// Owner keeps cache coherent with the domain: branchNodeUpdates of evaluation invalidate their prefixes
+// and written branches are put back. Nil *BranchCache is valid and caches nothing.
+type BranchCache struct {
+	mu      sync.Mutex
+	limit   int
+	entries map[string]*list.Element
+	lru     *list.List // front is most recently used
+
+	hits, misses uint64
+}
+
+type branchCacheEntry struct {
+	prefix string
+	step   uint64
+	data   []byte // as returned by branchFn, nil if there is no branch
+}
+
+// NewBranchCache creates cache of at most limit branches, nil is returned if limit is not positive
+func NewBranchCache(limit int) *BranchCache {
+	if limit <= 0 {
+		return nil
+	}
+	return &BranchCache{limit: limit, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// Get returns branch cached at step not later than given one
+func (c *BranchCache) Get(prefix []byte, step uint64) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[string(prefix)]
+	if !ok {
+		c.miss()
+		return nil, false
+	}
+	entry := el.Value.(*branchCacheEntry)
+	if entry.step > step {
+		c.remove(el)
+		c.miss()
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	atomic.AddUint64(&c.hits, 1)
+	mxBranchCacheHit.Inc()
+	return entry.data, true
+}
+
+func (c *BranchCache) miss() {
+	atomic.AddUint64(&c.misses, 1)
+	mxBranchCacheMiss.Inc()
+}
+
+// Put stores copy of branch data (nil for absent branch) as seen at given step
+func (c *BranchCache) Put(prefix []byte, step uint64, data []byte) {
+	if c == nil {
+		return
+	}
+	if data != nil {
+		data = append(make([]byte, 0, len(data)), data...)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[string(prefix)]; ok {
+		entry := el.Value.(*branchCacheEntry)
+		entry.step, entry.data = step, data
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[string(prefix)] = c.lru.PushFront(&branchCacheEntry{prefix: string(prefix), step: step, data: data})
+	for c.lru.Len() > c.limit {
+		c.remove(c.lru.Back())
+		mxBranchCacheEvict.Inc()
+	}
+}
+
+func (c *BranchCache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*branchCacheEntry).prefix)
+}
+
+// Invalidate drops branches changed by trie evaluation
+func (c *BranchCache) Invalidate(branchNodeUpdates map[string]BranchData) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for prefix := range branchNodeUpdates {
+		if el, ok := c.entries[prefix]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+// Reset drops all branches, should be used when domain is changed bypassing the cache
+func (c *BranchCache) Reset() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+func (c *BranchCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns number of cache hits and misses since cache creation
+func (c *BranchCache) Stats() (hits, misses uint64) {
+	if c == nil {
+		return 0, 0
+	}
+	return atomic.LoadUint64(&c.hits), atomic.LoadUint64(&c.misses)
+}
+
+// WrapBranchFn returns branchFn which reads through the cache, step provides current step of the state
+func (c *BranchCache) WrapBranchFn(branchFn func(prefix []byte) ([]byte, error), step func() uint64) func(prefix []byte) ([]byte, error) {
+	if c == nil {
+		return branchFn
+	}
+	return func(prefix []byte) ([]byte, error) {
+		s := step()
+		if data, ok := c.Get(prefix, s); ok {
+			return data, nil
+		}
+		data, err := branchFn(prefix)
+		if err != nil {
+			return nil, err
+		}
+		c.Put(prefix, s, data)
+		return data, nil
+	}
+}
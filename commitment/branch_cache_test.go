@@ -0,0 +1,136 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commitment
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common/length"
+)
+
+func Test_BranchCache_StepsAndEviction(t *testing.T) {
+	require.Nil(t, NewBranchCache(0))
+	var disabled *BranchCache
+	disabled.Put([]byte{1}, 1, []byte{1})
+	_, ok := disabled.Get([]byte{1}, 1)
+	require.False(t, ok)
+
+	c := NewBranchCache(2)
+	data := []byte{1, 2, 3}
+	c.Put([]byte{1}, 5, data)
+	data[0] = 0xff // cache keeps own copy
+
+	got, ok := c.Get([]byte{1}, 5)
+	require.True(t, ok)
+	require.Equal(t, []byte{1, 2, 3}, got)
+	got, ok = c.Get([]byte{1}, 7)
+	require.True(t, ok)
+	require.Equal(t, []byte{1, 2, 3}, got)
+
+	// branch cached at later step is not visible after state went back
+	_, ok = c.Get([]byte{1}, 4)
+	require.False(t, ok)
+	_, ok = c.Get([]byte{1}, 5)
+	require.False(t, ok)
+
+	// absence of branch is cached as well
+	c.Put([]byte{2}, 1, nil)
+	got, ok = c.Get([]byte{2}, 1)
+	require.True(t, ok)
+	require.Nil(t, got)
+
+	// least recently used is evicted
+	c.Put([]byte{3}, 1, []byte{3})
+	c.Put([]byte{4}, 1, []byte{4})
+	require.Equal(t, 2, c.Len())
+	_, ok = c.Get([]byte{2}, 1)
+	require.False(t, ok)
+	_, ok = c.Get([]byte{3}, 1)
+	require.True(t, ok)
+
+	c.Invalidate(map[string]BranchData{string([]byte{3}): nil})
+	_, ok = c.Get([]byte{3}, 1)
+	require.False(t, ok)
+	require.Equal(t, 1, c.Len())
+	c.Reset()
+	require.Zero(t, c.Len())
+
+	hits, misses := c.Stats()
+	require.EqualValues(t, 4, hits)
+	require.EqualValues(t, 4, misses)
+}
+
+func Test_BranchCache_HexPatriciaHashed(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	msPlain, msCached := NewMockState(t), NewMockState(t)
+	cache := NewBranchCache(64)
+	var step uint64
+	plain := NewHexPatriciaHashed(length.Addr, msPlain.branchFn, msPlain.accountFn, msPlain.storageFn)
+	cached := NewHexPatriciaHashed(length.Addr, cache.WrapBranchFn(msCached.branchFn, func() uint64 { return step }), msCached.accountFn, msCached.storageFn)
+
+	addrs := make([]string, 200)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("%040x", rnd.Uint64())
+	}
+	existing := make(map[string]bool)
+	for block := 0; block < 100; block++ {
+		step = uint64(block / 10)
+		builder := NewUpdateBuilder()
+		for i := 0; i < 10; i++ {
+			addr := addrs[rnd.Intn(len(addrs))]
+			if !existing[addr] {
+				existing[addr] = true
+				builder.Balance(addr, rnd.Uint64())
+				continue
+			}
+			switch rnd.Intn(3) {
+			case 0:
+				builder.Storage(addr, fmt.Sprintf("%02x", rnd.Intn(4)), fmt.Sprintf("%04x", rnd.Intn(1<<16)))
+			case 1:
+				builder.Nonce(addr, rnd.Uint64())
+			default:
+				builder.Balance(addr, rnd.Uint64())
+			}
+		}
+		plainKeys, hashedKeys, updates := builder.Build()
+		require.NoError(t, msPlain.applyPlainUpdates(plainKeys, updates))
+		require.NoError(t, msCached.applyPlainUpdates(plainKeys, updates))
+
+		plain.Reset()
+		expected, branchNodeUpdates, err := plain.ReviewKeys(plainKeys, hashedKeys)
+		require.NoError(t, err)
+		msPlain.applyBranchNodeUpdates(branchNodeUpdates)
+
+		cached.Reset()
+		rootHash, branchNodeUpdates, err := cached.ReviewKeys(plainKeys, hashedKeys)
+		require.NoError(t, err)
+		cache.Invalidate(branchNodeUpdates)
+		msCached.applyBranchNodeUpdates(branchNodeUpdates)
+		for prefix := range branchNodeUpdates { // written branches are put back as aggregator does
+			cache.Put([]byte(prefix), step, msCached.cm[prefix][2:])
+		}
+
+		require.Equal(t, expected, rootHash, "block %d", block)
+	}
+	hits, misses := cache.Stats()
+	require.NotZero(t, hits)
+	require.NotZero(t, misses)
+}
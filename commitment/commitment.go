@@ -36,6 +36,13 @@ type Trie interface {
 
 	// Makes trie more verbose
 	SetTrace(bool)
+
+	// SetParallel enables concurrent processing of independent subtries (if supported by the variant).
+	// Result is the same as of sequential processing, but branchFn/accountFn/storageFn must be safe for concurrent use.
+	SetParallel(bool)
+
+	// SetWitness starts recording of branches and leaves read by the trie into given witness (nil stops recording)
+	SetWitness(*Witness)
 }
 
 type TrieVariant string
@@ -45,12 +52,16 @@ const (
 	VariantHexPatriciaTrie TrieVariant = "hex-patricia-hashed"
 	// VariantBinPatriciaTrie - Experimental mode with binary key representation
 	VariantBinPatriciaTrie TrieVariant = "bin-patricia-hashed"
+	// VariantSparseMerkleTrie - zkEVM sparse Merkle tree with Poseidon hashing
+	VariantSparseMerkleTrie TrieVariant = "sparse-merkle-poseidon"
 )
 
 func InitializeTrie(tv TrieVariant) Trie {
 	switch tv {
 	case VariantBinPatriciaTrie:
 		return NewBinPatriciaHashed(length.Addr, nil, nil, nil)
+	case VariantSparseMerkleTrie:
+		return NewSparseMerkleTrie(length.Addr, nil, nil, nil)
 	case VariantHexPatriciaTrie:
 		fallthrough
 	default:
@@ -577,6 +588,8 @@ func ParseTrieVariant(s string) TrieVariant {
 	switch s {
 	case "bin":
 		trieVariant = VariantBinPatriciaTrie
+	case "smt":
+		trieVariant = VariantSparseMerkleTrie
 	case "hex":
 		fallthrough
 	default:
@@ -68,6 +68,9 @@ type HexPatriciaHashed struct {
 	rootTouched  bool
 	rootPresent  bool
 	trace        bool
+	parallel     bool                   // process subtries of the root branch concurrently
+	subtries     [16]*HexPatriciaHashed // instances processing subtries in parallel mode
+	witness      *Witness               // if set, records branches and leaves read by the trie
 	// Function used to load branch node and fill up the cells
 	// For each cell, it sets the cell type, clears the modified flag, fills the hash,
 	// and for the extension, account, and leaf type, the `l` and `k`
@@ -802,7 +805,7 @@ func (hph *HexPatriciaHashed) needUnfolding(hashedKey []byte) int {
 
 // unfoldBranchNode returns true if unfolding has been done
 func (hph *HexPatriciaHashed) unfoldBranchNode(row int, deleted bool, depth int) (bool, error) {
-	branchData, err := hph.branchFn(hexToCompact(hph.currentKey[:hph.currentKeyLen]))
+	branchData, err := hph.readBranch(hexToCompact(hph.currentKey[:hph.currentKeyLen]))
 	if err != nil {
 		return false, err
 	}
@@ -841,13 +844,17 @@ func (hph *HexPatriciaHashed) unfoldBranchNode(row int, deleted bool, depth int)
 			fmt.Printf("cell (%d, %x) depth=%d, hash=[%x], a=[%x], s=[%x], ex=[%x]\n", row, nibble, depth, cell.h[:cell.hl], cell.apk[:cell.apl], cell.spk[:cell.spl], cell.extension[:cell.extLen])
 		}
 		if cell.apl > 0 {
-			hph.accountFn(cell.apk[:cell.apl], cell)
+			if err = hph.readAccount(cell.apk[:cell.apl], cell); err != nil {
+				return false, err
+			}
 			if hph.trace {
 				fmt.Printf("accountFn[%x] return balance=%d, nonce=%d code=%x\n", cell.apk[:cell.apl], &cell.Balance, cell.Nonce, cell.CodeHash[:])
 			}
 		}
 		if cell.spl > 0 {
-			hph.storageFn(cell.spk[:cell.spl], cell)
+			if err = hph.readStorage(cell.spk[:cell.spl], cell); err != nil {
+				return false, err
+			}
 		}
 		if err = cell.deriveHashedKeys(depth, hph.keccak, hph.accountKeyLen); err != nil {
 			return false, err
@@ -1251,8 +1258,44 @@ func (hph *HexPatriciaHashed) RootHash() ([]byte, error) {
 }
 
 func (hph *HexPatriciaHashed) ReviewKeys(plainKeys, hashedKeys [][]byte) (rootHash []byte, branchNodeUpdates map[string]BranchData, err error) {
+	return hph.processKeys(plainKeys, hashedKeys, nil)
+}
+
+// processKeys applies updates of sorted hashed keys and folds the grid up to the root. When updates are nil
+// (ReviewKeys), values are read by accountFn/storageFn.
+func (hph *HexPatriciaHashed) processKeys(plainKeys, hashedKeys [][]byte, updates []Update) (rootHash []byte, branchNodeUpdates map[string]BranchData, err error) {
 	branchNodeUpdates = make(map[string]BranchData)
 
+	var processed int
+	if hph.parallel {
+		if processed, err = hph.processSubtries(plainKeys, hashedKeys, updates, branchNodeUpdates); err != nil {
+			return nil, nil, err
+		}
+	}
+	if updates != nil {
+		updates = updates[processed:]
+	}
+	if err = hph.processRange(plainKeys[processed:], hashedKeys[processed:], updates, branchNodeUpdates); err != nil {
+		return nil, nil, err
+	}
+	// Folding everything up to the root
+	for hph.activeRows > 0 {
+		if branchData, updateKey, err := hph.fold(); err != nil {
+			return nil, nil, fmt.Errorf("final fold: %w", err)
+		} else if branchData != nil {
+			branchNodeUpdates[string(updateKey)] = branchData
+		}
+	}
+
+	rootHash, err = hph.RootHash()
+	if err != nil {
+		return nil, branchNodeUpdates, fmt.Errorf("root hash evaluation failed: %w", err)
+	}
+	return rootHash, branchNodeUpdates, nil
+}
+
+// processRange moves the grid along sorted hashed keys and updates their cells
+func (hph *HexPatriciaHashed) processRange(plainKeys, hashedKeys [][]byte, updates []Update, branchNodeUpdates map[string]BranchData) error {
 	stagedCell := new(Cell)
 	for i, hashedKey := range hashedKeys {
 		plainKey := plainKeys[i]
@@ -1262,7 +1305,7 @@ func (hph *HexPatriciaHashed) ReviewKeys(plainKeys, hashedKeys [][]byte) (rootHa
 		// Keep folding until the currentKey is the prefix of the key we modify
 		for hph.needFolding(hashedKey) {
 			if branchData, updateKey, err := hph.fold(); err != nil {
-				return nil, nil, fmt.Errorf("fold: %w", err)
+				return fmt.Errorf("fold: %w", err)
 			} else if branchData != nil {
 				branchNodeUpdates[string(updateKey)] = branchData
 			}
@@ -1270,60 +1313,119 @@ func (hph *HexPatriciaHashed) ReviewKeys(plainKeys, hashedKeys [][]byte) (rootHa
 		// Now unfold until we step on an empty cell
 		for unfolding := hph.needUnfolding(hashedKey); unfolding > 0; unfolding = hph.needUnfolding(hashedKey) {
 			if err := hph.unfold(hashedKey, unfolding); err != nil {
-				return nil, nil, fmt.Errorf("unfold: %w", err)
+				return fmt.Errorf("unfold: %w", err)
 			}
 		}
 
-		// Update the cell
-		stagedCell.fillEmpty()
-		if len(plainKey) == hph.accountKeyLen {
-			if err := hph.accountFn(plainKey, stagedCell); err != nil {
-				return nil, nil, fmt.Errorf("accountFn for key %x failed: %w", plainKey, err)
+		if updates == nil {
+			// Update the cell
+			stagedCell.fillEmpty()
+			if len(plainKey) == hph.accountKeyLen {
+				if err := hph.readAccount(plainKey, stagedCell); err != nil {
+					return fmt.Errorf("accountFn for key %x failed: %w", plainKey, err)
+				}
+				if !stagedCell.Delete {
+					cell := hph.updateCell(plainKey, hashedKey)
+					cell.setAccountFields(stagedCell.CodeHash[:], &stagedCell.Balance, stagedCell.Nonce)
+
+					if hph.trace {
+						fmt.Printf("accountFn reading key %x => balance=%v nonce=%v codeHash=%x\n", cell.apk, cell.Balance.Uint64(), cell.Nonce, cell.CodeHash)
+					}
+				}
+			} else {
+				if err := hph.readStorage(plainKey, stagedCell); err != nil {
+					return fmt.Errorf("storageFn for key %x failed: %w", plainKey, err)
+				}
+				if !stagedCell.Delete {
+					hph.updateCell(plainKey, hashedKey).setStorage(stagedCell.Storage[:stagedCell.StorageLen])
+					if hph.trace {
+						fmt.Printf("storageFn reading key %x => %x\n", plainKey, stagedCell.Storage[:stagedCell.StorageLen])
+					}
+				}
 			}
-			if !stagedCell.Delete {
-				cell := hph.updateCell(plainKey, hashedKey)
-				cell.setAccountFields(stagedCell.CodeHash[:], &stagedCell.Balance, stagedCell.Nonce)
 
+			if stagedCell.Delete {
 				if hph.trace {
-					fmt.Printf("accountFn reading key %x => balance=%v nonce=%v codeHash=%x\n", cell.apk, cell.Balance.Uint64(), cell.Nonce, cell.CodeHash)
+					fmt.Printf("delete cell %x hash %x\n", plainKey, hashedKey)
 				}
+				hph.deleteCell(hashedKey)
+			}
+			continue
+		}
+
+		update := &updates[i]
+		// Update the cell
+		if update.Flags == DeleteUpdate {
+			hph.deleteCell(hashedKey)
+			if hph.trace {
+				fmt.Printf("key %x deleted\n", plainKey)
 			}
 		} else {
-			if err = hph.storageFn(plainKey, stagedCell); err != nil {
-				return nil, nil, fmt.Errorf("storageFn for key %x failed: %w", plainKey, err)
+			cell := hph.updateCell(plainKey, hashedKey)
+			if hph.trace {
+				fmt.Printf("accountFn updated key %x =>", plainKey)
 			}
-			if !stagedCell.Delete {
-				hph.updateCell(plainKey, hashedKey).setStorage(stagedCell.Storage[:stagedCell.StorageLen])
+			if update.Flags&BalanceUpdate != 0 {
 				if hph.trace {
-					fmt.Printf("storageFn reading key %x => %x\n", plainKey, stagedCell.Storage[:stagedCell.StorageLen])
+					fmt.Printf(" balance=%d", update.Balance.Uint64())
 				}
+				cell.Balance.Set(&update.Balance)
+			}
+			if update.Flags&NonceUpdate != 0 {
+				if hph.trace {
+					fmt.Printf(" nonce=%d", update.Nonce)
+				}
+				cell.Nonce = update.Nonce
+			}
+			if update.Flags&CodeUpdate != 0 {
+				if hph.trace {
+					fmt.Printf(" codeHash=%x", update.CodeHashOrStorage)
+				}
+				copy(cell.CodeHash[:], update.CodeHashOrStorage[:])
 			}
-		}
-
-		if stagedCell.Delete {
 			if hph.trace {
-				fmt.Printf("delete cell %x hash %x\n", plainKey, hashedKey)
+				fmt.Printf("\n")
+			}
+			if update.Flags&StorageUpdate != 0 {
+				cell.setStorage(update.CodeHashOrStorage[:update.ValLength])
+				if hph.trace {
+					fmt.Printf("\rstorageFn filled key %x => %x\n", plainKey, update.CodeHashOrStorage[:update.ValLength])
+				}
 			}
-			hph.deleteCell(hashedKey)
 		}
 	}
-	// Folding everything up to the root
-	for hph.activeRows > 0 {
-		if branchData, updateKey, err := hph.fold(); err != nil {
-			return nil, nil, fmt.Errorf("final fold: %w", err)
-		} else if branchData != nil {
-			branchNodeUpdates[string(updateKey)] = branchData
-		}
+	return nil
+}
+
+func (hph *HexPatriciaHashed) SetTrace(trace bool) { hph.trace = trace }
+
+func (hph *HexPatriciaHashed) SetParallel(parallel bool) { hph.parallel = parallel }
+
+func (hph *HexPatriciaHashed) SetWitness(w *Witness) { hph.witness = w }
+
+func (hph *HexPatriciaHashed) readBranch(prefix []byte) ([]byte, error) {
+	branchData, err := hph.branchFn(prefix)
+	if err == nil && hph.witness != nil {
+		hph.witness.addBranch(prefix, branchData)
 	}
+	return branchData, err
+}
 
-	rootHash, err = hph.RootHash()
-	if err != nil {
-		return nil, branchNodeUpdates, fmt.Errorf("root hash evaluation failed: %w", err)
+func (hph *HexPatriciaHashed) readAccount(plainKey []byte, cell *Cell) error {
+	err := hph.accountFn(plainKey, cell)
+	if err == nil && hph.witness != nil {
+		hph.witness.addLeaf(plainKey, cell, true)
 	}
-	return rootHash, branchNodeUpdates, nil
+	return err
 }
 
-func (hph *HexPatriciaHashed) SetTrace(trace bool) { hph.trace = trace }
+func (hph *HexPatriciaHashed) readStorage(plainKey []byte, cell *Cell) error {
+	err := hph.storageFn(plainKey, cell)
+	if err == nil && hph.witness != nil {
+		hph.witness.addLeaf(plainKey, cell, false)
+	}
+	return err
+}
 
 func (hph *HexPatriciaHashed) Variant() TrieVariant { return VariantHexPatriciaTrie }
 
@@ -1719,83 +1821,7 @@ func commonPrefixLen(b1, b2 []byte) int {
 }
 
 func (hph *HexPatriciaHashed) ProcessUpdates(plainKeys, hashedKeys [][]byte, updates []Update) (rootHash []byte, branchNodeUpdates map[string]BranchData, err error) {
-	branchNodeUpdates = make(map[string]BranchData)
-
-	for i, plainKey := range plainKeys {
-		hashedKey := hashedKeys[i]
-		if hph.trace {
-			fmt.Printf("plainKey=[%x], hashedKey=[%x], currentKey=[%x]\n", plainKey, hashedKey, hph.currentKey[:hph.currentKeyLen])
-		}
-		// Keep folding until the currentKey is the prefix of the key we modify
-		for hph.needFolding(hashedKey) {
-			if branchData, updateKey, err := hph.fold(); err != nil {
-				return nil, nil, fmt.Errorf("fold: %w", err)
-			} else if branchData != nil {
-				branchNodeUpdates[string(updateKey)] = branchData
-			}
-		}
-		// Now unfold until we step on an empty cell
-		for unfolding := hph.needUnfolding(hashedKey); unfolding > 0; unfolding = hph.needUnfolding(hashedKey) {
-			if err := hph.unfold(hashedKey, unfolding); err != nil {
-				return nil, nil, fmt.Errorf("unfold: %w", err)
-			}
-		}
-
-		update := updates[i]
-		// Update the cell
-		if update.Flags == DeleteUpdate {
-			hph.deleteCell(hashedKey)
-			if hph.trace {
-				fmt.Printf("key %x deleted\n", plainKey)
-			}
-		} else {
-			cell := hph.updateCell(plainKey, hashedKey)
-			if hph.trace {
-				fmt.Printf("accountFn updated key %x =>", plainKey)
-			}
-			if update.Flags&BalanceUpdate != 0 {
-				if hph.trace {
-					fmt.Printf(" balance=%d", update.Balance.Uint64())
-				}
-				cell.Balance.Set(&update.Balance)
-			}
-			if update.Flags&NonceUpdate != 0 {
-				if hph.trace {
-					fmt.Printf(" nonce=%d", update.Nonce)
-				}
-				cell.Nonce = update.Nonce
-			}
-			if update.Flags&CodeUpdate != 0 {
-				if hph.trace {
-					fmt.Printf(" codeHash=%x", update.CodeHashOrStorage)
-				}
-				copy(cell.CodeHash[:], update.CodeHashOrStorage[:])
-			}
-			if hph.trace {
-				fmt.Printf("\n")
-			}
-			if update.Flags&StorageUpdate != 0 {
-				cell.setStorage(update.CodeHashOrStorage[:update.ValLength])
-				if hph.trace {
-					fmt.Printf("\rstorageFn filled key %x => %x\n", plainKey, update.CodeHashOrStorage[:update.ValLength])
-				}
-			}
-		}
-	}
-	// Folding everything up to the root
-	for hph.activeRows > 0 {
-		if branchData, updateKey, err := hph.fold(); err != nil {
-			return nil, nil, fmt.Errorf("final fold: %w", err)
-		} else if branchData != nil {
-			branchNodeUpdates[string(updateKey)] = branchData
-		}
-	}
-
-	rootHash, err = hph.RootHash()
-	if err != nil {
-		return nil, branchNodeUpdates, fmt.Errorf("root hash evaluation failed: %w", err)
-	}
-	return rootHash, branchNodeUpdates, nil
+	return hph.processKeys(plainKeys, hashedKeys, updates)
 }
 
 // nolint
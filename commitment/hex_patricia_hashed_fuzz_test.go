@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/hex"
+	"fmt"
 	"math/rand"
 	"testing"
 
@@ -142,6 +143,7 @@ func Fuzz_ProcessUpdates_ArbitraryUpdateCount(f *testing.F) {
 		ms2 := NewMockState(t)
 		hph := NewHexPatriciaHashed(20, ms.branchFn, ms.accountFn, ms.storageFn)
 		hphAnother := NewHexPatriciaHashed(20, ms2.branchFn, ms2.accountFn, ms2.storageFn)
+		hphAnother.SetParallel(true)
 
 		plainKeys, hashedKeys, updates := builder.Build()
 
@@ -166,6 +168,7 @@ func Fuzz_ProcessUpdates_ArbitraryUpdateCount(f *testing.F) {
 
 		require.Len(t, rootHashAnother, length.Hash, "invalid root hash length")
 		require.EqualValues(t, rootHashReview, rootHashAnother, "storage-based and update-based rootHash mismatch")
+		require.EqualValues(t, branchNodeUpdates, branchUpdatesAnother, "sequential and parallel branch updates mismatch")
 	})
 }
 
@@ -212,3 +215,76 @@ func Fuzz_HexPatriciaHashed_ReviewKeys(f *testing.F) {
 		require.Lenf(t, rootHash, length.Hash, "invalid root hash length")
 	})
 }
+
+// go test -trimpath -v -fuzz=Fuzz_HexPatriciaHashed_Parallel -fuzztime=300s ./commitment
+
+func Fuzz_HexPatriciaHashed_Parallel(f *testing.F) {
+	f.Add(uint64(3), int64(1))
+	f.Add(uint64(100), int64(1234123415))
+	f.Add(uint64(2000), int64(42))
+
+	f.Fuzz(func(t *testing.T, keysCount uint64, seed int64) {
+		if keysCount > 10e3 {
+			t.Skip()
+		}
+		rnd := rand.New(rand.NewSource(seed))
+
+		ms := NewMockState(t)
+		msParallel := NewMockState(t)
+		hph := NewHexPatriciaHashed(length.Addr, ms.branchFn, ms.accountFn, ms.storageFn)
+		hphParallel := NewHexPatriciaHashed(length.Addr, msParallel.branchFn, msParallel.accountFn, msParallel.storageFn)
+		hphParallel.SetParallel(true)
+
+		keys := make([]string, keysCount)
+		for i := range keys {
+			key := make([]byte, length.Addr)
+			rnd.Read(key)
+			keys[i] = hex.EncodeToString(key)
+		}
+
+		for round := 0; round < 4; round++ {
+			builder := NewUpdateBuilder()
+			for _, key := range keys {
+				switch rnd.Intn(5) {
+				case 0:
+					if round > 0 {
+						builder.Delete(key)
+						continue
+					}
+					builder.Balance(key, rnd.Uint64())
+				case 1:
+					builder.Storage(key, fmt.Sprintf("%02x", rnd.Intn(8)), fmt.Sprintf("%04x", rnd.Intn(1<<16-1)+1))
+				case 2:
+					continue
+				default:
+					builder.Balance(key, rnd.Uint64()).Nonce(key, rnd.Uint64())
+				}
+			}
+			plainKeys, hashedKeys, updates := builder.Build()
+			require.NoError(t, ms.applyPlainUpdates(plainKeys, updates))
+			require.NoError(t, msParallel.applyPlainUpdates(plainKeys, updates))
+
+			var rootHash, rootHashParallel []byte
+			var branchNodeUpdates, branchNodeUpdatesParallel map[string]BranchData
+			var err error
+			if round%2 == 0 {
+				rootHash, branchNodeUpdates, err = hph.ReviewKeys(plainKeys, hashedKeys)
+				require.NoError(t, err)
+				rootHashParallel, branchNodeUpdatesParallel, err = hphParallel.ReviewKeys(plainKeys, hashedKeys)
+				require.NoError(t, err)
+			} else {
+				rootHash, branchNodeUpdates, err = hph.ProcessUpdates(plainKeys, hashedKeys, updates)
+				require.NoError(t, err)
+				rootHashParallel, branchNodeUpdatesParallel, err = hphParallel.ProcessUpdates(plainKeys, hashedKeys, updates)
+				require.NoError(t, err)
+			}
+			require.EqualValues(t, rootHash, rootHashParallel, "round %d: sequential and parallel root mismatch", round)
+			require.EqualValues(t, branchNodeUpdates, branchNodeUpdatesParallel, "round %d: sequential and parallel branch updates mismatch", round)
+
+			ms.applyBranchNodeUpdates(branchNodeUpdates)
+			msParallel.applyBranchNodeUpdates(branchNodeUpdatesParallel)
+			hph.Reset()
+			hphParallel.Reset()
+		}
+	})
+}
@@ -0,0 +1,123 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commitment
+
+import (
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Parallel mode: when root is a branch node (row 0 is unfolded at depth 1), keys of different first nibbles
+// never share rows of the grid below row 0. So each such subtrie is processed by separate HexPatriciaHashed instance,
+// which starts from a copy of it's cell in row 0 and folds everything down to row 0. Then cells and touch/after bits
+// are moved back to row 0 of the main instance, which folds the root as usual.
+
+// processSubtries returns amount of processed keys: all of them, or 0 if keys can't be split into subtries.
+// In latter case grid is left in the state sequential processing expects.
+func (hph *HexPatriciaHashed) processSubtries(plainKeys, hashedKeys [][]byte, updates []Update, branchNodeUpdates map[string]BranchData) (int, error) {
+	if len(hashedKeys) < 2 || hph.activeRows != 0 {
+		return 0, nil
+	}
+	first, last := hashedKeys[0], hashedKeys[len(hashedKeys)-1]
+	if first[0] == last[0] { // keys are sorted, so all of them are in one subtrie
+		return 0, nil
+	}
+	// the same step as sequential processing does for the first key
+	if unfolding := hph.needUnfolding(first); unfolding > 0 {
+		if err := hph.unfold(first, unfolding); err != nil {
+			return 0, fmt.Errorf("unfold: %w", err)
+		}
+	}
+	if hph.activeRows != 1 || hph.depths[0] != 1 {
+		return 0, nil // root is empty, leaf or extension
+	}
+
+	type subtrie struct {
+		nibble   int
+		from, to int
+		updates  map[string]BranchData
+	}
+	var subtries []*subtrie
+	for from := 0; from < len(hashedKeys); {
+		to := from + 1
+		for to < len(hashedKeys) && hashedKeys[to][0] == hashedKeys[from][0] {
+			to++
+		}
+		subtries = append(subtries, &subtrie{nibble: int(hashedKeys[from][0]), from: from, to: to, updates: make(map[string]BranchData)})
+		from = to
+	}
+
+	var g errgroup.Group
+	for _, st := range subtries {
+		st := st
+		w := hph.subtrieInstance(st.nibble)
+		var stUpdates []Update
+		if updates != nil {
+			stUpdates = updates[st.from:st.to]
+		}
+		g.Go(func() error {
+			if err := w.processRange(plainKeys[st.from:st.to], hashedKeys[st.from:st.to], stUpdates, st.updates); err != nil {
+				return fmt.Errorf("subtrie %x: %w", st.nibble, err)
+			}
+			for w.activeRows > 1 {
+				branchData, updateKey, err := w.fold()
+				if err != nil {
+					return fmt.Errorf("subtrie %x: final fold: %w", st.nibble, err)
+				}
+				if branchData != nil {
+					st.updates[string(updateKey)] = branchData
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return 0, err
+	}
+
+	for _, st := range subtries {
+		w, bit := hph.subtries[st.nibble], uint16(1)<<st.nibble
+		hph.grid[0][st.nibble] = w.grid[0][st.nibble]
+		hph.touchMap[0] |= w.touchMap[0] & bit
+		hph.afterMap[0] = hph.afterMap[0]&^bit | w.afterMap[0]&bit
+		for k, v := range st.updates {
+			branchNodeUpdates[k] = v
+		}
+	}
+	return len(hashedKeys), nil
+}
+
+// subtrieInstance prepares instance for processing subtrie under given nibble of the unfolded root row
+func (hph *HexPatriciaHashed) subtrieInstance(nibble int) *HexPatriciaHashed {
+	w := hph.subtries[nibble]
+	if w == nil {
+		w = NewHexPatriciaHashed(hph.accountKeyLen, hph.branchFn, hph.accountFn, hph.storageFn)
+		hph.subtries[nibble] = w
+	}
+	w.ResetFns(hph.branchFn, hph.accountFn, hph.storageFn)
+	w.SetWitness(hph.witness)
+	bit := uint16(1) << nibble
+	w.grid[0][nibble] = hph.grid[0][nibble]
+	w.depths[0] = 1
+	w.touchMap[0] = hph.touchMap[0] & bit
+	w.afterMap[0] = hph.afterMap[0] & bit
+	w.branchBefore[0] = hph.branchBefore[0]
+	w.activeRows = 1
+	w.currentKeyLen = 0
+	return w
+}
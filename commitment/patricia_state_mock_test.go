@@ -1,6 +1,7 @@
 package commitment
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
@@ -81,6 +82,22 @@ func (ms MockState) accountFn(plainKey []byte, cell *Cell) error {
 	return nil
 }
 
+// codeFn - mock keeps only code hashes, they stand for code of the account
+func (ms MockState) codeFn(addr []byte) ([]byte, error) {
+	exBytes, ok := ms.sm[string(addr)]
+	if !ok {
+		return nil, nil
+	}
+	var ex Update
+	if _, err := ex.Decode(exBytes, 0); err != nil {
+		return nil, err
+	}
+	if ex.Flags&CodeUpdate == 0 || bytes.Equal(ex.CodeHashOrStorage[:], EmptyCodeHash) {
+		return nil, nil
+	}
+	return common.Copy(ex.CodeHashOrStorage[:]), nil
+}
+
 func (ms MockState) storageFn(plainKey []byte, cell *Cell) error {
 	exBytes, ok := ms.sm[string(plainKey[:])]
 	if !ok {
@@ -0,0 +1,143 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commitment
+
+import (
+	"math/bits"
+)
+
+// Poseidon permutation over Goldilocks field (p = 2^64 - 2^32 + 1) with state width 12 (8 input elements + 4 capacity),
+// x^7 s-box, 8 full and 22 partial rounds, circulant MDS matrix - the hash of Polygon zkEVM (Hermez) sparse Merkle trees,
+// same as Poseidon of plonky2. Constants are in poseidon_constants.go. Chains with other constants should provide own
+// PoseidonFn to the trie.
+
+const (
+	goldilocksP       = 0xffffffff00000001
+	goldilocksEpsilon = 0xffffffff // 2^64 mod p
+
+	poseidonWidth         = 12
+	poseidonFullRounds    = 8
+	poseidonPartialRounds = 22
+)
+
+// PoseidonFn hashes 8 field elements with 4 capacity elements into 4 field elements
+type PoseidonFn func(in [8]uint64, capacity [4]uint64) [4]uint64
+
+// Poseidon - default PoseidonFn. Inputs are reduced modulo p
+func Poseidon(in [8]uint64, capacity [4]uint64) [4]uint64 {
+	var state [poseidonWidth]uint64
+	for i := range in {
+		state[i] = in[i] % goldilocksP
+	}
+	for i := range capacity {
+		state[8+i] = capacity[i] % goldilocksP
+	}
+	poseidonARK(&state, 0)
+
+	for r := 0; r < poseidonFullRounds/2; r++ {
+		poseidonSBox(&state)
+		poseidonARK(&state, (r+1)*poseidonWidth)
+		if r < poseidonFullRounds/2-1 {
+			poseidonMix(&state)
+		} else {
+			poseidonMixP(&state)
+		}
+	}
+
+	const partialC = (poseidonFullRounds/2 + 1) * poseidonWidth
+	for r := 0; r < poseidonPartialRounds; r++ {
+		state[0] = gAdd(gPow7(state[0]), poseidonC[partialC+r])
+		s := poseidonS[(2*poseidonWidth-1)*r:]
+		s0 := gMul(s[0], state[0])
+		for i := 1; i < poseidonWidth; i++ {
+			s0 = gAdd(s0, gMul(s[i], state[i]))
+			state[i] = gAdd(state[i], gMul(s[poseidonWidth+i-1], state[0]))
+		}
+		state[0] = s0
+	}
+
+	for r := 0; r < poseidonFullRounds/2; r++ {
+		poseidonSBox(&state)
+		if r < poseidonFullRounds/2-1 {
+			poseidonARK(&state, partialC+poseidonPartialRounds+r*poseidonWidth)
+		}
+		poseidonMix(&state)
+	}
+	return [4]uint64{state[0], state[1], state[2], state[3]}
+}
+
+func poseidonSBox(state *[poseidonWidth]uint64) {
+	for i := range state {
+		state[i] = gPow7(state[i])
+	}
+}
+
+func poseidonARK(state *[poseidonWidth]uint64, offset int) {
+	for i := range state {
+		state[i] = gAdd(state[i], poseidonC[offset+i])
+	}
+}
+
+// poseidonMix - multiplication by MDS matrix: circulant matrix plus diagonal one
+func poseidonMix(state *[poseidonWidth]uint64) {
+	var mixed [poseidonWidth]uint64
+	for i := 0; i < poseidonWidth; i++ {
+		mixed[i] = gMul(poseidonMDSDiag[i], state[i])
+		for j := 0; j < poseidonWidth; j++ {
+			mixed[i] = gAdd(mixed[i], gMul(poseidonMDSCirc[(j-i+poseidonWidth)%poseidonWidth], state[j]))
+		}
+	}
+	*state = mixed
+}
+
+func poseidonMixP(state *[poseidonWidth]uint64) {
+	var mixed [poseidonWidth]uint64
+	for i := 0; i < poseidonWidth; i++ {
+		for j := 0; j < poseidonWidth; j++ {
+			mixed[i] = gAdd(mixed[i], gMul(poseidonP[j][i], state[j]))
+		}
+	}
+	*state = mixed
+}
+
+func gAdd(a, b uint64) uint64 {
+	s, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		s += goldilocksEpsilon
+	}
+	if s >= goldilocksP {
+		s -= goldilocksP
+	}
+	return s
+}
+
+func gMul(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	// 2^64 = 2^32-1 and 2^96 = -1 (mod p)
+	hiHi, hiLo := hi>>32, hi&goldilocksEpsilon
+	t0, borrow := bits.Sub64(lo, hiHi, 0)
+	if borrow != 0 {
+		t0 -= goldilocksEpsilon
+	}
+	return gAdd(t0%goldilocksP, hiLo*goldilocksEpsilon%goldilocksP)
+}
+
+func gPow7(x uint64) uint64 {
+	x2 := gMul(x, x)
+	x4 := gMul(x2, x2)
+	return gMul(gMul(x4, x2), x)
+}
@@ -0,0 +1,492 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commitment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common"
+	"github.com/gateway-fm/cdk-erigon-lib/common/length"
+	"github.com/gateway-fm/cdk-erigon-lib/rlp"
+)
+
+// AccountProof - Merkle proof of account and (optionally) of it's storage slots, in the format of eth_getProof:
+// every proof is a list of RLP-encoded trie nodes on the path from the root to the key. Account proof starts
+// from the state root, storage proofs start from the storage root of the account.
+// Nodes embedded into their parents (shorter than 32 bytes) are not listed separately.
+type AccountProof struct {
+	Address      []byte
+	AccountProof [][]byte
+	StorageProof []StorageProof
+}
+
+type StorageProof struct {
+	Key   []byte
+	Proof [][]byte
+}
+
+// ProveAccount builds proof of account with given address and its storage slots from committed branch data.
+// Trie must be in the state of the last commitment evaluation (after ReviewKeys/ProcessUpdates or SetState),
+// functions passed to ResetFns are used to read branches, accounts and storage.
+// Proof of absent key is proof of it's non-existence.
+func (hph *HexPatriciaHashed) ProveAccount(addr []byte, storageKeys ...[]byte) (*AccountProof, error) {
+	if len(addr) != hph.accountKeyLen {
+		return nil, fmt.Errorf("prove account: address length %d, expected %d", len(addr), hph.accountKeyLen)
+	}
+	accountProof, _, err := hph.prove(addr)
+	if err != nil {
+		return nil, fmt.Errorf("prove account %x: %w", addr, err)
+	}
+	p := &AccountProof{
+		Address:      common.Copy(addr),
+		AccountProof: accountProof,
+		StorageProof: make([]StorageProof, 0, len(storageKeys)),
+	}
+	plainKey := make([]byte, 0, hph.accountKeyLen+length.Hash)
+	for _, key := range storageKeys {
+		plainKey = append(append(plainKey[:0], addr...), key...)
+		_, storageProof, err := hph.prove(plainKey)
+		if err != nil {
+			return nil, fmt.Errorf("prove storage %x: %w", plainKey, err)
+		}
+		p.StorageProof = append(p.StorageProof, StorageProof{Key: common.Copy(key), Proof: storageProof})
+	}
+	return p, nil
+}
+
+// prove walks from the root cell along hashed plain key and produces nodes of account and storage tries
+func (hph *HexPatriciaHashed) prove(plainKey []byte) (accountProof, storageProof [][]byte, err error) {
+	hashedKey := make([]byte, 64, 128)
+	if err = hashKey(hph.keccak, plainKey[:hph.accountKeyLen], hashedKey, 0); err != nil {
+		return nil, nil, err
+	}
+	if len(plainKey) > hph.accountKeyLen {
+		hashedKey = hashedKey[:128]
+		if err = hashKey(hph.keccak, plainKey[hph.accountKeyLen:], hashedKey[64:], 0); err != nil {
+			return nil, nil, err
+		}
+	}
+	add := func(nodeDepth int, node []byte) {
+		if nodeDepth < 64 {
+			accountProof = append(accountProof, node)
+		} else {
+			storageProof = append(storageProof, node)
+		}
+	}
+
+	var cells [16]Cell
+	cell := hph.root
+	depth := 0
+	rootUnknown := cell.apl == 0 && cell.spl == 0 && cell.hl == 0 && cell.extLen == 0
+	for {
+		var branchDepth int // nibbles before branch node, which the cell points to
+		var branchHash []byte
+		switch {
+		case cell.apl > 0 && depth <= 64:
+			accountHash := make([]byte, 65)
+			if err = hashKey(hph.keccak, cell.apk[:cell.apl], accountHash, 0); err != nil {
+				return nil, nil, err
+			}
+			accountHash[64] = 16 // terminator
+
+			var storageRoot [length.Hash]byte
+			var storageNodes [][]byte
+			switch {
+			case cell.spl > 0: // singleton storage, always hashed
+				storageHash := make([]byte, 65)
+				if err = hashKey(hph.keccak, cell.spk[hph.accountKeyLen:cell.spl], storageHash, 0); err != nil {
+					return nil, nil, err
+				}
+				storageHash[64] = 16
+				node := proofShortNode(storageHash, proofStorageValue(cell.Storage[:cell.StorageLen]))
+				copy(storageRoot[:], hph.proofHash(node))
+				storageNodes = append(storageNodes, node)
+			case cell.extLen > 0:
+				node := proofShortNode(cell.extension[:cell.extLen], rlpStringPayload(cell.h[:cell.hl]))
+				copy(storageRoot[:], hph.proofHash(node))
+				storageNodes = append(storageNodes, node)
+			case cell.hl > 0:
+				storageRoot = cell.h
+			default:
+				copy(storageRoot[:], EmptyRootHash)
+			}
+			var valBuf [128]byte
+			valLen := cell.accountForHashing(valBuf[:], storageRoot)
+			add(depth, proofShortNode(accountHash[depth:], valBuf[:valLen]))
+
+			if !bytes.Equal(accountHash[:64], hashedKey[:64]) || len(hashedKey) == 64 {
+				return accountProof, storageProof, nil
+			}
+			if len(storageNodes) > 0 {
+				add(64, storageNodes[0])
+			}
+			switch {
+			case cell.spl > 0:
+				return accountProof, storageProof, nil
+			case cell.extLen > 0:
+				if !bytes.HasPrefix(hashedKey[64:], cell.extension[:cell.extLen]) {
+					return accountProof, storageProof, nil
+				}
+				branchDepth, branchHash = 64+cell.extLen, cell.h[:cell.hl]
+			case cell.hl > 0:
+				branchDepth, branchHash = 64, cell.h[:cell.hl]
+			default:
+				return accountProof, storageProof, nil
+			}
+		case cell.spl > 0 && depth >= 64:
+			if cell.StorageLen == 0 {
+				return accountProof, storageProof, nil
+			}
+			storageHash := make([]byte, 65)
+			if err = hashKey(hph.keccak, cell.spk[hph.accountKeyLen:cell.spl], storageHash, 0); err != nil {
+				return nil, nil, err
+			}
+			storageHash[64] = 16
+			node := proofShortNode(storageHash[depth-64:], proofStorageValue(cell.Storage[:cell.StorageLen]))
+			if len(node) >= length.Hash { // shorter leaf is embedded into the branch
+				add(depth, node)
+			}
+			return accountProof, storageProof, nil
+		case cell.hl > 0:
+			branchDepth, branchHash = depth, cell.h[:cell.hl]
+			if cell.extLen > 0 {
+				add(depth, proofShortNode(cell.extension[:cell.extLen], rlpStringPayload(cell.h[:cell.hl])))
+				if !bytes.HasPrefix(hashedKey[depth:], cell.extension[:cell.extLen]) {
+					return accountProof, storageProof, nil
+				}
+				branchDepth += cell.extLen
+			}
+		case depth == 0 && rootUnknown:
+			// root cell is not set (trie was reset), try to load root branch
+			branchDepth = 0
+		default:
+			return accountProof, storageProof, nil
+		}
+		if branchDepth >= len(hashedKey) {
+			return nil, nil, fmt.Errorf("branch at depth %d is beyond key %x", branchDepth, hashedKey)
+		}
+
+		bitmap, err := hph.proofBranchCells(hashedKey[:branchDepth], &cells)
+		if err != nil {
+			return nil, nil, err
+		}
+		if bitmap == 0 {
+			if branchHash != nil {
+				return nil, nil, fmt.Errorf("branch [%x] not found", hashedKey[:branchDepth])
+			}
+			return accountProof, storageProof, nil // empty trie
+		}
+		node, err := hph.proofBranchNode(bitmap, &cells, branchDepth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if h := hph.proofHash(node); branchHash != nil && !bytes.Equal(h, branchHash) {
+			return nil, nil, fmt.Errorf("branch [%x] hash mismatch: expected %x, computed %x", hashedKey[:branchDepth], branchHash, h)
+		}
+		add(branchDepth, node)
+
+		nibble := hashedKey[branchDepth]
+		if bitmap&(uint16(1)<<nibble) == 0 {
+			return accountProof, storageProof, nil
+		}
+		cell, depth = cells[nibble], branchDepth+1
+	}
+}
+
+// proofBranchCells loads branch node by given hashed prefix and fills it's cells, the same way unfoldBranchNode does
+func (hph *HexPatriciaHashed) proofBranchCells(prefix []byte, cells *[16]Cell) (bitmap uint16, err error) {
+	branchData, err := hph.branchFn(hexToCompact(prefix))
+	if err != nil {
+		return 0, err
+	}
+	if len(branchData) == 0 {
+		return 0, nil
+	}
+	if len(branchData) < 2 {
+		return 0, fmt.Errorf("branch [%x]: data too short", prefix)
+	}
+	bitmap = binary.BigEndian.Uint16(branchData[0:])
+	pos := 2
+	for bitset := bitmap; bitset != 0; bitset &= bitset - 1 {
+		nibble := bits.TrailingZeros16(bitset)
+		cell := &cells[nibble]
+		cell.fillEmpty()
+		if pos >= len(branchData) {
+			return 0, fmt.Errorf("branch [%x]: data too short", prefix)
+		}
+		fieldBits := PartFlags(branchData[pos])
+		pos++
+		if pos, err = cell.fillFromFields(branchData, pos, fieldBits); err != nil {
+			return 0, fmt.Errorf("prefix [%x], branchData[%x]: %w", prefix, branchData, err)
+		}
+		if cell.apl > 0 {
+			if err = hph.accountFn(cell.apk[:cell.apl], cell); err != nil {
+				return 0, err
+			}
+		}
+		if cell.spl > 0 {
+			if err = hph.storageFn(cell.spk[:cell.spl], cell); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return bitmap, nil
+}
+
+// proofBranchNode produces RLP of full node: 16 children references and empty value
+func (hph *HexPatriciaHashed) proofBranchNode(bitmap uint16, cells *[16]Cell, depth int) ([]byte, error) {
+	payload := make([]byte, 0, 16*(length.Hash+1)+1)
+	for nibble := 0; nibble < 16; nibble++ {
+		if bitmap&(uint16(1)<<nibble) == 0 {
+			payload = append(payload, 0x80)
+			continue
+		}
+		var err error
+		if payload, err = hph.computeCellHash(&cells[nibble], depth, payload); err != nil {
+			return nil, err
+		}
+	}
+	payload = append(payload, 0x80)
+	return appendRlpList(nil, payload), nil
+}
+
+func (hph *HexPatriciaHashed) proofHash(node []byte) []byte {
+	hph.keccak2.Reset()
+	hph.keccak2.Write(node)
+	var h [length.Hash]byte
+	hph.keccak2.Read(h[:])
+	return h[:]
+}
+
+// proofShortNode produces RLP of leaf (key has terminator) or extension node, value is already RLP-encoded
+// for extension and is raw value for leaf
+func proofShortNode(key []byte, value []byte) []byte {
+	payload := appendRlpString(nil, hexToCompact(key))
+	if hasTerm(key) {
+		payload = appendRlpString(payload, value)
+	} else {
+		payload = append(payload, value...)
+	}
+	return appendRlpList(nil, payload)
+}
+
+// proofStorageValue - storage leaf keeps RLP-encoded value
+func proofStorageValue(v []byte) []byte { return appendRlpString(nil, v) }
+
+func rlpStringPayload(s []byte) []byte { return appendRlpString(nil, s) }
+
+func appendRlpString(buf, s []byte) []byte {
+	if len(s) == 1 && s[0] < 0x80 {
+		return append(buf, s[0])
+	}
+	buf = appendRlpPrefix(buf, 0x80, len(s))
+	return append(buf, s...)
+}
+
+func appendRlpList(buf, payload []byte) []byte {
+	buf = appendRlpPrefix(buf, 0xc0, len(payload))
+	return append(buf, payload...)
+}
+
+func appendRlpPrefix(buf []byte, base byte, l int) []byte {
+	if l < 56 {
+		return append(buf, base+byte(l))
+	}
+	var lenBuf [8]byte
+	binary.BigEndian.PutUint64(lenBuf[:], uint64(l))
+	beLen := (bits.Len64(uint64(l)) + 7) / 8
+	buf = append(buf, base+55+byte(beLen))
+	return append(buf, lenBuf[8-beLen:]...)
+}
+
+// VerifyProof checks proof of key against root hash. hashedKey is keccak of the plain key (address for account trie,
+// storage location for storage trie). Returns value of the leaf: RLP-encoded account or RLP-encoded storage value,
+// or nil if proof proves absence of the key.
+func VerifyProof(rootHash, hashedKey []byte, proof [][]byte) (value []byte, err error) {
+	key := make([]byte, len(hashedKey)*2)
+	for i, b := range hashedKey {
+		key[i*2], key[i*2+1] = b>>4, b&0xf
+	}
+	keccak := sha3.NewLegacyKeccak256().(keccakState)
+	var h [length.Hash]byte
+
+	wantHash := rootHash
+	var node []byte // node embedded into parent
+	used := 0
+	absent := func() ([]byte, error) {
+		if used != len(proof) {
+			return nil, fmt.Errorf("proof has %d unused nodes", len(proof)-used)
+		}
+		return nil, nil
+	}
+	for pos := 0; ; {
+		if node == nil {
+			if used == len(proof) {
+				if used == 0 && bytes.Equal(rootHash, EmptyRootHash) {
+					return nil, nil
+				}
+				return nil, fmt.Errorf("proof is too short: %d nodes", len(proof))
+			}
+			node = proof[used]
+			used++
+			keccak.Reset()
+			keccak.Write(node)
+			keccak.Read(h[:])
+			if !bytes.Equal(h[:], wantHash) {
+				return nil, fmt.Errorf("node %d: hash mismatch, expected %x, got %x", used-1, wantHash, h)
+			}
+		}
+		items, err := proofNodeItems(node)
+		if err != nil {
+			return nil, fmt.Errorf("node %d: %w", used-1, err)
+		}
+		var child []byte
+		switch len(items) {
+		case 17:
+			if pos >= len(key) {
+				return nil, fmt.Errorf("node %d: branch at the end of key", used-1)
+			}
+			child = items[key[pos]]
+			pos++
+		case 2:
+			compact, err := proofItemString(items[0])
+			if err != nil {
+				return nil, fmt.Errorf("node %d: key: %w", used-1, err)
+			}
+			if len(compact) == 0 {
+				return nil, fmt.Errorf("node %d: empty key", used-1)
+			}
+			path := CompactedKeyToHex(compact)
+			if compact[0]&0x20 != 0 { // leaf
+				if hasTerm(path) {
+					path = path[:len(path)-1]
+				}
+				if !bytes.Equal(path, key[pos:]) {
+					return absent()
+				}
+				if used != len(proof) {
+					return nil, fmt.Errorf("proof has %d unused nodes", len(proof)-used)
+				}
+				return proofItemString(items[1])
+			}
+			if !bytes.HasPrefix(key[pos:], path) {
+				return absent()
+			}
+			pos += len(path)
+			child = items[1]
+		default:
+			return nil, fmt.Errorf("node %d: unexpected amount of items %d", used-1, len(items))
+		}
+		switch {
+		case len(child) == 1 && child[0] == 0x80:
+			return absent()
+		case child[0] >= 0xc0: // embedded node
+			node = child
+		default:
+			if wantHash, err = proofItemString(child); err != nil {
+				return nil, fmt.Errorf("node %d: child: %w", used-1, err)
+			}
+			if len(wantHash) != length.Hash {
+				return nil, fmt.Errorf("node %d: child reference of length %d", used-1, len(wantHash))
+			}
+			node = nil
+		}
+	}
+}
+
+// proofNodeItems splits RLP list to the raw RLP encodings of it's items
+func proofNodeItems(node []byte) (items [][]byte, err error) {
+	dataPos, dataLen, err := rlp.List(node, 0)
+	if err != nil {
+		return nil, err
+	}
+	if dataPos+dataLen != len(node) {
+		return nil, fmt.Errorf("trailing bytes after node")
+	}
+	for pos := dataPos; pos < dataPos+dataLen; {
+		itemPos, itemLen, _, err := rlp.Prefix(node, pos)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, node[pos:itemPos+itemLen])
+		pos = itemPos + itemLen
+	}
+	return items, nil
+}
+
+func proofItemString(item []byte) ([]byte, error) {
+	dataPos, dataLen, err := rlp.String(item, 0)
+	if err != nil {
+		return nil, err
+	}
+	return item[dataPos : dataPos+dataLen], nil
+}
+
+// Verify checks account proof against state root and storage proofs against storage root of the account.
+// Returns RLP-encoded account (nil if absent) and values of storage slots (nil for absent ones).
+func (p *AccountProof) Verify(rootHash []byte) (account []byte, storage [][]byte, err error) {
+	keccak := sha3.NewLegacyKeccak256()
+	keccak.Write(p.Address)
+	if account, err = VerifyProof(rootHash, keccak.Sum(nil), p.AccountProof); err != nil {
+		return nil, nil, fmt.Errorf("account %x: %w", p.Address, err)
+	}
+	storageRoot := EmptyRootHash
+	if account != nil {
+		if storageRoot, err = accountStorageRoot(account); err != nil {
+			return nil, nil, fmt.Errorf("account %x: %w", p.Address, err)
+		}
+	}
+	storage = make([][]byte, len(p.StorageProof))
+	for i, sp := range p.StorageProof {
+		keccak.Reset()
+		keccak.Write(sp.Key)
+		v, err := VerifyProof(storageRoot, keccak.Sum(nil), sp.Proof)
+		if err != nil {
+			return nil, nil, fmt.Errorf("storage %x: %w", sp.Key, err)
+		}
+		if v != nil {
+			if storage[i], err = proofItemString(v); err != nil {
+				return nil, nil, fmt.Errorf("storage %x: %w", sp.Key, err)
+			}
+		}
+	}
+	return account, storage, nil
+}
+
+// accountStorageRoot extracts storage root from RLP-encoded account [nonce, balance, storageRoot, codeHash]
+func accountStorageRoot(account []byte) ([]byte, error) {
+	items, err := proofNodeItems(account)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != 4 {
+		return nil, fmt.Errorf("account has %d fields", len(items))
+	}
+	root, err := proofItemString(items[2])
+	if err != nil {
+		return nil, err
+	}
+	if len(root) != length.Hash {
+		return nil, fmt.Errorf("storage root of length %d", len(root))
+	}
+	return root, nil
+}
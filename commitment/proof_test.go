@@ -0,0 +1,136 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commitment
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common/length"
+)
+
+func Test_HexPatriciaHashed_ProveAccount(t *testing.T) {
+	ms := NewMockState(t)
+	hph := NewHexPatriciaHashed(1, ms.branchFn, ms.accountFn, ms.storageFn)
+
+	builder := NewUpdateBuilder()
+	storage := map[string]map[string]string{}
+	for i := 0; i < 40; i++ {
+		addr := fmt.Sprintf("%02x", i*3)
+		builder.Balance(addr, uint64(i+1)).Nonce(addr, uint64(i))
+		storage[addr] = map[string]string{}
+		for j := 0; j < i%4; j++ { // accounts without storage, with singleton storage and with storage trie
+			loc, val := fmt.Sprintf("%02x", j*7), fmt.Sprintf("%04x", i*256+j+1)
+			builder.Storage(addr, loc, val)
+			storage[addr][loc] = val
+		}
+	}
+	plainKeys, hashedKeys, updates := builder.Build()
+	require.NoError(t, ms.applyPlainUpdates(plainKeys, updates))
+	rootHash, branchNodeUpdates, err := hph.ReviewKeys(plainKeys, hashedKeys)
+	require.NoError(t, err)
+	ms.applyBranchNodeUpdates(branchNodeUpdates)
+
+	check := func(t *testing.T) {
+		t.Helper()
+		for addr, slots := range storage {
+			locs := [][]byte{decodeHex("ff")} // absent slot
+			for loc := range slots {
+				locs = append(locs, decodeHex(loc))
+			}
+			proof, err := hph.ProveAccount(decodeHex(addr), locs...)
+			require.NoError(t, err)
+			account, values, err := proof.Verify(rootHash)
+			require.NoError(t, err, addr)
+			require.NotNil(t, account)
+			require.Nil(t, values[0])
+			for i, loc := range locs[1:] {
+				require.Equal(t, mockStorageValue(slots[hex.EncodeToString(loc)]), values[i+1])
+			}
+		}
+		proof, err := hph.ProveAccount(decodeHex("01"), decodeHex("00"))
+		require.NoError(t, err)
+		account, values, err := proof.Verify(rootHash)
+		require.NoError(t, err)
+		require.Nil(t, account)
+		require.Nil(t, values[0])
+	}
+	t.Run("after_review", check)
+	hph.Reset()
+	t.Run("after_reset", check)
+
+	t.Run("tampered", func(t *testing.T) {
+		proof, err := hph.ProveAccount(decodeHex("09"), decodeHex("07"))
+		require.NoError(t, err)
+		_, _, err = proof.Verify(rootHash)
+		require.NoError(t, err)
+
+		last := proof.StorageProof[0].Proof[len(proof.StorageProof[0].Proof)-1]
+		last[len(last)-1] ^= 0xff
+		_, _, err = proof.Verify(rootHash)
+		require.Error(t, err)
+
+		proof.AccountProof = proof.AccountProof[:len(proof.AccountProof)-1]
+		_, _, err = proof.Verify(rootHash)
+		require.Error(t, err)
+	})
+}
+
+func Test_HexPatriciaHashed_ProveEmptyTrie(t *testing.T) {
+	ms := NewMockState(t)
+	hph := NewHexPatriciaHashed(1, ms.branchFn, ms.accountFn, ms.storageFn)
+
+	proof, err := hph.ProveAccount(decodeHex("01"), decodeHex("02"))
+	require.NoError(t, err)
+	require.Empty(t, proof.AccountProof)
+	account, values, err := proof.Verify(EmptyRootHash)
+	require.NoError(t, err)
+	require.Nil(t, account)
+	require.Nil(t, values[0])
+}
+
+func Test_HexPatriciaHashed_ProveSingleAccount(t *testing.T) {
+	ms := NewMockState(t)
+	hph := NewHexPatriciaHashed(1, ms.branchFn, ms.accountFn, ms.storageFn)
+
+	plainKeys, hashedKeys, updates := NewUpdateBuilder().
+		Balance("05", 9).
+		Storage("05", "02", "8989").
+		Build()
+	require.NoError(t, ms.applyPlainUpdates(plainKeys, updates))
+	rootHash, branchNodeUpdates, err := hph.ReviewKeys(plainKeys, hashedKeys)
+	require.NoError(t, err)
+	ms.applyBranchNodeUpdates(branchNodeUpdates)
+
+	proof, err := hph.ProveAccount(decodeHex("05"), decodeHex("02"))
+	require.NoError(t, err)
+	require.Len(t, proof.AccountProof, 1)
+	account, values, err := proof.Verify(rootHash)
+	require.NoError(t, err)
+	require.NotNil(t, account)
+	require.Equal(t, mockStorageValue("8989"), values[0])
+}
+
+// mockStorageValue - MockState always returns storage values of length.Hash bytes
+func mockStorageValue(v string) []byte {
+	value := make([]byte, length.Hash)
+	copy(value, decodeHex(v))
+	return value
+}
@@ -0,0 +1,599 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commitment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"github.com/holiman/uint256"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common"
+	"github.com/gateway-fm/cdk-erigon-lib/common/length"
+)
+
+// SparseMerkleTrie implements zkEVM-style commitment: binary sparse Merkle tree hashed with Poseidon over Goldilocks field.
+//
+// Each account is split into separate leaves for balance, nonce, code hash and code length, each storage slot is a leaf too.
+// Code hash is Poseidon hash of bytecode (see PoseidonBytecodeHash), so trie reads code of contracts via codeFn.
+// Key of the leaf is Poseidon(address, leaf type; capacity=Poseidon(slot) for storage, Poseidon(0) for others), path to the leaf is taken
+// bit by bit from key limbs (bit i is bit i/4 of limb i%4). Leaf is placed at the shortest path which distinguishes
+// it from other leaves, it's hash is Poseidon(remaining key bits, Poseidon(value); capacity=1).
+// Branch hash is Poseidon(left, right), empty subtree hashes to zero. Leaves with zero value are absent.
+//
+// Branch nodes are stored in commitment domain as BranchData with 2 cells, under binToCompact(path) prefix:
+// leaf cell holds leaf key as HashedKeyPart, plain key and hash of the value, branch cell holds only hash.
+// Every update writes both cells, so BranchMerger merges SMT branches the same way as hex ones.
+// Root node (empty path) is always stored, so trie keeps no state between evaluations.
+type SparseMerkleTrie struct {
+	accountKeyLen int
+	root          *smtNode
+	deleted       map[string]struct{} // paths of branches collapsed during current evaluation
+	hashFn        PoseidonFn
+	keyCapacity   [4]uint64 // capacity of keys of account leaves - hash of zeros
+	trace         bool
+	witness       *Witness // if set, records branches and leaves read by the trie
+
+	branchFn  func(prefix []byte) ([]byte, error)
+	accountFn func(plainKey []byte, cell *Cell) error
+	storageFn func(plainKey []byte, cell *Cell) error
+	codeFn    func(addr []byte) ([]byte, error)
+}
+
+func NewSparseMerkleTrie(accountKeyLen int,
+	branchFn func(prefix []byte) ([]byte, error),
+	accountFn func(plainKey []byte, cell *Cell) error,
+	storageFn func(plainKey []byte, cell *Cell) error,
+) *SparseMerkleTrie {
+	return &SparseMerkleTrie{
+		accountKeyLen: accountKeyLen,
+		hashFn:        Poseidon,
+		keyCapacity:   Poseidon([8]uint64{}, [4]uint64{}),
+		deleted:       make(map[string]struct{}),
+		branchFn:      branchFn,
+		accountFn:     accountFn,
+		storageFn:     storageFn,
+	}
+}
+
+// leaf types, part of the leaf key
+const (
+	smtKeyBalance    = 0
+	smtKeyNonce      = 1
+	smtKeyCode       = 2
+	smtKeyStorage    = 3
+	smtKeyCodeLength = 4
+)
+
+const smtMaxDepth = 256
+
+const (
+	smtEmpty = iota
+	smtLeaf
+	smtBranch
+)
+
+type smtNode struct {
+	children [2]smtChild
+	dirty    bool // node has to be rehashed and written
+}
+
+type smtChild struct {
+	kind     int
+	node     *smtNode  // loaded branch, nil if branch was not read yet
+	hash     [4]uint64 // hash of the branch or hash of the leaf value
+	key      [4]uint64 // leaf key
+	plainKey []byte    // leaf account or storage plain key
+}
+
+func (t *SparseMerkleTrie) Variant() TrieVariant { return VariantSparseMerkleTrie }
+
+func (t *SparseMerkleTrie) SetTrace(trace bool) { t.trace = trace }
+
+// SetParallel is not supported by sparse Merkle trie, keys are always processed sequentially
+func (t *SparseMerkleTrie) SetParallel(bool) {}
+
+// SetWitness starts recording of branches and leaves read by the trie. Contract code read by codeFn is not recorded,
+// trie evaluated from witness needs codeFn for keys with code updates
+func (t *SparseMerkleTrie) SetWitness(w *Witness) { t.witness = w }
+
+func (t *SparseMerkleTrie) readBranch(prefix []byte) ([]byte, error) {
+	branchData, err := t.branchFn(prefix)
+	if err == nil && t.witness != nil {
+		t.witness.addBranch(prefix, branchData)
+	}
+	return branchData, err
+}
+
+func (t *SparseMerkleTrie) readAccount(plainKey []byte, cell *Cell) error {
+	err := t.accountFn(plainKey, cell)
+	if err == nil && t.witness != nil {
+		t.witness.addLeaf(plainKey, cell, true)
+	}
+	return err
+}
+
+func (t *SparseMerkleTrie) readStorage(plainKey []byte, cell *Cell) error {
+	err := t.storageFn(plainKey, cell)
+	if err == nil && t.witness != nil {
+		t.witness.addLeaf(plainKey, cell, false)
+	}
+	return err
+}
+
+// SetHashFn replaces Poseidon used by the trie, e.g. by implementation with constants of particular chain
+func (t *SparseMerkleTrie) SetHashFn(fn PoseidonFn) {
+	t.hashFn = fn
+	t.keyCapacity = fn([8]uint64{}, [4]uint64{})
+	t.Reset()
+}
+
+// SetCodeFn sets reader of contract code by address: code hash of updates is keccak, while leaves keep Poseidon hash of code
+func (t *SparseMerkleTrie) SetCodeFn(codeFn func(addr []byte) ([]byte, error)) {
+	t.codeFn = codeFn
+}
+
+// Reset drops loaded nodes, they are read again from branchFn
+func (t *SparseMerkleTrie) Reset() {
+	t.root = nil
+	t.deleted = make(map[string]struct{})
+}
+
+func (t *SparseMerkleTrie) ResetFns(
+	branchFn func(prefix []byte) ([]byte, error),
+	accountFn func(plainKey []byte, cell *Cell) error,
+	storageFn func(plainKey []byte, cell *Cell) error,
+) {
+	t.branchFn = branchFn
+	t.accountFn = accountFn
+	t.storageFn = storageFn
+}
+
+func (t *SparseMerkleTrie) RootHash() ([]byte, error) {
+	if err := t.loadRoot(); err != nil {
+		return nil, err
+	}
+	var h [4]uint64
+	switch c0, c1 := &t.root.children[0], &t.root.children[1]; {
+	case c0.kind == smtEmpty && c1.kind == smtEmpty:
+	case c0.kind == smtLeaf && c1.kind == smtEmpty:
+		h = t.leafHash(c0, 0)
+	case c0.kind == smtEmpty && c1.kind == smtLeaf:
+		h = t.leafHash(c1, 0)
+	default:
+		h = t.nodeHash(t.root, 1)
+	}
+	return smtHashToBytes(h), nil
+}
+
+// ReviewKeys reads current values of given keys via accountFn and storageFn and puts them into the trie.
+// Hashed keys are not used, SMT derives keys of it's leaves itself.
+func (t *SparseMerkleTrie) ReviewKeys(plainKeys, _ [][]byte) (rootHash []byte, branchNodeUpdates map[string]BranchData, err error) {
+	updates := make([]Update, len(plainKeys))
+	var cell Cell
+	for i, plainKey := range plainKeys {
+		cell.Balance.Clear()
+		cell.Nonce, cell.StorageLen, cell.Delete = 0, 0, false
+		copy(cell.CodeHash[:], EmptyCodeHash)
+		u := &updates[i]
+		if len(plainKey) == t.accountKeyLen {
+			if err = t.readAccount(plainKey, &cell); err != nil {
+				return nil, nil, fmt.Errorf("accountFn for key %x failed: %w", plainKey, err)
+			}
+			if cell.Delete {
+				u.Flags = DeleteUpdate
+				continue
+			}
+			u.Flags = BalanceUpdate | NonceUpdate | CodeUpdate
+			u.Balance.Set(&cell.Balance)
+			u.Nonce = cell.Nonce
+			copy(u.CodeHashOrStorage[:], cell.CodeHash[:])
+			continue
+		}
+		if err = t.readStorage(plainKey, &cell); err != nil {
+			return nil, nil, fmt.Errorf("storageFn for key %x failed: %w", plainKey, err)
+		}
+		if cell.Delete || cell.StorageLen == 0 {
+			u.Flags = DeleteUpdate
+			continue
+		}
+		u.Flags = StorageUpdate
+		u.ValLength = cell.StorageLen
+		copy(u.CodeHashOrStorage[:], cell.Storage[:cell.StorageLen])
+	}
+	return t.ProcessUpdates(plainKeys, nil, updates)
+}
+
+// ProcessUpdates applies updates to the leaves of given plain keys. Hashed keys are not used.
+func (t *SparseMerkleTrie) ProcessUpdates(plainKeys, _ [][]byte, updates []Update) (rootHash []byte, branchNodeUpdates map[string]BranchData, err error) {
+	if err = t.loadRoot(); err != nil {
+		return nil, nil, err
+	}
+	for i, plainKey := range plainKeys {
+		update := &updates[i]
+		if t.trace {
+			fmt.Printf("smt update [%x] %s\n", plainKey, update.Flags)
+		}
+		if len(plainKey) == t.accountKeyLen {
+			var balance, nonce, code, codeLength *uint256.Int // nil means leaf is not updated
+			if update.Flags&DeleteUpdate != 0 {
+				balance, nonce, code, codeLength = new(uint256.Int), new(uint256.Int), new(uint256.Int), new(uint256.Int)
+			}
+			if update.Flags&BalanceUpdate != 0 {
+				balance = new(uint256.Int).Set(&update.Balance)
+			}
+			if update.Flags&NonceUpdate != 0 {
+				nonce = uint256.NewInt(update.Nonce)
+			}
+			if update.Flags&CodeUpdate != 0 {
+				code, codeLength = new(uint256.Int), new(uint256.Int)
+				if !bytes.Equal(update.CodeHashOrStorage[:], EmptyCodeHash) {
+					if err = t.contractCode(plainKey, code, codeLength); err != nil {
+						return nil, nil, err
+					}
+				}
+			}
+			for _, leaf := range []struct {
+				typ   uint64
+				value *uint256.Int
+			}{{smtKeyBalance, balance}, {smtKeyNonce, nonce}, {smtKeyCode, code}, {smtKeyCodeLength, codeLength}} {
+				if leaf.value == nil {
+					continue
+				}
+				if err = t.setLeaf(t.leafKey(plainKey, leaf.typ, nil), plainKey, leaf.value); err != nil {
+					return nil, nil, fmt.Errorf("update [%x]: %w", plainKey, err)
+				}
+			}
+			continue
+		}
+		value := new(uint256.Int)
+		if update.Flags&StorageUpdate != 0 {
+			value.SetBytes(update.CodeHashOrStorage[:update.ValLength])
+		}
+		if err = t.setLeaf(t.leafKey(plainKey[:t.accountKeyLen], smtKeyStorage, plainKey[t.accountKeyLen:]), plainKey, value); err != nil {
+			return nil, nil, fmt.Errorf("update [%x]: %w", plainKey, err)
+		}
+	}
+
+	branchNodeUpdates = make(map[string]BranchData)
+	if err = t.collectUpdates(t.root, nil, branchNodeUpdates); err != nil {
+		return nil, nil, err
+	}
+	for path := range t.deleted {
+		if _, ok := branchNodeUpdates[path]; !ok {
+			branchNodeUpdates[path] = BranchData{0, 3, 0, 0}
+		}
+	}
+	t.deleted = make(map[string]struct{})
+
+	rootHash, err = t.RootHash()
+	if err != nil {
+		return nil, branchNodeUpdates, fmt.Errorf("root hash evaluation failed: %w", err)
+	}
+	return rootHash, branchNodeUpdates, nil
+}
+
+// leafKey derives SMT key from address, leaf type and storage slot
+func (t *SparseMerkleTrie) leafKey(addr []byte, typ uint64, slot []byte) [4]uint64 {
+	var in [8]uint64
+	limbs := smtLimbs(addr)
+	copy(in[:5], limbs[:5])
+	in[6] = typ
+	capacity := t.keyCapacity
+	if slot != nil {
+		capacity = t.hashFn(smtLimbs(slot), [4]uint64{})
+	}
+	return t.hashFn(in, capacity)
+}
+
+// contractCode sets values of code hash and code length leaves of the contract
+func (t *SparseMerkleTrie) contractCode(addr []byte, hash, codeLength *uint256.Int) error {
+	if t.codeFn == nil {
+		return fmt.Errorf("code of [%x] is updated, but sparse Merkle trie has no codeFn", addr)
+	}
+	code, err := t.codeFn(addr)
+	if err != nil {
+		return fmt.Errorf("codeFn for key %x failed: %w", addr, err)
+	}
+	if len(code) == 0 {
+		return nil
+	}
+	*hash = uint256.Int(PoseidonBytecodeHash(code, t.hashFn))
+	codeLength.SetUint64(uint64(len(code)))
+	return nil
+}
+
+// PoseidonBytecodeHash - hash of contract code used by zkEVM. Code is padded by 0x01 and zeros to multiple of 56 bytes,
+// last byte gets 0x80 bit. Every 56 bytes are 8 little-endian elements of 7 bytes, hashed with previous hash as capacity.
+func PoseidonBytecodeHash(code []byte, hashFn PoseidonFn) [4]uint64 {
+	const elementBytes, chunkBytes = 7, 8 * 7
+	padded := make([]byte, (len(code)/chunkBytes+1)*chunkBytes)
+	copy(padded, code)
+	padded[len(code)] = 0x01
+	padded[len(padded)-1] |= 0x80
+
+	var h [4]uint64
+	for chunk := padded; len(chunk) > 0; chunk = chunk[chunkBytes:] {
+		var in [8]uint64
+		for i := range in {
+			for j := elementBytes - 1; j >= 0; j-- {
+				in[i] = in[i]<<8 | uint64(chunk[i*elementBytes+j])
+			}
+		}
+		h = hashFn(in, h)
+	}
+	return h
+}
+
+// setLeaf puts value into the leaf with given key, zero value deletes the leaf
+func (t *SparseMerkleTrie) setLeaf(key [4]uint64, plainKey []byte, value *uint256.Int) error {
+	var valueHash *[4]uint64
+	if !value.IsZero() {
+		var limbs [8]uint64
+		for i, w := range value {
+			limbs[2*i], limbs[2*i+1] = w&0xffffffff, w>>32
+		}
+		h := t.hashFn(limbs, [4]uint64{})
+		valueHash = &h
+	}
+	return t.set(t.root, make([]byte, 0, smtMaxDepth), key, plainKey, valueHash)
+}
+
+// set updates leaf under the node at path, nil valueHash deletes leaf
+func (t *SparseMerkleTrie) set(n *smtNode, path []byte, key [4]uint64, plainKey []byte, valueHash *[4]uint64) error {
+	level := len(path)
+	if level >= smtMaxDepth {
+		return fmt.Errorf("smt key collision at %x", key)
+	}
+	bit := smtKeyBit(key, level)
+	c := &n.children[bit]
+	childPath := append(path, bit)
+
+	switch c.kind {
+	case smtEmpty:
+		if valueHash == nil {
+			return nil
+		}
+		*c = smtChild{kind: smtLeaf, key: key, hash: *valueHash, plainKey: common.Copy(plainKey)}
+		n.dirty = true
+	case smtLeaf:
+		if c.key == key {
+			if valueHash == nil {
+				*c = smtChild{}
+			} else {
+				c.hash, c.plainKey = *valueHash, common.Copy(plainKey)
+			}
+			n.dirty = true
+			return nil
+		}
+		if valueHash == nil {
+			return nil
+		}
+		// leaves share the path so far, push existing one down
+		child := &smtNode{dirty: true}
+		child.children[smtKeyBit(c.key, level+1)] = *c
+		*c = smtChild{kind: smtBranch, node: child}
+		n.dirty = true
+		return t.set(child, childPath, key, plainKey, valueHash)
+	case smtBranch:
+		if err := t.loadChild(c, childPath); err != nil {
+			return err
+		}
+		if err := t.set(c.node, childPath, key, plainKey, valueHash); err != nil {
+			return err
+		}
+		if !c.node.dirty {
+			return nil
+		}
+		n.dirty = true
+		// branch with no leaves below it or single leaf is replaced by leaf
+		c0, c1 := c.node.children[0], c.node.children[1]
+		var replacement *smtChild
+		switch {
+		case c0.kind == smtEmpty && c1.kind == smtEmpty:
+			replacement = &smtChild{}
+		case c0.kind == smtLeaf && c1.kind == smtEmpty:
+			replacement = &c0
+		case c0.kind == smtEmpty && c1.kind == smtLeaf:
+			replacement = &c1
+		}
+		if replacement != nil {
+			t.deleted[string(binToCompact(childPath))] = struct{}{}
+			*c = *replacement
+		}
+	}
+	return nil
+}
+
+func (t *SparseMerkleTrie) loadRoot() error {
+	if t.root != nil {
+		return nil
+	}
+	root := smtChild{kind: smtBranch}
+	if err := t.loadChild(&root, nil); err != nil {
+		return err
+	}
+	t.root = root.node
+	return nil
+}
+
+// loadChild reads branch node at given path from branchFn
+func (t *SparseMerkleTrie) loadChild(c *smtChild, path []byte) error {
+	if c.node != nil {
+		return nil
+	}
+	c.node = &smtNode{}
+	branchData, err := t.readBranch(binToCompact(path))
+	if err != nil {
+		return err
+	}
+	if len(branchData) == 0 {
+		return nil
+	}
+	if len(branchData) < 2 {
+		return fmt.Errorf("smt branch [%x] is too short", path)
+	}
+	afterMap := binary.BigEndian.Uint16(branchData[0:]) // touchMap is skipped by branchFn
+	pos := 2
+	var cell Cell
+	for bitset := afterMap; bitset != 0; bitset &= bitset - 1 {
+		nibble := bits.TrailingZeros16(bitset)
+		if nibble > 1 || pos >= len(branchData) {
+			return fmt.Errorf("smt branch [%x] is malformed", path)
+		}
+		fieldBits := PartFlags(branchData[pos])
+		if pos, err = cell.fillFromFields(branchData, pos+1, fieldBits); err != nil {
+			return fmt.Errorf("smt branch [%x]: %w", path, err)
+		}
+		child := &c.node.children[nibble]
+		child.hash = smtHashFromBytes(cell.h[:cell.hl])
+		if fieldBits&HashedKeyPart == 0 {
+			child.kind = smtBranch
+			continue
+		}
+		child.kind = smtLeaf
+		child.key = smtHashFromBytes(cell.extension[:cell.extLen])
+		switch {
+		case cell.spl > 0:
+			child.plainKey = common.Copy(cell.spk[:cell.spl])
+		case cell.apl > 0:
+			child.plainKey = common.Copy(cell.apk[:cell.apl])
+		}
+	}
+	return nil
+}
+
+// collectUpdates rehashes dirty branches and encodes them into branchNodeUpdates
+func (t *SparseMerkleTrie) collectUpdates(n *smtNode, path []byte, branchNodeUpdates map[string]BranchData) error {
+	if !n.dirty {
+		return nil
+	}
+	var afterMap uint16
+	for i := range n.children {
+		c := &n.children[i]
+		if c.kind == smtBranch && c.node != nil && c.node.dirty {
+			if err := t.collectUpdates(c.node, append(path, byte(i)), branchNodeUpdates); err != nil {
+				return err
+			}
+			c.hash = t.nodeHash(c.node, len(path)+2)
+		}
+		if c.kind != smtEmpty {
+			afterMap |= 1 << i
+		}
+	}
+	branchData := make(BranchData, 4, 4+2*(1+3*(1+length.Hash)+length.Addr+length.Hash))
+	binary.BigEndian.PutUint16(branchData[0:], 3)
+	binary.BigEndian.PutUint16(branchData[2:], afterMap)
+	putField := func(b []byte) {
+		branchData = binary.AppendUvarint(branchData, uint64(len(b)))
+		branchData = append(branchData, b...)
+	}
+	for i := range n.children {
+		c := &n.children[i]
+		switch c.kind {
+		case smtBranch:
+			branchData = append(branchData, byte(HashPart))
+			putField(smtHashToBytes(c.hash))
+		case smtLeaf:
+			fieldBits := HashedKeyPart | HashPart
+			if len(c.plainKey) == t.accountKeyLen {
+				fieldBits |= AccountPlainPart
+			} else {
+				fieldBits |= StoragePlainPart
+			}
+			branchData = append(branchData, byte(fieldBits))
+			putField(smtHashToBytes(c.key))
+			putField(c.plainKey)
+			putField(smtHashToBytes(c.hash))
+		}
+	}
+	updateKey := binToCompact(path)
+	branchNodeUpdates[string(updateKey)] = branchData
+	n.dirty = false
+	if t.trace {
+		fmt.Printf("smt branch [%x] => %x\n", updateKey, branchData)
+	}
+	return nil
+}
+
+// nodeHash returns hash of the branch, children of which are at given level
+func (t *SparseMerkleTrie) nodeHash(n *smtNode, level int) [4]uint64 {
+	var in [8]uint64
+	for i := range n.children {
+		var h [4]uint64
+		switch c := &n.children[i]; c.kind {
+		case smtLeaf:
+			h = t.leafHash(c, level)
+		case smtBranch:
+			h = c.hash
+			if c.node != nil && c.node.dirty {
+				h = t.nodeHash(c.node, level+1)
+			}
+		}
+		copy(in[4*i:], h[:])
+	}
+	return t.hashFn(in, [4]uint64{})
+}
+
+// leafHash returns hash of the leaf at given level, level bits of the key are already encoded by the path
+func (t *SparseMerkleTrie) leafHash(c *smtChild, level int) [4]uint64 {
+	var in [8]uint64
+	for i := range c.key {
+		shift := level / 4
+		if i < level%4 {
+			shift++
+		}
+		if shift < 64 {
+			in[i] = c.key[i] >> shift
+		}
+	}
+	copy(in[4:], c.hash[:])
+	return t.hashFn(in, [4]uint64{1})
+}
+
+func smtKeyBit(key [4]uint64, level int) byte {
+	return byte(key[level%4]>>(level/4)) & 1
+}
+
+// smtLimbs splits big-endian number of up to 32 bytes into 8 little-endian 32-bit limbs
+func smtLimbs(b []byte) (limbs [8]uint64) {
+	var buf [32]byte
+	copy(buf[32-len(b):], b)
+	for i := range limbs {
+		limbs[i] = uint64(binary.BigEndian.Uint32(buf[28-4*i:]))
+	}
+	return limbs
+}
+
+func smtHashToBytes(h [4]uint64) []byte {
+	b := make([]byte, length.Hash)
+	for i := range h {
+		binary.BigEndian.PutUint64(b[24-8*i:], h[i])
+	}
+	return b
+}
+
+func smtHashFromBytes(b []byte) (h [4]uint64) {
+	var buf [length.Hash]byte
+	copy(buf[length.Hash-len(b):], b)
+	for i := range h {
+		h[i] = binary.BigEndian.Uint64(buf[24-8*i:])
+	}
+	return h
+}
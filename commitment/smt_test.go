@@ -0,0 +1,252 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commitment
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common/length"
+)
+
+func Test_Goldilocks_Arithmetic(t *testing.T) {
+	p := new(big.Int).SetUint64(goldilocksP)
+	rnd := rand.New(rand.NewSource(1))
+	values := []uint64{0, 1, 2, goldilocksEpsilon, goldilocksP - 1, goldilocksP - 2, 1 << 63}
+	for i := 0; i < 1000; i++ {
+		values = append(values, rnd.Uint64()%goldilocksP)
+	}
+	for i, a := range values {
+		b := values[(i*7+3)%len(values)]
+		ba, bb := new(big.Int).SetUint64(a), new(big.Int).SetUint64(b)
+		require.Equal(t, new(big.Int).Mod(new(big.Int).Add(ba, bb), p).Uint64(), gAdd(a, b), "%d+%d", a, b)
+		require.Equal(t, new(big.Int).Mod(new(big.Int).Mul(ba, bb), p).Uint64(), gMul(a, b), "%d*%d", a, b)
+	}
+}
+
+func Test_Poseidon(t *testing.T) {
+	in := [8]uint64{1, 2, 3, 4, 5, 6, 7, 8}
+	h := Poseidon(in, [4]uint64{})
+	require.Equal(t, h, Poseidon(in, [4]uint64{}))
+	require.NotEqual(t, h, Poseidon(in, [4]uint64{1}))
+	in[7]++
+	require.NotEqual(t, h, Poseidon(in, [4]uint64{}))
+	for _, v := range h {
+		require.Less(t, v, uint64(goldilocksP))
+	}
+	// inputs are taken modulo p
+	require.Equal(t, Poseidon([8]uint64{}, [4]uint64{}), Poseidon([8]uint64{goldilocksP}, [4]uint64{goldilocksP}))
+
+	// known answers of zkEVM Poseidon (go-iden3-crypto/goldenposeidon)
+	pm1 := uint64(goldilocksP - 1)
+	for _, tc := range []struct {
+		in       [8]uint64
+		capacity [4]uint64
+		expect   [4]uint64
+	}{
+		{
+			expect: [4]uint64{4330397376401421145, 14124799381142128323, 8742572140681234676, 14345658006221440202},
+		},
+		{
+			in:       [8]uint64{1, 1, 1, 1, 1, 1, 1, 1},
+			capacity: [4]uint64{1, 1, 1, 1},
+			expect:   [4]uint64{16428316519797902711, 13351830238340666928, 682362844289978626, 12150588177266359240},
+		},
+		{
+			in:       [8]uint64{pm1, pm1, pm1, pm1, pm1, pm1, pm1, pm1},
+			capacity: [4]uint64{pm1, pm1, pm1, pm1},
+			expect:   [4]uint64{13691089994624172887, 15662102337790434313, 14940024623104903507, 10772674582659927682},
+		},
+		{
+			in:     [8]uint64{923978, 235763497586, 9827635653498, 112870, 289273673480943876, 230295874986745876, 6254867324987, 2087},
+			expect: [4]uint64{1892171027578617759, 984732815927439256, 7866041765487844082, 8161503938059336191},
+		},
+	} {
+		require.Equal(t, tc.expect, Poseidon(tc.in, tc.capacity), "%d %d", tc.in, tc.capacity)
+	}
+}
+
+func Test_SparseMerkleTrie_Incremental(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	ms := NewMockState(t)
+	trie := NewSparseMerkleTrie(length.Addr, ms.branchFn, ms.accountFn, ms.storageFn)
+	trie.SetCodeFn(ms.codeFn)
+
+	rootHash, err := trie.RootHash()
+	require.NoError(t, err)
+	require.Equal(t, make([]byte, length.Hash), rootHash)
+
+	addrs := make([]string, 30)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("%040x", rnd.Uint64())
+	}
+	for block := 0; block < 20; block++ {
+		builder := NewUpdateBuilder()
+		for i := 0; i < 8; i++ {
+			addr := addrs[rnd.Intn(len(addrs))]
+			switch rnd.Intn(6) {
+			case 0:
+				builder.Delete(addr)
+			case 1:
+				builder.Nonce(addr, rnd.Uint64())
+			case 2:
+				builder.Storage(addr, fmt.Sprintf("%02x", rnd.Intn(8)), fmt.Sprintf("%04x", rnd.Intn(1<<16)))
+			case 3:
+				builder.DeleteStorage(addr, fmt.Sprintf("%02x", rnd.Intn(8)))
+			case 4:
+				builder.CodeHash(addr, fmt.Sprintf("%064x", rnd.Uint64()))
+			default:
+				builder.Balance(addr, rnd.Uint64())
+			}
+		}
+		plainKeys, hashedKeys, updates := builder.Build()
+		require.NoError(t, ms.applyPlainUpdates(plainKeys, updates))
+
+		trie.Reset()
+		rootHash, branchNodeUpdates, err := trie.ProcessUpdates(plainKeys, hashedKeys, updates)
+		require.NoError(t, err)
+		ms.applyBranchNodeUpdates(branchNodeUpdates)
+
+		// the same state committed at once
+		fresh := NewMockState(t)
+		var allKeys [][]byte
+		var allUpdates []Update
+		for key, value := range ms.sm {
+			var u Update
+			_, err := u.Decode(value, 0)
+			require.NoError(t, err)
+			allKeys, allUpdates = append(allKeys, []byte(key)), append(allUpdates, u)
+		}
+		batch := NewSparseMerkleTrie(length.Addr, fresh.branchFn, fresh.accountFn, fresh.storageFn)
+		batch.SetCodeFn(ms.codeFn)
+		batchRoot, _, err := batch.ProcessUpdates(allKeys, nil, allUpdates)
+		require.NoError(t, err)
+		require.Equal(t, batchRoot, rootHash, "block %d", block)
+
+		// trie reloaded from stored branches evaluates the same root
+		trie.Reset()
+		reloaded, err := trie.RootHash()
+		require.NoError(t, err)
+		require.Equal(t, rootHash, reloaded)
+	}
+
+	// deletion of everything leads to empty root
+	builder := NewUpdateBuilder()
+	for key := range ms.sm {
+		if len(key) == length.Addr {
+			builder.Delete(fmt.Sprintf("%x", key))
+		} else {
+			builder.DeleteStorage(fmt.Sprintf("%x", key[:length.Addr]), fmt.Sprintf("%x", key[length.Addr:]))
+		}
+	}
+	plainKeys, hashedKeys, updates := builder.Build()
+	trie.Reset()
+	rootHash, _, err = trie.ProcessUpdates(plainKeys, hashedKeys, updates)
+	require.NoError(t, err)
+	require.Equal(t, make([]byte, length.Hash), rootHash)
+}
+
+func Test_SparseMerkleTrie_OrderIndependent(t *testing.T) {
+	builder := NewUpdateBuilder()
+	for i := 0; i < 50; i++ {
+		addr := fmt.Sprintf("%02x", i)
+		builder.Balance(addr, uint64(i+1))
+		if i%3 == 0 {
+			builder.Nonce(addr, uint64(i)).CodeHash(addr, fmt.Sprintf("%064x", i))
+		}
+	}
+	plainKeys, hashedKeys, updates := builder.Build()
+
+	ms := NewMockState(t)
+	require.NoError(t, ms.applyPlainUpdates(plainKeys, updates))
+	trie := NewSparseMerkleTrie(1, ms.branchFn, ms.accountFn, ms.storageFn)
+	trie.SetCodeFn(ms.codeFn)
+	rootHash, _, err := trie.ProcessUpdates(plainKeys, hashedKeys, updates)
+	require.NoError(t, err)
+
+	rnd := rand.New(rand.NewSource(7))
+	rnd.Shuffle(len(plainKeys), func(i, j int) {
+		plainKeys[i], plainKeys[j] = plainKeys[j], plainKeys[i]
+		updates[i], updates[j] = updates[j], updates[i]
+	})
+	ms2 := NewMockState(t)
+	shuffledTrie := NewSparseMerkleTrie(1, ms2.branchFn, ms2.accountFn, ms2.storageFn)
+	shuffledTrie.SetCodeFn(ms.codeFn)
+	shuffled, _, err := shuffledTrie.ProcessUpdates(plainKeys, nil, updates)
+	require.NoError(t, err)
+	require.Equal(t, rootHash, shuffled)
+
+	reviewTrie := NewSparseMerkleTrie(1, ms2.branchFn, ms.accountFn, ms.storageFn)
+	reviewTrie.SetCodeFn(ms.codeFn)
+	reviewed, _, err := reviewTrie.ReviewKeys(plainKeys, nil)
+	require.NoError(t, err)
+	require.Equal(t, rootHash, reviewed)
+
+	require.Equal(t, VariantSparseMerkleTrie, ParseTrieVariant("smt"))
+	require.Equal(t, VariantSparseMerkleTrie, InitializeTrie(VariantSparseMerkleTrie).Variant())
+}
+
+func Test_PoseidonBytecodeHash(t *testing.T) {
+	// known answers of zkEVM (zkevm-node test/vectors/src/merkle-tree/smt-hash-bytecode.json)
+	for _, tc := range []struct {
+		code, expect string
+	}{
+		{"dead", "2549d1fb0dc984e3098f235473637bd9e40aab1692c87e0afaf58720d2fbb8cd"},
+		{"123456789abcde123456789abcde123456789abcde123456789abcde123456789abcde123456789abcde123456789abcde123456789abcdeff", "b26e257fb87ad0976c69af4af03c9ee20449d18b0be000aa749b5b342a445308"},
+		{"8231e0e8e502600b14bb0a2c9689f7d93d10e9f5451f18f0a9b6f123", "31cd3428959051f652c12f729473d52c0956368643ff086514f983595c034067"},
+		{"ce0e8e502600b14bb0a2c9689f7d93d10e9f5451f18f030ec3bb6c5001", "a29092cb3f80b471d45d2e1bcca7fdcdb1083370e5952b56166cf03e73f24d31"},
+		{"34665289b71a2cb8bf4c289ae6d17d845457c48bfc18623ca39e141b2e40c5d3", "26aa5d09e2046f5ab7e311b32c6e34fa52a6dc8257a34b494af84fe1471c589c"},
+	} {
+		code, err := hex.DecodeString(tc.code)
+		require.NoError(t, err)
+		require.Equal(t, tc.expect, hex.EncodeToString(smtHashToBytes(PoseidonBytecodeHash(code, Poseidon))), tc.code)
+	}
+}
+
+func Test_SparseMerkleTrie_LeafKey(t *testing.T) {
+	// known answers of zkEVM (zkevm-node test/vectors/src/merkle-tree/smt-key-*.json)
+	zeroAddr := make([]byte, length.Addr)
+	ffAddr := bytes.Repeat([]byte{0xff}, length.Addr)
+	maxSlot := bytes.Repeat([]byte{0xff}, length.Hash)
+	for _, tc := range []struct {
+		addr   []byte
+		typ    uint64
+		slot   []byte
+		expect string
+	}{
+		{zeroAddr, smtKeyBalance, nil, "26833593870529421166492422877314944811724038685477806060577465163426988022737"},
+		{ffAddr, smtKeyBalance, nil, "40127382331240911157907914324528741813743915534659976972051985426947551260673"},
+		{zeroAddr, smtKeyNonce, nil, "28358077366816831193326378002625509892290580640052650926129802772493521696670"},
+		{ffAddr, smtKeyNonce, nil, "46601686036392058419641250406383581470536953255400137235565480209972996937304"},
+		{zeroAddr, smtKeyCode, nil, "72618736525103033809705966741823173469010530487114812728907809351129229387686"},
+		{ffAddr, smtKeyCode, nil, "100339618010685329502920959863456851722741867804653471565599858216996781583185"},
+		{zeroAddr, smtKeyStorage, make([]byte, length.Hash), "12534214928306848758475099215268104288950840610411881349004256209866079801855"},
+		{ffAddr, smtKeyStorage, maxSlot, "33137250487625679402353497751179166536828572303855484396343252601253270796565"},
+		{zeroAddr, smtKeyCodeLength, nil, "41007279171909826356801898715236946089777777871690100429699594563988270638848"},
+		{ffAddr, smtKeyCodeLength, nil, "34646114882128150922895390038184820825657559006046553149154512997547296886401"},
+	} {
+		trie := NewSparseMerkleTrie(length.Addr, nil, nil, nil)
+		key := trie.leafKey(tc.addr, tc.typ, tc.slot)
+		require.Equal(t, tc.expect, new(big.Int).SetBytes(smtHashToBytes(key)).String(), "%x %d %x", tc.addr, tc.typ, tc.slot)
+	}
+}
@@ -0,0 +1,268 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commitment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common"
+)
+
+// Witness - branch nodes and leaves (accounts and storage items) read by the trie through branchFn/accountFn/storageFn
+// during commitment evaluation. Trie rebuilt from witness reads the same data, so it evaluates the same updates
+// to the same root hash and branch updates without access to the full state.
+// Absent branches and leaves are recorded too: they are part of the proof that keys were not in the state.
+//
+// Encoding:
+//   - 1 byte: version
+//   - uvarint: amount of branches, then for each branch sorted by prefix:
+//     uvarint len + compacted prefix, uvarint len + branch data (empty for absent branch)
+//   - uvarint: amount of leaves, then for each leaf sorted by plain key:
+//     uvarint len + plain key, Update encoding (DeleteUpdate flag for absent leaf)
+type Witness struct {
+	mu       sync.Mutex // trie in parallel mode reads from multiple goroutines
+	branches map[string][]byte
+	leaves   map[string]Update
+}
+
+const witnessVersion = 1
+
+func NewWitness() *Witness {
+	return &Witness{branches: map[string][]byte{}, leaves: map[string]Update{}}
+}
+
+func (w *Witness) BranchesCount() int { return len(w.branches) }
+func (w *Witness) LeavesCount() int   { return len(w.leaves) }
+
+func (w *Witness) addBranch(prefix, branchData []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.branches[string(prefix)]; !ok {
+		w.branches[string(prefix)] = common.Copy(branchData)
+	}
+}
+
+// addLeaf records account or storage item read into the cell, first read wins
+func (w *Witness) addLeaf(plainKey []byte, cell *Cell, account bool) {
+	var u Update
+	switch {
+	case cell.Delete:
+		u.Flags = DeleteUpdate
+	case account:
+		if !cell.Balance.IsZero() {
+			u.Flags |= BalanceUpdate
+			u.Balance.Set(&cell.Balance)
+		}
+		if cell.Nonce != 0 {
+			u.Flags |= NonceUpdate
+			u.Nonce = cell.Nonce
+		}
+		if !bytes.Equal(cell.CodeHash[:], EmptyCodeHash) {
+			u.Flags |= CodeUpdate
+			copy(u.CodeHashOrStorage[:], cell.CodeHash[:])
+		}
+	default:
+		u.Flags = StorageUpdate
+		u.ValLength = cell.StorageLen
+		copy(u.CodeHashOrStorage[:], cell.Storage[:cell.StorageLen])
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.leaves[string(plainKey)]; !ok {
+		w.leaves[string(plainKey)] = u
+	}
+}
+
+// BranchFn reads branch from witness, it can be used as branchFn of the trie
+func (w *Witness) BranchFn(prefix []byte) ([]byte, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	branchData, ok := w.branches[string(prefix)]
+	if !ok {
+		return nil, fmt.Errorf("branch [%x] is not in witness", prefix)
+	}
+	if len(branchData) == 0 {
+		return nil, nil
+	}
+	return branchData, nil
+}
+
+// AccountFn reads account from witness, it can be used as accountFn of the trie
+func (w *Witness) AccountFn(plainKey []byte, cell *Cell) error {
+	u, err := w.leaf(plainKey)
+	if err != nil {
+		return err
+	}
+	cell.Nonce = u.Nonce
+	cell.Balance.Set(&u.Balance)
+	if u.Flags&CodeUpdate != 0 {
+		copy(cell.CodeHash[:], u.CodeHashOrStorage[:])
+	} else {
+		copy(cell.CodeHash[:], EmptyCodeHash)
+	}
+	cell.Delete = u.Flags&DeleteUpdate != 0
+	return nil
+}
+
+// StorageFn reads storage item from witness, it can be used as storageFn of the trie
+func (w *Witness) StorageFn(plainKey []byte, cell *Cell) error {
+	u, err := w.leaf(plainKey)
+	if err != nil {
+		return err
+	}
+	cell.StorageLen = u.ValLength
+	copy(cell.Storage[:], u.CodeHashOrStorage[:u.ValLength])
+	cell.Delete = u.Flags&DeleteUpdate != 0
+	return nil
+}
+
+func (w *Witness) leaf(plainKey []byte) (Update, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	u, ok := w.leaves[string(plainKey)]
+	if !ok {
+		return u, fmt.Errorf("leaf [%x] is not in witness", plainKey)
+	}
+	return u, nil
+}
+
+// Trie rebuilds partial trie from witness. Reading branch or leaf which is not in witness fails.
+func (w *Witness) Trie(accountKeyLen int) *HexPatriciaHashed {
+	return NewHexPatriciaHashed(accountKeyLen, w.BranchFn, w.AccountFn, w.StorageFn)
+}
+
+// RootHash recomputes root hash of the state witness was collected from, using root branch and it's leaves
+func (w *Witness) RootHash(accountKeyLen int) ([]byte, error) {
+	hph := w.Trie(accountKeyLen)
+	var cells [16]Cell
+	bitmap, err := hph.proofBranchCells(nil, &cells)
+	if err != nil {
+		return nil, err
+	}
+	if bitmap == 0 {
+		return common.Copy(EmptyRootHash), nil
+	}
+	node, err := hph.proofBranchNode(bitmap, &cells, 1)
+	if err != nil {
+		return nil, err
+	}
+	return hph.proofHash(node), nil
+}
+
+func (w *Witness) Encode(buf []byte) []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var numBuf [binary.MaxVarintLen64]byte
+	putBytes := func(b []byte) {
+		n := binary.PutUvarint(numBuf[:], uint64(len(b)))
+		buf = append(append(buf, numBuf[:n]...), b...)
+	}
+
+	buf = append(buf, witnessVersion)
+	n := binary.PutUvarint(numBuf[:], uint64(len(w.branches)))
+	buf = append(buf, numBuf[:n]...)
+	prefixes := make([]string, 0, len(w.branches))
+	for prefix := range w.branches {
+		prefixes = append(prefixes, prefix)
+	}
+	slices.Sort(prefixes)
+	for _, prefix := range prefixes {
+		putBytes([]byte(prefix))
+		putBytes(w.branches[prefix])
+	}
+
+	n = binary.PutUvarint(numBuf[:], uint64(len(w.leaves)))
+	buf = append(buf, numBuf[:n]...)
+	keys := make([]string, 0, len(w.leaves))
+	for key := range w.leaves {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		putBytes([]byte(key))
+		u := w.leaves[key]
+		buf = u.Encode(buf, numBuf[:])
+	}
+	return buf
+}
+
+func (w *Witness) Decode(buf []byte) error {
+	if len(buf) == 0 || buf[0] != witnessVersion {
+		return fmt.Errorf("decode witness: unsupported version")
+	}
+	pos := 1
+	getUvarint := func(what string) (uint64, error) {
+		v, n := binary.Uvarint(buf[pos:])
+		if n <= 0 {
+			return 0, fmt.Errorf("decode witness: %s", what)
+		}
+		pos += n
+		return v, nil
+	}
+	getBytes := func(what string) ([]byte, error) {
+		l, err := getUvarint(what + " len")
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(buf)-pos) < l {
+			return nil, fmt.Errorf("decode witness: buffer too small for %s", what)
+		}
+		b := common.Copy(buf[pos : pos+int(l)])
+		pos += int(l)
+		return b, nil
+	}
+
+	branchesCount, err := getUvarint("branches count")
+	if err != nil {
+		return err
+	}
+	w.branches = make(map[string][]byte, branchesCount)
+	for i := uint64(0); i < branchesCount; i++ {
+		prefix, err := getBytes("branch prefix")
+		if err != nil {
+			return err
+		}
+		if w.branches[string(prefix)], err = getBytes("branch data"); err != nil {
+			return err
+		}
+	}
+	leavesCount, err := getUvarint("leaves count")
+	if err != nil {
+		return err
+	}
+	w.leaves = make(map[string]Update, leavesCount)
+	for i := uint64(0); i < leavesCount; i++ {
+		key, err := getBytes("leaf key")
+		if err != nil {
+			return err
+		}
+		var u Update
+		if pos, err = u.Decode(buf, pos); err != nil {
+			return fmt.Errorf("decode witness leaf [%x]: %w", key, err)
+		}
+		w.leaves[string(key)] = u
+	}
+	if pos != len(buf) {
+		return fmt.Errorf("decode witness: %d trailing bytes", len(buf)-pos)
+	}
+	return nil
+}
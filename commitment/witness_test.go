@@ -0,0 +1,147 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commitment
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HexPatriciaHashed_Witness(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		t.Run(fmt.Sprintf("parallel=%t", parallel), func(t *testing.T) {
+			ms := NewMockState(t)
+			hph := NewHexPatriciaHashed(1, ms.branchFn, ms.accountFn, ms.storageFn)
+			hph.SetParallel(parallel)
+
+			builder := NewUpdateBuilder()
+			for i := 0; i < 200; i++ {
+				addr := fmt.Sprintf("%02x", i)
+				builder.Balance(addr, uint64(i+1))
+				if i%5 == 0 {
+					builder.Storage(addr, "01", fmt.Sprintf("%04x", i+1)).Storage(addr, "02", "ff")
+				}
+			}
+			plainKeys, hashedKeys, updates := builder.Build()
+			require.NoError(t, ms.applyPlainUpdates(plainKeys, updates))
+			preRoot, branchNodeUpdates, err := hph.ReviewKeys(plainKeys, hashedKeys)
+			require.NoError(t, err)
+			ms.applyBranchNodeUpdates(branchNodeUpdates)
+
+			// block touching few keys, state is not updated yet, so trie reads pre-state
+			plainKeys, hashedKeys, updates = NewUpdateBuilder().
+				Balance("05", 1000).
+				Nonce("0a", 3).
+				Storage("0a", "01", "0b0b").
+				Balance("fa", 7). // new account
+				Delete("14").
+				Build()
+
+			witness := NewWitness()
+			hph.Reset()
+			hph.SetWitness(witness)
+			postRoot, branchNodeUpdates, err := hph.ProcessUpdates(plainKeys, hashedKeys, updates)
+			require.NoError(t, err)
+			hph.SetWitness(nil)
+			require.NotEqual(t, preRoot, postRoot)
+			require.Less(t, witness.BranchesCount(), len(ms.cm))
+
+			encoded := witness.Encode(nil)
+			decoded := NewWitness()
+			require.NoError(t, decoded.Decode(encoded))
+			require.Equal(t, encoded, decoded.Encode(nil))
+
+			root, err := decoded.RootHash(1)
+			require.NoError(t, err)
+			require.Equal(t, preRoot, root)
+
+			stateless := decoded.Trie(1)
+			stateless.SetParallel(parallel)
+			statelessRoot, statelessUpdates, err := stateless.ProcessUpdates(plainKeys, hashedKeys, updates)
+			require.NoError(t, err)
+			require.Equal(t, postRoot, statelessRoot)
+			require.Equal(t, branchNodeUpdates, statelessUpdates)
+
+			// witness doesn't know about keys outside of the block
+			plainKeys, hashedKeys, updates = NewUpdateBuilder().Balance("c3", 1).Storage("c8", "03", "01").Build()
+			stateless.Reset()
+			_, _, err = stateless.ProcessUpdates(plainKeys, hashedKeys, updates)
+			require.Error(t, err)
+
+			require.Error(t, decoded.Decode(encoded[:len(encoded)-1]))
+		})
+	}
+}
+
+func Test_Trie_Witness(t *testing.T) {
+	for _, variant := range []TrieVariant{VariantBinPatriciaTrie, VariantSparseMerkleTrie} {
+		variant := variant
+		t.Run(string(variant), func(t *testing.T) {
+			newTrie := func(branchFn func([]byte) ([]byte, error), accountFn, storageFn func([]byte, *Cell) error) Trie {
+				if variant == VariantBinPatriciaTrie {
+					return NewBinPatriciaHashed(1, branchFn, accountFn, storageFn)
+				}
+				return NewSparseMerkleTrie(1, branchFn, accountFn, storageFn)
+			}
+			ms := NewMockState(t)
+			trie := newTrie(ms.branchFn, ms.accountFn, ms.storageFn)
+
+			plainKeys, hashedKeys, updates := NewUpdateBuilder().
+				Balance("00", 4).
+				Balance("01", 5).
+				Balance("02", 6).
+				Balance("03", 7).
+				Balance("04", 8).
+				Storage("04", "01", "0401").
+				Storage("03", "56", "050505").
+				Storage("03", "57", "060606").
+				Balance("05", 9).
+				Storage("05", "02", "8989").
+				Storage("05", "04", "9898").
+				Build()
+			require.NoError(t, ms.applyPlainUpdates(plainKeys, updates))
+			_, branchNodeUpdates, err := trie.ReviewKeys(plainKeys, hashedKeys)
+			require.NoError(t, err)
+			ms.applyBranchNodeUpdates(branchNodeUpdates)
+
+			plainKeys, hashedKeys, updates = NewUpdateBuilder().
+				Storage("03", "58", "050505").
+				Balance("05", 1000).
+				Build()
+			require.NoError(t, ms.applyPlainUpdates(plainKeys, updates))
+
+			witness := NewWitness()
+			trie.Reset()
+			trie.SetWitness(witness)
+			postRoot, branchNodeUpdates, err := trie.ReviewKeys(plainKeys, hashedKeys)
+			require.NoError(t, err)
+			trie.SetWitness(nil)
+			require.NotZero(t, witness.BranchesCount())
+			require.GreaterOrEqual(t, witness.LeavesCount(), len(plainKeys))
+
+			decoded := NewWitness()
+			require.NoError(t, decoded.Decode(witness.Encode(nil)))
+			stateless := newTrie(decoded.BranchFn, decoded.AccountFn, decoded.StorageFn)
+			statelessRoot, statelessUpdates, err := stateless.ReviewKeys(plainKeys, hashedKeys)
+			require.NoError(t, err)
+			require.Equal(t, postRoot, statelessRoot)
+			require.Equal(t, branchNodeUpdates, statelessUpdates)
+		})
+	}
+}
@@ -0,0 +1,268 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"context"
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common"
+	"github.com/gateway-fm/cdk-erigon-lib/common/hexutility"
+	"golang.org/x/exp/slices"
+)
+
+// FileStats describes how the words of compressed file are encoded, see Analyze. Words of tail segment (if any)
+// are included into words counts, sizes and usage of patterns and positions
+type FileStats struct {
+	File  string `json:"file"`
+	Codec string `json:"codec"`
+	Size  int64  `json:"size"`
+
+	TailWords uint64 `json:"tailWords,omitempty"`
+	TailSize  int64  `json:"tailSize,omitempty"`
+
+	Words             uint64  `json:"words"`
+	EmptyWords        uint64  `json:"emptyWords"`
+	UncompressedWords uint64  `json:"uncompressedWords"` // non-empty words stored as is: without patterns (or zstd)
+	UncompressedRatio float64 `json:"uncompressedRatio"` // UncompressedWords / non-empty words
+
+	HeaderSize       uint64  `json:"headerSize"` // header with patterns and positions sections
+	WordsDataSize    uint64  `json:"wordsDataSize"`
+	WordsSize        uint64  `json:"wordsSize"` // total size of decompressed words
+	BytesPerWord     float64 `json:"bytesPerWord"`
+	DataBytesPerWord float64 `json:"dataBytesPerWord"` // size of word in the file
+	PatternBytes     uint64  `json:"patternBytes"`     // bytes of words covered by patterns
+	LiteralBytes     uint64  `json:"literalBytes"`     // bytes of words not covered by patterns
+
+	SharedDictionary bool           `json:"sharedDictionary"`
+	DictionaryID     uint64         `json:"dictionaryId,omitempty"`
+	PatternsCount    int            `json:"patternsCount"`
+	UnusedPatterns   int            `json:"unusedPatterns"`
+	Patterns         []PatternStat  `json:"patterns"` // sorted by uses, most used first
+	Positions        []PositionStat `json:"positions"`
+	WordLenHistogram []LenBucket    `json:"wordLenHistogram"`
+	DataLenHistogram []LenBucket    `json:"dataLenHistogram"` // sizes of words in the file
+}
+
+type PatternStat struct {
+	Pattern hexutility.Bytes `json:"pattern"`
+	Depth   uint64           `json:"depth"` // length of huffman code
+	Uses    uint64           `json:"uses"`
+}
+
+// PositionStat - usage of position code. Positions are word length + 1, distance between patterns + 1 and 0 terminator
+type PositionStat struct {
+	Pos   uint64 `json:"pos"`
+	Depth uint64 `json:"depth"`
+	Uses  uint64 `json:"uses"`
+}
+
+// LenBucket counts lengths in range (previous bucket's MaxLen, MaxLen], buckets are powers of 2
+type LenBucket struct {
+	MaxLen uint64 `json:"maxLen"`
+	Count  uint64 `json:"count"`
+}
+
+type lenHistogram [65]uint64
+
+func (h *lenHistogram) add(l uint64) {
+	if l == 0 {
+		h[0]++
+		return
+	}
+	h[bits.Len64(l-1)+1]++
+}
+
+func (h *lenHistogram) buckets() []LenBucket {
+	var res []LenBucket
+	for i, c := range h {
+		if c == 0 {
+			continue
+		}
+		var maxLen uint64
+		if i > 0 {
+			maxLen = uint64(1) << (i - 1)
+		}
+		res = append(res, LenBucket{MaxLen: maxLen, Count: c})
+	}
+	return res
+}
+
+// Analyze scans all words of the file and collects its FileStats, which don't reference memory of d. Only maxPatterns most used patterns are listed,
+// all patterns if maxPatterns < 0
+func Analyze(ctx context.Context, d *Decompressor, maxPatterns int) (*FileStats, error) {
+	st := &FileStats{
+		File:          d.fileName,
+		Codec:         d.codec.String(),
+		Size:          d.size,
+		Words:         d.wordsCount,
+		EmptyWords:    d.emptyWordsCount,
+		HeaderSize:    d.wordsStart,
+		WordsDataSize: uint64(len(d.data)) - d.wordsStart,
+	}
+	st.DictionaryID, st.SharedDictionary = d.DictionaryID()
+
+	var depths, posDepths, poss []uint64
+	var patterns [][]byte
+	if d.codec == CodecPattern {
+		var err error
+		pos := uint64(24)
+		if d.sharedDict != nil {
+			depths, patterns = d.sharedDict.depths, d.sharedDict.patterns
+		} else {
+			patternsSize := binary.BigEndian.Uint64(d.data[16:24]) & headerValueMask
+			if depths, patterns, _, err = readPatterns(d.data[pos : pos+patternsSize]); err != nil {
+				return nil, err
+			}
+			pos += patternsSize
+		}
+		posSize := binary.BigEndian.Uint64(d.data[pos : pos+8])
+		if posDepths, poss, _, err = readPositions(d.data[pos+8 : pos+8+posSize]); err != nil {
+			return nil, err
+		}
+	}
+	// getter returns patterns pointing to the same memory, so they are identified by the first byte
+	patternIdx := make(map[*byte]int, len(patterns))
+	for i, p := range patterns {
+		if len(p) > 0 {
+			patternIdx[&p[0]] = i
+		}
+	}
+	patternUses := make([]uint64, len(patterns))
+	posUses := map[uint64]uint64{}
+
+	var wordLens, dataLens lenHistogram
+	var word []byte
+	// tail segment is encoded with patterns of the file, but has own codec (CodecRaw if the file has no patterns)
+	scan := func(seg *Decompressor) error {
+		g := seg.makeGetter()
+		for i := 0; g.HasNext(); i++ {
+			if i%100_000 == 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				default:
+				}
+			}
+			start := g.dataP
+			var wordLen uint64
+			var uncompressed bool
+			if seg.codec == CodecPattern {
+				wordLen, uncompressed = g.analyzeWord(patternIdx, patternUses, posUses, st)
+			} else {
+				stored, compressed := g.nextWord()
+				word = g.decodeWord(stored, compressed, word[:0])
+				wordLen, uncompressed = uint64(len(word)), !compressed
+				if uncompressed {
+					st.LiteralBytes += wordLen
+				}
+			}
+			if wordLen > 0 && uncompressed {
+				st.UncompressedWords++
+			}
+			st.WordsSize += wordLen
+			wordLens.add(wordLen)
+			dataLens.add(g.dataP - start)
+		}
+		return nil
+	}
+	if err := scan(d); err != nil {
+		return nil, err
+	}
+	if d.tail != nil {
+		st.TailWords, st.TailSize = d.tail.wordsCount, d.tail.size
+		st.Words += d.tail.wordsCount
+		st.EmptyWords += d.tail.emptyWordsCount
+		st.WordsDataSize += uint64(len(d.tail.data)) - d.tail.wordsStart
+		if err := scan(d.tail); err != nil {
+			return nil, err
+		}
+	}
+
+	if nonEmpty := st.Words - st.EmptyWords; nonEmpty > 0 {
+		st.UncompressedRatio = float64(st.UncompressedWords) / float64(nonEmpty)
+	}
+	if st.Words > 0 {
+		st.BytesPerWord = float64(st.WordsSize) / float64(st.Words)
+		st.DataBytesPerWord = float64(st.WordsDataSize) / float64(st.Words)
+	}
+	st.WordLenHistogram, st.DataLenHistogram = wordLens.buckets(), dataLens.buckets()
+
+	st.PatternsCount = len(patterns)
+	for i, p := range patterns {
+		if patternUses[i] == 0 {
+			st.UnusedPatterns++
+		}
+		st.Patterns = append(st.Patterns, PatternStat{Pattern: p, Depth: depths[i], Uses: patternUses[i]})
+	}
+	slices.SortStableFunc(st.Patterns, func(a, b PatternStat) bool { return a.Uses > b.Uses })
+	if maxPatterns >= 0 && len(st.Patterns) > maxPatterns {
+		st.Patterns = st.Patterns[:maxPatterns]
+	}
+	for i := range st.Patterns { // patterns point to mmap of file
+		st.Patterns[i].Pattern = common.Copy(st.Patterns[i].Pattern)
+	}
+
+	for i, pos := range poss {
+		st.Positions = append(st.Positions, PositionStat{Pos: pos, Depth: posDepths[i], Uses: posUses[pos]})
+	}
+	slices.SortStableFunc(st.Positions, func(a, b PositionStat) bool { return a.Uses > b.Uses })
+	return st, nil
+}
+
+// analyzeWord moves to the next word the same way as Skip, counting usage of patterns and positions
+func (g *Getter) analyzeWord(patternIdx map[*byte]int, patternUses []uint64, posUses map[uint64]uint64, st *FileStats) (wordLen uint64, uncompressed bool) {
+	l := g.nextPos(true)
+	posUses[l]++
+	l-- // because when create huffman tree we do ++ , because 0 is terminator
+	if l == 0 {
+		if g.dataBit > 0 {
+			g.dataP++
+			g.dataBit = 0
+		}
+		return 0, true
+	}
+
+	var add uint64
+	var bufPos, lastUncovered, patternsCount int
+	for pos := g.nextPos(false); pos != 0; pos = g.nextPos(false) {
+		posUses[pos]++
+		bufPos += int(pos) - 1
+		if bufPos > lastUncovered {
+			add += uint64(bufPos - lastUncovered)
+		}
+		pattern := g.nextPattern()
+		if len(pattern) > 0 {
+			patternUses[patternIdx[&pattern[0]]]++
+		}
+		patternsCount++
+		lastUncovered = bufPos + len(pattern)
+	}
+	posUses[0]++
+	if g.dataBit > 0 {
+		g.dataP++
+		g.dataBit = 0
+	}
+	if int(l) > lastUncovered {
+		add += l - uint64(lastUncovered)
+	}
+	st.LiteralBytes += add
+	st.PatternBytes += l - add
+	g.dataP += add
+	return l, patternsCount == 0
+}
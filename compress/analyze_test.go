@@ -0,0 +1,133 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	words := codecWords(5000)
+	var wordsSize uint64
+	for _, w := range words {
+		wordsSize += uint64(len(w))
+	}
+	for _, codec := range []Codec{CodecPattern, CodecZstd, CodecRaw} {
+		codec := codec
+		t.Run(codec.String(), func(t *testing.T) {
+			d := prepareCodecFile(t, filepath.Join(t.TempDir(), "compressed"), codec, words)
+			defer d.Close()
+			st, err := Analyze(context.Background(), d, 10)
+			require.NoError(t, err)
+
+			require.Equal(t, codec.String(), st.Codec)
+			require.Equal(t, uint64(len(words)), st.Words)
+			require.Equal(t, uint64(len(words)/4), st.EmptyWords)
+			require.Equal(t, wordsSize, st.WordsSize)
+			require.Equal(t, uint64(d.size), st.HeaderSize+st.WordsDataSize)
+			require.InDelta(t, float64(wordsSize)/float64(len(words)), st.BytesPerWord, 1e-9)
+			var histWords, histData uint64
+			for _, b := range st.WordLenHistogram {
+				histWords += b.Count
+			}
+			for _, b := range st.DataLenHistogram {
+				histData += b.Count
+			}
+			require.Equal(t, st.Words, histWords)
+			require.Equal(t, st.Words, histData)
+			require.Equal(t, uint64(len(words)/4), st.WordLenHistogram[0].Count)
+
+			switch codec {
+			case CodecPattern:
+				require.NotZero(t, st.PatternsCount)
+				require.Len(t, st.Patterns, 10)
+				require.NotZero(t, st.PatternBytes)
+				for i := 1; i < len(st.Patterns); i++ {
+					require.GreaterOrEqual(t, st.Patterns[i-1].Uses, st.Patterns[i].Uses)
+				}
+				require.NotEmpty(t, st.Positions)
+				// every non-empty word uses terminator position
+				var terminators uint64
+				for _, p := range st.Positions {
+					if p.Pos == 0 {
+						terminators = p.Uses
+					}
+				}
+				require.Equal(t, st.Words-st.EmptyWords, terminators)
+				// words added by AddUncompressedWord don't use patterns
+				require.Greater(t, st.UncompressedWords, uint64(0))
+				require.Equal(t, wordsSize, st.PatternBytes+st.LiteralBytes)
+			case CodecZstd:
+				require.Less(t, st.UncompressedWords, st.Words-st.EmptyWords)
+				require.Empty(t, st.Patterns)
+				require.Less(t, st.LiteralBytes, wordsSize)
+			case CodecRaw:
+				require.Equal(t, st.Words-st.EmptyWords, st.UncompressedWords)
+				require.Equal(t, 1.0, st.UncompressedRatio)
+				require.Zero(t, st.PatternBytes)
+				require.Equal(t, wordsSize, st.LiteralBytes)
+			}
+
+			all, err := Analyze(context.Background(), d, -1)
+			require.NoError(t, err)
+			require.Len(t, all.Patterns, all.PatternsCount)
+			_, err = json.Marshal(all)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAnalyzeTail(t *testing.T) {
+	words := codecWords(5000)
+	var wordsSize uint64
+	for _, w := range words {
+		wordsSize += uint64(len(w))
+	}
+	for _, codec := range []Codec{CodecPattern, CodecZstd, CodecRaw} {
+		codec := codec
+		t.Run(codec.String(), func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "compressed")
+			prepareCodecFile(t, file, codec, words[:4200]).Close()
+			appendWords(t, file, words[4200:], 4200)
+			d, err := NewDecompressor(file)
+			require.NoError(t, err)
+			defer d.Close()
+			require.True(t, d.HasTail())
+
+			st, err := Analyze(context.Background(), d, -1)
+			require.NoError(t, err)
+			require.Equal(t, uint64(d.Count()), st.Words)
+			require.Equal(t, uint64(800), st.TailWords)
+			require.Equal(t, d.tail.size, st.TailSize)
+			require.Equal(t, uint64(len(words)/4), st.EmptyWords)
+			require.Equal(t, wordsSize, st.WordsSize)
+			if codec != CodecZstd {
+				require.Equal(t, wordsSize, st.PatternBytes+st.LiteralBytes)
+			}
+			var histWords uint64
+			for _, b := range st.WordLenHistogram {
+				histWords += b.Count
+			}
+			require.Equal(t, st.Words, histWords)
+		})
+	}
+}
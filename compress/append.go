@@ -0,0 +1,269 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common"
+	"github.com/gateway-fm/cdk-erigon-lib/etl"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// Tail segment allows to add words to the compressed file without compressing it again: new words are written
+// into separate file next to it, encoded with the dictionary of the file. Decompressor opens tail together with
+// the file and Getter iterates over words of the file and then over words of the tail - offsets of tail words
+// continue offsets of the file. Every append rewrites the tail (existing words of the tail and new ones),
+// so it's expected to stay small: CompactTail folds it into the file.
+// Tail segment has format:
+//   - words count of the file (8 bytes)
+//   - size of words data of the file (8 bytes)
+//   - compressed file: CodecPattern file's tail refers to the patterns of the file as to shared dictionary
+//     (see Dictionary) and has own positions dictionary, tail of the file without patterns is CodecRaw.
+//     Tail of CodecZstd file is compressed with zstd dictionary of the file, which is copied into the tail.
+
+// TailFileExt - extension appended to the compressed file name to get path of it's tail segment
+const TailFileExt = ".tail"
+
+const tailHeaderSize = 16
+
+func TailPath(compressedFilePath string) string {
+	return compressedFilePath + TailFileExt
+}
+
+// tailBinding - words count and size of words data of the file, which tail segment is written for
+type tailBinding struct {
+	words uint64
+	size  uint64
+}
+
+// write prefixes compressed segment with the header
+func (b *tailBinding) write(tailPath, segmentPath string) error {
+	seg, err := os.Open(segmentPath)
+	if err != nil {
+		return err
+	}
+	defer seg.Close()
+	f, err := os.Create(tailPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriterSize(f, etl.BufIOSize)
+	var header [tailHeaderSize]byte
+	binary.BigEndian.PutUint64(header[:8], b.words)
+	binary.BigEndian.PutUint64(header[8:], b.size)
+	if _, err = w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, seg); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// NewAppendCompressor creates compressor, which Compress writes words added to it into the tail segment of existing
+// compressed file. Words already in the tail are kept before them. Shared dictionary of the file (if any) is resolved
+// through DefaultDictionaries. Sidecars (word offsets, sparse key index) aren't built for the tail
+func NewAppendCompressor(ctx context.Context, logPrefix, compressedFilePath, tmpDir string, workers int, lvl log.Lvl) (*Compressor, error) {
+	d, err := NewDecompressor(compressedFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+	dict, err := d.tailDictionary()
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewCompressor(ctx, logPrefix, TailPath(compressedFilePath), tmpDir, 0, workers, lvl)
+	if err != nil {
+		return nil, err
+	}
+	c.tail = &tailBinding{words: d.wordsCount, size: d.tailBase()}
+	c.dict, c.codec = dict, d.tailCodec()
+	if d.codec == CodecZstd {
+		c.zstdDict = common.Copy(d.data[24 : d.wordsStart-8])
+	}
+	if d.tail != nil {
+		if err = copyWords(ctx, d.tail.makeGetter(), c); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// CompactTail folds tail segment into the file: all words are compressed again (with new dictionary, unless the file
+// uses shared one), sidecars of the file are rebuilt and the tail is removed. Does nothing if the file has no tail
+func CompactTail(ctx context.Context, logPrefix, compressedFilePath, tmpDir string, minPatternScore uint64, workers int, lvl log.Lvl) error {
+	d, err := NewDecompressor(compressedFilePath)
+	if err != nil {
+		return err
+	}
+	if d.tail == nil {
+		return d.Close()
+	}
+	c, err := NewCompressor(ctx, logPrefix, compressedFilePath, tmpDir, minPatternScore, workers, lvl)
+	if err != nil {
+		d.Close()
+		return err
+	}
+	defer c.Close()
+	c.codec, c.dict = d.codec, d.sharedDict
+	c.wordOffsets = d.wordOffsets != nil
+	if d.keyIndex != nil {
+		c.keyIndexStep = d.keyIndex.step
+	}
+	err = copyWords(ctx, d.MakeGetter(), c)
+	d.Close() // the file is replaced by Compress
+	if err != nil {
+		return err
+	}
+	return c.Compress()
+}
+
+// copyWords adds all words of the getter to the compressor. Words which were stored without compression (no patterns
+// found or zstd didn't help) are added as uncompressed: they are decoded the same way by Next and NextUncompressed,
+// so it doesn't matter how they were added originally
+func copyWords(ctx context.Context, g *Getter, c *Compressor) error {
+	var word []byte
+	var err error
+	for i := 0; g.HasNext(); i++ {
+		if i%100_000 == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+		g.nextSegment()
+		compressed := g.wordCompressed()
+		word, _ = g.Next(word[:0])
+		if compressed {
+			err = c.AddWord(word)
+		} else {
+			err = c.AddUncompressedWord(word)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wordCompressed reports if the word at current offset has patterns (CodecPattern) or is zstd frame (CodecZstd)
+func (g *Getter) wordCompressed() bool {
+	savePos, saveBit := g.dataP, g.dataBit
+	defer func() { g.dataP, g.dataBit = savePos, saveBit }()
+	if g.codec != CodecPattern {
+		_, compressed := g.nextWord()
+		return compressed
+	}
+	if g.nextPos(true) == 1 { // empty word
+		return false
+	}
+	return g.nextPos(false) != 0
+}
+
+// openTail opens tail segment of the file if it exists
+func (d *Decompressor) openTail(dicts *DictionaryRegistry) error {
+	path := TailPath(d.filePath)
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	dict, err := d.tailDictionary()
+	if err != nil {
+		return fmt.Errorf("tail %s: %w", path, err)
+	}
+	if dict != nil {
+		dicts = NewDictionaryRegistry(dict)
+	}
+	tail, err := openDecompressor(path, dicts, false, tailHeaderSize)
+	if err != nil {
+		return fmt.Errorf("tail %s: %w", path, err)
+	}
+	words, size := binary.BigEndian.Uint64(tail.mmapHandle1[:8]), binary.BigEndian.Uint64(tail.mmapHandle1[8:16])
+	if words != d.wordsCount || size != d.tailBase() {
+		tail.Close()
+		return fmt.Errorf("tail %s: written for %d words of %d bytes, file has %d words of %d bytes", path, words, size, d.wordsCount, d.tailBase())
+	}
+	if tail.codec != d.tailCodec() {
+		tail.Close()
+		return fmt.Errorf("tail %s: codec %s, expected %s", path, tail.codec, d.tailCodec())
+	}
+	if tail.wordsCount == 0 {
+		return tail.Close()
+	}
+	if d.wordOffsets != nil {
+		if tail.wordOffsets, err = collectWordOffsets(context.Background(), tail); err != nil {
+			tail.Close()
+			return err
+		}
+	}
+	d.tail = tail
+	return nil
+}
+
+func (d *Decompressor) HasTail() bool { return d.tail != nil }
+
+// tailBase - offset of the first word of tail segment, it's equal to size of words data of the file
+func (d *Decompressor) tailBase() uint64 { return uint64(len(d.data)) - d.wordsStart }
+
+// tailCodec - codec of the file, except CodecPattern file without patterns - it's tail is CodecRaw
+func (d *Decompressor) tailCodec() Codec {
+	if d.codec == CodecPattern && d.dict == nil {
+		return CodecRaw
+	}
+	return d.codec
+}
+
+// tailDictionary returns dictionary which tail segment is encoded with: shared dictionary of the file or
+// dictionary of it's own patterns, nil if the file has no patterns
+func (d *Decompressor) tailDictionary() (*Dictionary, error) {
+	if d.codec != CodecPattern || d.dict == nil {
+		return nil, nil
+	}
+	if d.sharedDict != nil {
+		return d.sharedDict, nil
+	}
+	patternsSize := binary.BigEndian.Uint64(d.data[16:24]) & headerValueMask
+	depths, patterns, _, err := readPatterns(d.data[24 : 24+patternsSize])
+	if err != nil {
+		return nil, err
+	}
+	dict, err := NewDictionary(depths, patterns)
+	if err != nil {
+		return nil, err
+	}
+	dict.tableOnce.Do(func() { dict.table = d.dict }) // same patterns in the same order - decoding table is reused
+	return dict, nil
+}
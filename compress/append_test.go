@@ -0,0 +1,170 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func appendWords(t *testing.T, file string, words [][]byte, from int) {
+	t.Helper()
+	c, err := NewAppendCompressor(context.Background(), "append", file, filepath.Dir(file), 2, log.LvlDebug)
+	require.NoError(t, err)
+	defer c.Close()
+	for i, w := range words {
+		if (from+i)%7 == 6 {
+			require.NoError(t, c.AddUncompressedWord(w))
+		} else {
+			require.NoError(t, c.AddWord(w))
+		}
+	}
+	require.NoError(t, c.Compress())
+}
+
+func checkAppendedWords(t *testing.T, d *Decompressor, words [][]byte) {
+	t.Helper()
+	require.Equal(t, len(words), d.Count())
+	g := d.MakeGetter()
+	offsets := make([]uint64, 0, len(words))
+	var word []byte
+	for i := 0; g.HasNext(); i++ {
+		offsets = append(offsets, g.offset())
+		if i%7 == 6 {
+			uncompressed, _ := g.NextUncompressed()
+			require.Equal(t, string(words[i]), string(uncompressed), i)
+			continue
+		}
+		word, _ = g.Next(word[:0])
+		require.Equal(t, string(words[i]), string(word), i)
+	}
+	require.Equal(t, len(words), len(offsets))
+
+	perm := rand.New(rand.NewSource(0)).Perm(len(words))
+	if len(perm) > 200 {
+		perm = perm[:200]
+	}
+	for _, i := range perm {
+		if d.HasWordOffsets() {
+			offset, err := d.WordOffset(i)
+			require.NoError(t, err)
+			require.Equal(t, offsets[i], offset, i)
+		}
+		g.Reset(offsets[i])
+		if i%7 == 6 {
+			continue
+		}
+		require.True(t, g.MatchPrefix(words[i]))
+		ok, next := g.Match(words[i])
+		require.True(t, ok, i)
+		if i+1 < len(words) {
+			require.Equal(t, offsets[i+1], next)
+			g.Reset(offsets[i])
+			require.Equal(t, offsets[i+1], g.Skip())
+		}
+	}
+}
+
+func TestAppend(t *testing.T) {
+	words := codecWords(3000)
+	for _, codec := range []Codec{CodecPattern, CodecZstd, CodecRaw} {
+		codec := codec
+		t.Run(codec.String(), func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "compressed")
+			prepareCodecFile(t, file, codec, words[:2000]).Close()
+
+			appendWords(t, file, words[2000:2500], 2000)
+			appendWords(t, file, words[2500:], 2500)
+			d, err := NewDecompressor(file)
+			require.NoError(t, err)
+			require.True(t, d.HasTail())
+			checkAppendedWords(t, d, words)
+			var scanned int
+			require.NoError(t, d.ParallelScan(context.Background(), 3, true, func(i int, offset uint64, word []byte) error {
+				require.Equal(t, scanned, i)
+				if i%7 != 6 {
+					require.Equal(t, string(words[i]), string(word), i)
+				}
+				scanned++
+				return nil
+			}))
+			require.Equal(t, len(words), scanned)
+			d.Close()
+
+			require.NoError(t, CompactTail(context.Background(), "append", file, filepath.Dir(file), 1, 2, log.LvlDebug))
+			_, err = os.Stat(TailPath(file))
+			require.ErrorIs(t, err, os.ErrNotExist)
+			d, err = NewDecompressor(file)
+			require.NoError(t, err)
+			defer d.Close()
+			require.False(t, d.HasTail())
+			require.True(t, d.HasWordOffsets())
+			require.Equal(t, codec, d.Codec())
+			checkAppendedWords(t, d, words)
+		})
+	}
+}
+
+func TestAppendNoPatterns(t *testing.T) {
+	words := [][]byte{[]byte("a"), nil, []byte("bc"), []byte("def"), nil, []byte("g"), []byte("hi"), []byte("j")}
+	file := filepath.Join(t.TempDir(), "compressed")
+	prepareCodecFile(t, file, CodecPattern, words[:3]).Close()
+	appendWords(t, file, words[3:], 3)
+
+	d, err := NewDecompressor(file)
+	require.NoError(t, err)
+	defer d.Close()
+	require.Equal(t, CodecRaw, d.tail.Codec())
+	checkAppendedWords(t, d, words)
+}
+
+func TestAppendStaleTail(t *testing.T) {
+	words := codecWords(500)
+	dir := t.TempDir()
+	file, other := filepath.Join(dir, "compressed"), filepath.Join(dir, "other")
+	prepareCodecFile(t, file, CodecPattern, words[:400]).Close()
+	prepareCodecFile(t, other, CodecPattern, words[:300]).Close()
+	appendWords(t, file, words[400:], 400)
+
+	// tail of another file is ignored
+	tail, err := os.ReadFile(TailPath(file))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(TailPath(other), tail, 0644))
+	d, err := NewDecompressor(other)
+	require.NoError(t, err)
+	require.False(t, d.HasTail())
+	require.Equal(t, 300, d.Count())
+	d.Close()
+
+	// corrupt tail is ignored
+	require.NoError(t, os.WriteFile(TailPath(other), tail[:10], 0644))
+	d, err = NewDecompressor(other)
+	require.NoError(t, err)
+	require.False(t, d.HasTail())
+	d.Close()
+
+	// compression of the file removes it's tail
+	prepareCodecFile(t, file, CodecPattern, words[:400]).Close()
+	_, err = os.Stat(TailPath(file))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,81 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// analyze prints statistics of compressed files as JSON (one object per file):
+//
+//	analyze [-patterns N] [-dict file.dict]... file.seg...
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gateway-fm/cdk-erigon-lib/compress"
+)
+
+type dictFiles []string
+
+func (f *dictFiles) String() string     { return strings.Join(*f, ",") }
+func (f *dictFiles) Set(v string) error { *f = append(*f, v); return nil }
+
+func main() {
+	var dicts dictFiles
+	patterns := flag.Int("patterns", 100, "amount of most used patterns to print, -1 for all")
+	flag.Var(&dicts, "dict", "shared dictionary used by the files, can be repeated")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] file...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(context.Background(), flag.Args(), dicts, *patterns); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, files, dictFiles []string, patterns int) error {
+	registry := compress.NewDictionaryRegistry()
+	for _, f := range dictFiles {
+		if _, err := registry.RegisterFile(f); err != nil {
+			return err
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	for _, f := range files {
+		d, err := compress.NewDecompressorWithDictionaries(f, registry)
+		if err != nil {
+			return err
+		}
+		st, err := compress.Analyze(ctx, d, patterns)
+		d.Close()
+		if err != nil {
+			return fmt.Errorf("analyze %s: %w", f, err)
+		}
+		if err = enc.Encode(st); err != nil {
+			return err
+		}
+	}
+	return nil
+}
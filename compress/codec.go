@@ -0,0 +1,244 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	"github.com/gateway-fm/cdk-erigon-lib/etl"
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// Codec defines how words are encoded in the compressed file. It's stored in the high bits of the patterns size
+// field of the header (zero for files produced before codecs were introduced - CodecPattern):
+//   - bit 63 - shared dictionary flag (CodecPattern only)
+//   - bits 56..62 - codec
+//   - bits 0..55 - size of patterns section (or ID of shared dictionary)
+//
+// CodecZstd and CodecRaw files keep the layout of CodecPattern files: patterns section holds zstd dictionary
+// (empty for CodecRaw) and positions section is empty. Each word is written as uvarint(len<<1 | zstdFlag) and
+// len bytes - zstd frame without magic number or the word itself. Empty word is single zero byte.
+type Codec uint8
+
+const (
+	CodecPattern Codec = iota // dictionary of patterns, huffman coding of patterns and positions
+	CodecZstd                 // every word is compressed by zstd with dictionary trained on sample of words
+	CodecRaw                  // words are stored as is
+)
+
+func (c Codec) String() string {
+	switch c {
+	case CodecPattern:
+		return "pattern"
+	case CodecZstd:
+		return "zstd"
+	case CodecRaw:
+		return "raw"
+	default:
+		return fmt.Sprintf("codec(%d)", uint8(c))
+	}
+}
+
+func ParseCodec(s string) (Codec, error) {
+	for _, c := range []Codec{CodecPattern, CodecZstd, CodecRaw} {
+		if c.String() == s {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown codec: %s", s)
+}
+
+const (
+	codecShift      = 56
+	codecMask       = 0x7f
+	headerValueMask = uint64(1)<<codecShift - 1
+)
+
+// zstdMagic - first bytes of every zstd frame, not stored in the file
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+const (
+	zstdLevel          = zstd.SpeedBetterCompression
+	zstdDictID         = 1                // dictionary is private to the file, so smallest ID is used: it's written into every frame
+	zstdMaxDictSize    = 64 * 1024        // size of trained zstd dictionary
+	zstdSamplesLimit   = 16 * 1024 * 1024 // how many bytes of words are used to train zstd dictionary
+	zstdMinSamplesSize = 8 * 1024         // don't train dictionary on less than that
+	zstdSampleSize     = 16 * 1024        // words are concatenated into samples, training cost grows with amount of samples
+)
+
+// addZstdSample keeps words to train zstd dictionary on
+func (c *Compressor) addZstdSample(word []byte) {
+	if len(word) == 0 || c.zstdSamplesSize+len(word) > zstdSamplesLimit {
+		return
+	}
+	if n := len(c.zstdSamples); n == 0 || len(c.zstdSamples[n-1])+len(word) > zstdSampleSize {
+		c.zstdSamples = append(c.zstdSamples, make([]byte, 0, zstdSampleSize))
+	}
+	last := len(c.zstdSamples) - 1
+	c.zstdSamples[last] = append(c.zstdSamples[last], word...)
+	c.zstdSamplesSize += len(word)
+}
+
+func trainZstdDictionary(samples [][]byte, samplesSize int) ([]byte, error) {
+	if samplesSize < zstdMinSamplesSize {
+		return nil, nil
+	}
+	return dict.BuildZstdDict(samples, dict.Options{MaxDictSize: zstdMaxDictSize, HashBytes: 6, ZstdDictID: zstdDictID, ZstdLevel: zstdLevel})
+}
+
+// compressWords writes the file with CodecZstd or CodecRaw codec - no dictionary of patterns is built
+func (c *Compressor) compressWords(segmentFilePath string) error {
+	var zdict []byte
+	var enc *zstd.Encoder
+	if c.codec == CodecZstd {
+		var err error
+		if c.tail != nil { // tail segment is compressed with dictionary of the file
+			zdict = c.zstdDict
+		} else if zdict, err = trainZstdDictionary(c.zstdSamples, c.zstdSamplesSize); err != nil {
+			// dictionary can't be trained on some inputs, words are still compressed without it
+			log.Warn(fmt.Sprintf("[%s] train zstd dictionary", c.logPrefix), "err", err)
+			zdict = nil
+		}
+		c.zstdSamples = nil
+		opts := []zstd.EOption{zstd.WithEncoderCRC(false), zstd.WithEncoderConcurrency(1), zstd.WithEncoderLevel(zstdLevel)}
+		if len(zdict) > 0 {
+			opts = append(opts, zstd.WithEncoderDict(zdict))
+		}
+		if enc, err = zstd.NewWriter(nil, opts...); err != nil && len(zdict) > 0 {
+			// trainer may produce dictionary which zstd refuses to load
+			log.Warn(fmt.Sprintf("[%s] load zstd dictionary", c.logPrefix), "err", err)
+			zdict = nil
+			enc, err = zstd.NewWriter(nil, opts[:len(opts)-1]...)
+		}
+		if err != nil {
+			return err
+		}
+		defer enc.Close()
+	}
+
+	cf, err := os.Create(segmentFilePath)
+	if err != nil {
+		return err
+	}
+	defer cf.Close()
+	cw := bufio.NewWriterSize(cf, 2*etl.BufIOSize)
+	var header [24]byte
+	binary.BigEndian.PutUint64(header[:8], c.wordsCount)
+	binary.BigEndian.PutUint64(header[8:16], c.emptyWordsCount)
+	binary.BigEndian.PutUint64(header[16:24], uint64(c.codec)<<codecShift|uint64(len(zdict)))
+	if _, err = cw.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err = cw.Write(zdict); err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint64(header[:8], 0) // empty positions section
+	if _, err = cw.Write(header[:8]); err != nil {
+		return err
+	}
+
+	var numBuf [binary.MaxVarintLen64]byte
+	var frame []byte
+	if err = c.uncompressedFile.ForEach(func(v []byte, compression bool) error {
+		stored, zstdFlag := v, uint64(0)
+		if enc != nil && compression && len(v) > 0 {
+			// incompressible words (hashes, signatures) are stored as is
+			if frame = enc.EncodeAll(v, frame[:0]); len(frame)-len(zstdMagic) < len(v) {
+				stored, zstdFlag = frame[len(zstdMagic):], 1
+			}
+		}
+		n := binary.PutUvarint(numBuf[:], uint64(len(stored))<<1|zstdFlag)
+		if _, err := cw.Write(numBuf[:n]); err != nil {
+			return err
+		}
+		_, err := cw.Write(stored)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err = cw.Flush(); err != nil {
+		return err
+	}
+	return cf.Close()
+}
+
+func newZstdDecoder(zdict []byte) (*zstd.Decoder, error) {
+	opts := []zstd.DOption{zstd.WithDecoderConcurrency(0)}
+	if len(zdict) > 0 {
+		opts = append(opts, zstd.WithDecoderDicts(zdict))
+	}
+	return zstd.NewReader(nil, opts...)
+}
+
+// nextWord reads the word in CodecZstd and CodecRaw files: stored bytes and flag if they are zstd frame
+func (g *Getter) nextWord() (stored []byte, compressed bool) {
+	h, n := binary.Uvarint(g.data[g.dataP:])
+	if n <= 0 {
+		panic(fmt.Sprintf("file: %s, invalid word header at %d", g.fName, g.dataP))
+	}
+	start := g.dataP + uint64(n)
+	g.dataP, g.dataBit = start+h>>1, 0
+	return g.data[start:g.dataP], h&1 == 1
+}
+
+func (g *Getter) decodeWord(stored []byte, compressed bool, buf []byte) []byte {
+	if !compressed {
+		return append(buf, stored...)
+	}
+	g.frame = append(append(g.frame[:0], zstdMagic...), stored...)
+	buf, err := g.zstd.DecodeAll(g.frame, buf)
+	if err != nil {
+		panic(fmt.Sprintf("file: %s, %s", g.fName, err))
+	}
+	return buf
+}
+
+// wordAt returns current word without moving the offset, zstd frames are decoded into scratch buffer of getter
+func (g *Getter) wordAt() []byte {
+	savePos := g.dataP
+	stored, compressed := g.nextWord()
+	g.dataP = savePos
+	if !compressed {
+		return stored
+	}
+	g.scratch = g.decodeWord(stored, compressed, g.scratch[:0])
+	return g.scratch
+}
+
+func (g *Getter) nextWordDecoded(buf []byte) ([]byte, uint64) {
+	stored, compressed := g.nextWord()
+	return g.decodeWord(stored, compressed, buf), g.dataP
+}
+
+func (g *Getter) matchWord(buf []byte) (bool, uint64) {
+	savePos := g.dataP
+	if !bytes.Equal(g.wordAt(), buf) {
+		return false, savePos
+	}
+	g.nextWord()
+	return true, g.dataP
+}
+
+func (g *Getter) matchWordPrefix(prefix []byte) bool {
+	return bytes.HasPrefix(g.wordAt(), prefix)
+}
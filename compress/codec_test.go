@@ -0,0 +1,131 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func codecWords(count int) [][]byte {
+	rnd := rand.New(rand.NewSource(0))
+	words := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		switch i % 4 {
+		case 0:
+			words = append(words, nil)
+		case 1: // high entropy, like hashes
+			w := make([]byte, 32)
+			rnd.Read(w)
+			words = append(words, w)
+		default:
+			words = append(words, []byte(fmt.Sprintf("%s %d %s", loremStrings[i%len(loremStrings)], i, loremStrings[(i*7)%len(loremStrings)])))
+		}
+	}
+	return words
+}
+
+func prepareCodecFile(tb testing.TB, file string, codec Codec, words [][]byte) *Decompressor {
+	tb.Helper()
+	c, err := NewCompressor(context.Background(), "codec", file, filepath.Dir(file), 1, 2, log.LvlDebug)
+	require.NoError(tb, err)
+	defer c.Close()
+	c.SetCodec(codec)
+	c.SetWordOffsets(true)
+	for i, w := range words {
+		if i%7 == 6 {
+			require.NoError(tb, c.AddUncompressedWord(w))
+		} else {
+			require.NoError(tb, c.AddWord(w))
+		}
+	}
+	require.NoError(tb, c.Compress())
+	d, err := NewDecompressor(file)
+	require.NoError(tb, err)
+	require.Equal(tb, codec, d.Codec())
+	return d
+}
+
+func TestCodecs(t *testing.T) {
+	words := codecWords(5000)
+	for _, codec := range []Codec{CodecPattern, CodecZstd, CodecRaw} {
+		codec := codec
+		t.Run(codec.String(), func(t *testing.T) {
+			parsed, err := ParseCodec(codec.String())
+			require.NoError(t, err)
+			require.Equal(t, codec, parsed)
+
+			d := prepareCodecFile(t, filepath.Join(t.TempDir(), "compressed"), codec, words)
+			defer d.Close()
+			require.Equal(t, len(words), d.Count())
+			require.Equal(t, (len(words)+3)/4, d.EmptyWordsCount())
+
+			g := d.MakeGetter()
+			var word, buf []byte
+			for i := 0; g.HasNext(); i++ {
+				offset := g.dataP
+				if len(words[i]) > 4 {
+					require.True(t, g.MatchPrefix(words[i][:4]), i)
+					require.False(t, g.MatchPrefix(append(append([]byte{}, words[i]...), 0)), i)
+					ok, _ := g.Match(words[i][1:])
+					require.False(t, ok, i)
+				}
+				require.Equal(t, offset, g.dataP, "Match and MatchPrefix don't move offset on mismatch")
+				if i%7 == 6 {
+					word, _ = g.NextUncompressed()
+				} else {
+					buf, _ = g.Next(buf[:0])
+					word = buf
+				}
+				require.Equal(t, string(words[i]), string(word), i)
+
+				g.Reset(offset)
+				ok, next := g.Match(words[i])
+				require.True(t, ok, i)
+				g.Reset(offset)
+				if i%7 == 6 {
+					require.Equal(t, next, g.SkipUncompressed())
+				} else {
+					require.Equal(t, next, g.Skip())
+				}
+			}
+
+			if codec == CodecZstd {
+				var frames int
+				for g.Reset(0); g.HasNext(); {
+					if _, compressed := g.nextWord(); compressed {
+						frames++
+					}
+				}
+				require.Greater(t, frames, 0)
+			}
+
+			// sidecars are built by scanning the file with Skip
+			for _, i := range []int{0, 1, 2, 3, 100, len(words) - 1} {
+				word, err := d.WordAt(i, nil)
+				require.NoError(t, err)
+				require.Equal(t, string(words[i]), string(word), i)
+			}
+		})
+	}
+}
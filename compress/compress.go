@@ -69,6 +69,15 @@ type Compressor struct {
 	Ratio            CompressionRatio
 	lvl              log.Lvl
 	trace            bool
+	wordOffsets      bool
+	keyIndexStep     int
+	dict             *Dictionary // shared dictionary, see SetDictionary
+	codec            Codec
+	emptyWordsCount  uint64   // maintained only for codecs other than CodecPattern
+	zstdSamples      [][]byte // words to train zstd dictionary on, see CodecZstd
+	zstdSamplesSize  int
+	zstdDict         []byte       // zstd dictionary of the file, which tail segment is written for
+	tail             *tailBinding // set if compressor writes tail segment, see NewAppendCompressor
 }
 
 func NewCompressor(ctx context.Context, logPrefix, outputFile, tmpDir string, minPatternScore uint64, workers int, lvl log.Lvl) (*Compressor, error) {
@@ -126,6 +135,28 @@ func (c *Compressor) SetTrace(trace bool) {
 	c.trace = trace
 }
 
+// SetWordOffsets enables building of word offsets sidecar by Compress, see BuildWordOffsets
+func (c *Compressor) SetWordOffsets(enabled bool) {
+	c.wordOffsets = enabled
+}
+
+// SetSparseKeyIndex enables building of sparse key index by Compress, with one sample per `step` key/value pairs.
+// Words must be added as sorted key/value pairs, see BuildSparseKeyIndex
+func (c *Compressor) SetSparseKeyIndex(step int) {
+	c.keyIndexStep = step
+}
+
+// SetDictionary makes Compressor use shared dictionary instead of building own one. Dictionary is not stored
+// in the output file, decompressor needs to find it by ID in DictionaryRegistry. Must be called before adding words
+func (c *Compressor) SetDictionary(dict *Dictionary) {
+	c.dict = dict
+}
+
+// SetCodec selects how words are encoded, see Codec. Must be called before adding words
+func (c *Compressor) SetCodec(codec Codec) {
+	c.codec = codec
+}
+
 func (c *Compressor) Count() int { return int(c.wordsCount) }
 
 func (c *Compressor) AddWord(word []byte) error {
@@ -136,6 +167,18 @@ func (c *Compressor) AddWord(word []byte) error {
 	}
 
 	c.wordsCount++
+	if c.codec != CodecPattern {
+		if len(word) == 0 {
+			c.emptyWordsCount++
+		}
+		if c.codec == CodecZstd && c.tail == nil {
+			c.addZstdSample(word)
+		}
+		return c.uncompressedFile.Append(word)
+	}
+	if c.dict != nil { // no need to sample superstrings - dictionary is already known
+		return c.uncompressedFile.Append(word)
+	}
 	l := 2*len(word) + 2
 	if c.superstringLen+l > superstringLimit {
 		if c.superstringCount%samplingFactor == 0 {
@@ -165,6 +208,9 @@ func (c *Compressor) AddUncompressedWord(word []byte) error {
 	}
 
 	c.wordsCount++
+	if len(word) == 0 {
+		c.emptyWordsCount++
+	}
 	return c.uncompressedFile.AppendUncompressed(word)
 }
 
@@ -178,34 +224,66 @@ func (c *Compressor) Compress() error {
 	close(c.superstrings)
 	c.wg.Wait()
 
-	if c.lvl < log.LvlTrace {
-		log.Log(c.lvl, fmt.Sprintf("[%s] BuildDict start", c.logPrefix), "workers", c.workers)
+	if c.codec != CodecPattern && c.dict != nil {
+		return fmt.Errorf("shared dictionary is supported only by %s codec, got %s", CodecPattern, c.codec)
 	}
-	t := time.Now()
-	db, err := DictionaryBuilderFromCollectors(c.ctx, compressLogPrefix, c.tmpDir, c.suffixCollectors, c.lvl)
-	if err != nil {
+	var db *DictionaryBuilder
+	var err error
+	if c.dict == nil && c.codec == CodecPattern {
+		if c.lvl < log.LvlTrace {
+			log.Log(c.lvl, fmt.Sprintf("[%s] BuildDict start", c.logPrefix), "workers", c.workers)
+		}
+		t := time.Now()
+		db, err = DictionaryBuilderFromCollectors(c.ctx, compressLogPrefix, c.tmpDir, c.suffixCollectors, c.lvl)
+		if err != nil {
 
-		return err
-	}
-	if c.trace {
-		_, fileName := filepath.Split(c.outputFile)
-		if err := PersistDictrionary(filepath.Join(c.tmpDir, fileName)+".dictionary.txt", db); err != nil {
 			return err
 		}
+		if c.trace {
+			_, fileName := filepath.Split(c.outputFile)
+			if err := PersistDictrionary(filepath.Join(c.tmpDir, fileName)+".dictionary.txt", db); err != nil {
+				return err
+			}
+		}
+		if c.lvl < log.LvlTrace {
+			log.Log(c.lvl, fmt.Sprintf("[%s] BuildDict", c.logPrefix), "took", time.Since(t))
+		}
 	}
 	defer os.Remove(c.tmpOutFilePath)
-	if c.lvl < log.LvlTrace {
-		log.Log(c.lvl, fmt.Sprintf("[%s] BuildDict", c.logPrefix), "took", time.Since(t))
+	segmentFilePath := c.tmpOutFilePath
+	if c.tail != nil { // tail segment is prefixed by header, see NewAppendCompressor
+		_, fileName := filepath.Split(c.outputFile)
+		segmentFilePath = filepath.Join(c.tmpDir, fileName) + ".seg"
+		defer os.Remove(segmentFilePath)
 	}
 
-	t = time.Now()
-	if err := reducedict(c.ctx, c.trace, c.logPrefix, c.tmpOutFilePath, c.uncompressedFile, c.workers, db, c.lvl); err != nil {
+	t := time.Now()
+	if c.codec != CodecPattern {
+		if err := c.compressWords(segmentFilePath); err != nil {
+			return err
+		}
+	} else if err := reducedict(c.ctx, c.trace, c.logPrefix, segmentFilePath, c.uncompressedFile, c.workers, db, c.dict, c.lvl); err != nil {
 		return err
 	}
+	if c.tail != nil {
+		if err := c.tail.write(c.tmpOutFilePath, segmentFilePath); err != nil {
+			return fmt.Errorf("tail: %w", err)
+		}
+	}
 
+	if c.tail == nil {
+		if err := removeSidecars(c.outputFile); err != nil {
+			return err
+		}
+	}
 	if err := os.Rename(c.tmpOutFilePath, c.outputFile); err != nil {
 		return fmt.Errorf("renaming: %w", err)
 	}
+	if c.tail == nil {
+		if err := c.buildSidecars(); err != nil {
+			return err
+		}
+	}
 	c.Ratio, err = Ratio(c.uncompressedFile.filePath, c.outputFile)
 	if err != nil {
 		return fmt.Errorf("ratio: %w", err)
@@ -218,6 +296,40 @@ func (c *Compressor) Compress() error {
 	return nil
 }
 
+// removeSidecars removes sidecars and tail segment of the previous version of the file. It's done before the new
+// version replaces it, so crash in between doesn't leave stale sidecars next to the new file
+func removeSidecars(compressedFilePath string) error {
+	if err := os.Remove(TailPath(compressedFilePath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove stale tail: %w", err)
+	}
+	if err := os.Remove(WordOffsetsPath(compressedFilePath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove stale word offsets: %w", err)
+	}
+	if err := os.Remove(SparseKeyIndexPath(compressedFilePath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove stale sparse key index: %w", err)
+	}
+	return nil
+}
+
+// buildSidecars builds requested sidecars of the output file
+func (c *Compressor) buildSidecars() error {
+	dicts := DefaultDictionaries
+	if c.dict != nil {
+		dicts = NewDictionaryRegistry(c.dict)
+	}
+	if c.wordOffsets {
+		if err := buildWordOffsets(c.ctx, c.outputFile, dicts); err != nil {
+			return fmt.Errorf("word offsets: %w", err)
+		}
+	}
+	if c.keyIndexStep > 0 {
+		if err := buildSparseKeyIndex(c.ctx, c.outputFile, c.keyIndexStep, dicts); err != nil {
+			return fmt.Errorf("sparse key index: %w", err)
+		}
+	}
+	return nil
+}
+
 // superstringLimit limits how large can one "superstring" get before it is processed
 // CompressorSequential allocates 7 bytes for each uint of superstringLimit. For example,
 // superstingLimit 16m will result in 112Mb being allocated for various arrays
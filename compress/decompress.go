@@ -27,6 +27,8 @@ import (
 
 	"github.com/gateway-fm/cdk-erigon-lib/common/dbg"
 	"github.com/gateway-fm/cdk-erigon-lib/mmap"
+	"github.com/gateway-fm/cdk-erigon-lib/recsplit/eliasfano32"
+	"github.com/klauspost/compress/zstd"
 	"github.com/ledgerwatch/log/v3"
 )
 
@@ -99,17 +101,25 @@ type posTable struct {
 
 // Decompressor provides access to the superstrings in a file produced by a compressor
 type Decompressor struct {
-	f               *os.File
-	mmapHandle2     *[mmap.MaxMapSize]byte // mmap handle for windows (this is used to close mmap)
-	dict            *patternTable
-	posDict         *posTable
-	mmapHandle1     []byte // mmap handle for unix (this is used to close mmap)
-	data            []byte // slice of correct size for the decompressor to work with
-	wordsStart      uint64 // Offset of whether the superstrings actually start
-	size            int64
-	modTime         time.Time
-	wordsCount      uint64
-	emptyWordsCount uint64
+	f                  *os.File
+	mmapHandle2        *[mmap.MaxMapSize]byte // mmap handle for windows (this is used to close mmap)
+	dict               *patternTable
+	posDict            *posTable
+	mmapHandle1        []byte // mmap handle for unix (this is used to close mmap)
+	data               []byte // slice of correct size for the decompressor to work with
+	wordsStart         uint64 // Offset of whether the superstrings actually start
+	size               int64
+	modTime            time.Time
+	wordsCount         uint64
+	emptyWordsCount    uint64
+	wordOffsets        *eliasfano32.EliasFano // optional sidecar, see BuildWordOffsets
+	keyIndex           *sparseKeyIndex        // optional sidecar, see BuildSparseKeyIndex
+	tail               *Decompressor          // optional tail segment, see NewAppendCompressor
+	sharedDict         *Dictionary            // if externalDictionary
+	zstd               *zstd.Decoder          // CodecZstd only
+	codec              Codec
+	dictionaryID       uint64 // ID of shared dictionary, if externalDictionary
+	externalDictionary bool
 
 	filePath, fileName string
 }
@@ -144,6 +154,20 @@ func SetDecompressionTableCondensity(fromBitSize int) {
 }
 
 func NewDecompressor(compressedFilePath string) (*Decompressor, error) {
+	return newDecompressor(compressedFilePath, DefaultDictionaries, true)
+}
+
+// NewDecompressorWithDictionaries - same as NewDecompressor, but resolves shared dictionary of the file (if any) through given registry
+func NewDecompressorWithDictionaries(compressedFilePath string, dicts *DictionaryRegistry) (*Decompressor, error) {
+	return newDecompressor(compressedFilePath, dicts, true)
+}
+
+func newDecompressor(compressedFilePath string, dicts *DictionaryRegistry, withSidecars bool) (*Decompressor, error) {
+	return openDecompressor(compressedFilePath, dicts, withSidecars, 0)
+}
+
+// openDecompressor opens compressed file which starts at headerSize offset (non-zero only for tail segment)
+func openDecompressor(compressedFilePath string, dicts *DictionaryRegistry, withSidecars bool, headerSize int64) (*Decompressor, error) {
 	_, fName := filepath.Split(compressedFilePath)
 	d := &Decompressor{
 		filePath: compressedFilePath,
@@ -165,7 +189,7 @@ func NewDecompressor(compressedFilePath string) (*Decompressor, error) {
 		return nil, err
 	}
 	d.size = stat.Size()
-	if d.size < 32 {
+	if d.size < headerSize+32 {
 		return nil, fmt.Errorf("compressed file is too short: %d", d.size)
 	}
 	d.modTime = stat.ModTime()
@@ -174,69 +198,54 @@ func NewDecompressor(compressedFilePath string) (*Decompressor, error) {
 	}
 
 	// read patterns from file
-	d.data = d.mmapHandle1[:d.size]
+	d.data = d.mmapHandle1[headerSize:d.size]
 	d.wordsCount = binary.BigEndian.Uint64(d.data[:8])
 	d.emptyWordsCount = binary.BigEndian.Uint64(d.data[8:16])
 	dictSize := binary.BigEndian.Uint64(d.data[16:24])
-	data := d.data[24 : 24+dictSize]
-
-	var depths []uint64
-	var patterns [][]byte
-	var i uint64
-	var patternMaxDepth uint64
-
-	for i < dictSize {
-		d, ns := binary.Uvarint(data[i:])
-		if d > 64 { // mainnet has maxDepth 31
-			return nil, fmt.Errorf("dictionary is invalid: patternMaxDepth=%d", d)
+	d.codec = Codec(dictSize >> codecShift & codecMask)
+	if d.codec != CodecPattern {
+		dictSize &= headerValueMask
+		switch d.codec {
+		case CodecZstd:
+			if d.zstd, err = newZstdDecoder(d.data[24 : 24+dictSize]); err != nil {
+				d.Close()
+				return nil, fmt.Errorf("zstd dictionary of %s: %w", compressedFilePath, err)
+			}
+		case CodecRaw:
+		default:
+			d.Close()
+			return nil, fmt.Errorf("unknown codec %d, file: %s", d.codec, compressedFilePath)
 		}
-		depths = append(depths, d)
-		if d > patternMaxDepth {
-			patternMaxDepth = d
+	} else if dictSize&externalDictionaryFlag != 0 {
+		d.dictionaryID, d.externalDictionary = dictSize&headerValueMask, true
+		dict, ok := dicts.Get(d.dictionaryID)
+		if !ok {
+			d.Close()
+			return nil, fmt.Errorf("%w: %x, file: %s", ErrUnknownDictionary, d.dictionaryID, compressedFilePath)
 		}
-		i += uint64(ns)
-		l, n := binary.Uvarint(data[i:])
-		i += uint64(n)
-		patterns = append(patterns, data[i:i+l])
-		//fmt.Printf("depth = %d, pattern = [%x]\n", d, data[i:i+l])
-		i += l
-	}
-
-	if dictSize > 0 {
-		var bitLen int
-		if patternMaxDepth > 9 {
-			bitLen = 9
-		} else {
-			bitLen = int(patternMaxDepth)
+		d.dict, d.sharedDict = dict.patternTable(), dict
+		dictSize = 0 // patterns are not stored in the file
+	} else {
+		dictSize &= headerValueMask
+		depths, patterns, patternMaxDepth, err := readPatterns(d.data[24 : 24+dictSize])
+		if err != nil {
+			d.Close()
+			return nil, err
+		}
+		if dictSize > 0 {
+			d.dict = newCondensedPatternTable(depths, patterns, patternMaxDepth)
 		}
-		// fmt.Printf("pattern maxDepth=%d\n", tree.maxDepth)
-		d.dict = newPatternTable(bitLen)
-		buildCondensedPatternTable(d.dict, depths, patterns, 0, 0, 0, patternMaxDepth)
 	}
 
 	// read positions
 	pos := 24 + dictSize
 	dictSize = binary.BigEndian.Uint64(d.data[pos : pos+8])
-	data = d.data[pos+8 : pos+8+dictSize]
+	data := d.data[pos+8 : pos+8+dictSize]
 
-	var posDepths []uint64
-	var poss []uint64
-	var posMaxDepth uint64
-
-	i = 0
-	for i < dictSize {
-		d, ns := binary.Uvarint(data[i:])
-		if d > 2048 {
-			return nil, fmt.Errorf("dictionary is invalid: posMaxDepth=%d", d)
-		}
-		posDepths = append(posDepths, d)
-		if d > posMaxDepth {
-			posMaxDepth = d
-		}
-		i += uint64(ns)
-		pos, n := binary.Uvarint(data[i:])
-		i += uint64(n)
-		poss = append(poss, pos)
+	posDepths, poss, posMaxDepth, err := readPositions(data)
+	if err != nil {
+		d.Close()
+		return nil, err
 	}
 
 	if dictSize > 0 {
@@ -257,9 +266,77 @@ func NewDecompressor(compressedFilePath string) (*Decompressor, error) {
 		buildPosTable(posDepths, poss, d.posDict, 0, 0, 0, posMaxDepth)
 	}
 	d.wordsStart = pos + 8 + dictSize
+	if withSidecars {
+		// sidecar files are written after the file itself, stale or corrupt one (left by interrupted write or
+		// by another version of the file) is treated as absent
+		if err = d.openWordOffsets(); err != nil {
+			log.Warn("[decompress] ignoring word offsets", "file", fName, "err", err)
+		}
+		if err = d.openSparseKeyIndex(); err != nil {
+			log.Warn("[decompress] ignoring sparse key index", "file", fName, "err", err)
+		}
+		if err = d.openTail(dicts); err != nil {
+			log.Warn("[decompress] ignoring tail segment", "file", fName, "err", err)
+		}
+	}
 	return d, nil
 }
 
+// readPatterns parses patterns section of compressed file: depth of each pattern in huffman tree, length and pattern
+func readPatterns(data []byte) (depths []uint64, patterns [][]byte, maxDepth uint64, err error) {
+	var i uint64
+	for i < uint64(len(data)) {
+		d, ns := binary.Uvarint(data[i:])
+		if d > 64 { // mainnet has maxDepth 31
+			return nil, nil, 0, fmt.Errorf("dictionary is invalid: patternMaxDepth=%d", d)
+		}
+		depths = append(depths, d)
+		if d > maxDepth {
+			maxDepth = d
+		}
+		i += uint64(ns)
+		l, n := binary.Uvarint(data[i:])
+		i += uint64(n)
+		patterns = append(patterns, data[i:i+l])
+		//fmt.Printf("depth = %d, pattern = [%x]\n", d, data[i:i+l])
+		i += l
+	}
+	return depths, patterns, maxDepth, nil
+}
+
+// readPositions parses positions section of compressed file: depth of each position in huffman tree and position
+func readPositions(data []byte) (depths []uint64, poss []uint64, maxDepth uint64, err error) {
+	var i uint64
+	for i < uint64(len(data)) {
+		d, ns := binary.Uvarint(data[i:])
+		if d > 2048 {
+			return nil, nil, 0, fmt.Errorf("dictionary is invalid: posMaxDepth=%d", d)
+		}
+		depths = append(depths, d)
+		if d > maxDepth {
+			maxDepth = d
+		}
+		i += uint64(ns)
+		pos, n := binary.Uvarint(data[i:])
+		i += uint64(n)
+		poss = append(poss, pos)
+	}
+	return depths, poss, maxDepth, nil
+}
+
+func newCondensedPatternTable(depths []uint64, patterns [][]byte, maxDepth uint64) *patternTable {
+	var bitLen int
+	if maxDepth > 9 {
+		bitLen = 9
+	} else {
+		bitLen = int(maxDepth)
+	}
+	// fmt.Printf("pattern maxDepth=%d\n", tree.maxDepth)
+	table := newPatternTable(bitLen)
+	buildCondensedPatternTable(table, depths, patterns, 0, 0, 0, maxDepth)
+	return table
+}
+
 func buildCondensedPatternTable(table *patternTable, depths []uint64, patterns [][]byte, code uint16, bits int, depth uint64, maxDepth uint64) int {
 	if len(depths) == 0 {
 		return 0
@@ -341,6 +418,12 @@ func (d *Decompressor) ModTime() time.Time {
 }
 
 func (d *Decompressor) Close() error {
+	if d.tail != nil {
+		d.tail.Close()
+	}
+	if d.zstd != nil {
+		d.zstd.Close()
+	}
 	if err := mmap.Munmap(d.mmapHandle1, d.mmapHandle2); err != nil {
 		log.Trace("unmap", "err", err, "file", d.FileName())
 	}
@@ -396,8 +479,16 @@ func (d *Decompressor) EnableWillNeed() *Decompressor {
 // Getter represent "reader" or "interator" that can move accross the data of the decompressor
 // The full state of the getter can be captured by saving dataP, and dataBit
 type Getter struct {
+	segments    []getterSegment // file and it's tail, nil if there is no tail
+	seg         int             // current segment
+	base        uint64          // offset of the current segment
 	patternDict *patternTable
 	posDict     *posTable
+	keyIndex    *sparseKeyIndex
+	zstd        *zstd.Decoder // CodecZstd only
+	scratch     []byte        // decoded zstd word for Match and MatchPrefix
+	frame       []byte        // zstd frame with restored magic number
+	codec       Codec
 	fName       string
 	data        []byte
 	dataP       uint64
@@ -405,6 +496,20 @@ type Getter struct {
 	trace       bool
 }
 
+// getterSegment - data and decoding tables of the file or it's tail segment
+type getterSegment struct {
+	patternDict *patternTable
+	posDict     *posTable
+	zstd        *zstd.Decoder
+	data        []byte
+	base        uint64
+	codec       Codec
+}
+
+func (g *Getter) segment(base uint64) getterSegment {
+	return getterSegment{patternDict: g.patternDict, posDict: g.posDict, zstd: g.zstd, data: g.data, base: base, codec: g.codec}
+}
+
 func (g *Getter) Trace(t bool)     { g.trace = t }
 func (g *Getter) FileName() string { return g.fName }
 
@@ -496,42 +601,113 @@ func buildCondensedWordDistances() [][]int {
 }
 
 func (g *Getter) Size() int {
+	if n := len(g.segments); n > 0 {
+		return int(g.segments[n-1].base) + len(g.segments[n-1].data)
+	}
 	return len(g.data)
 }
 
-func (d *Decompressor) Count() int           { return int(d.wordsCount) }
-func (d *Decompressor) EmptyWordsCount() int { return int(d.emptyWordsCount) }
+// DictionaryID returns ID of shared dictionary, which file was compressed with (see Compressor.SetDictionary)
+func (d *Decompressor) DictionaryID() (id uint64, external bool) {
+	return d.dictionaryID, d.externalDictionary
+}
+
+func (d *Decompressor) Codec() Codec { return d.codec }
+
+// Count returns amount of words in the file, including words of tail segment
+func (d *Decompressor) Count() int {
+	if d.tail != nil {
+		return int(d.wordsCount + d.tail.wordsCount)
+	}
+	return int(d.wordsCount)
+}
+
+func (d *Decompressor) EmptyWordsCount() int {
+	if d.tail != nil {
+		return int(d.emptyWordsCount + d.tail.emptyWordsCount)
+	}
+	return int(d.emptyWordsCount)
+}
 
 // MakeGetter creates an object that can be used to access superstrings in the decompressor's file
 // Getter is not thread-safe, but there can be multiple getters used simultaneously and concurrently
-// for the same decompressor
+// for the same decompressor. Words of the tail segment (see NewAppendCompressor) follow words of the file
 func (d *Decompressor) MakeGetter() *Getter {
+	g := d.makeGetter()
+	if d.tail != nil {
+		g.segments = []getterSegment{g.segment(0), d.tail.makeGetter().segment(d.tailBase())}
+	}
+	return g
+}
+
+// makeGetter creates getter over words of the file itself, without tail segment
+func (d *Decompressor) makeGetter() *Getter {
 	return &Getter{
 		posDict:     d.posDict,
 		data:        d.data[d.wordsStart:],
 		patternDict: d.dict,
+		keyIndex:    d.keyIndex,
+		zstd:        d.zstd,
+		codec:       d.codec,
 		fName:       d.fileName,
 	}
 }
 
 func (g *Getter) Reset(offset uint64) {
+	if g.segments != nil {
+		i := len(g.segments) - 1
+		for i > 0 && offset < g.segments[i].base {
+			i--
+		}
+		g.useSegment(i)
+		offset -= g.base
+	}
 	g.dataP = offset
 	g.dataBit = 0
 }
 
 func (g *Getter) HasNext() bool {
-	return g.dataP < uint64(len(g.data))
+	return g.dataP < uint64(len(g.data)) || g.seg+1 < len(g.segments)
+}
+
+// nextSegment moves getter to the beginning of the next segment (tail of the file) when current one is over
+func (g *Getter) nextSegment() {
+	if g.segments != nil && g.dataP >= uint64(len(g.data)) && g.seg+1 < len(g.segments) {
+		g.useSegment(g.seg + 1)
+		g.dataP, g.dataBit = 0, 0
+	}
+}
+
+func (g *Getter) useSegment(i int) {
+	s := &g.segments[i]
+	g.seg, g.base = i, s.base
+	g.patternDict, g.posDict, g.zstd, g.codec, g.data = s.patternDict, s.posDict, s.zstd, s.codec, s.data
+}
+
+// offset returns offset of the current word, taking segments into account
+func (g *Getter) offset() uint64 {
+	g.nextSegment()
+	return g.base + g.dataP
 }
 
 // Next extracts a compressed word from current offset in the file
 // and appends it to the given buf, returning the result of appending
 // After extracting next word, it moves to the beginning of the next one
 func (g *Getter) Next(buf []byte) ([]byte, uint64) {
+	g.nextSegment()
+	buf, offset := g.next(buf)
+	return buf, g.base + offset
+}
+
+func (g *Getter) next(buf []byte) ([]byte, uint64) {
 	defer func() {
 		if rec := recover(); rec != nil {
 			panic(fmt.Sprintf("file: %s, %s, %s", g.fName, rec, dbg.Stack()))
 		}
 	}()
+	if g.codec != CodecPattern {
+		return g.nextWordDecoded(buf)
+	}
 	savePos := g.dataP
 	wordLen := g.nextPos(true)
 	wordLen-- // because when create huffman tree we do ++ , because 0 is terminator
@@ -588,11 +764,24 @@ func (g *Getter) Next(buf []byte) ([]byte, uint64) {
 }
 
 func (g *Getter) NextUncompressed() ([]byte, uint64) {
+	g.nextSegment()
+	word, offset := g.nextUncompressed()
+	return word, g.base + offset
+}
+
+func (g *Getter) nextUncompressed() ([]byte, uint64) {
 	defer func() {
 		if rec := recover(); rec != nil {
 			panic(fmt.Sprintf("file: %s, %s, %s", g.fName, rec, dbg.Stack()))
 		}
 	}()
+	if g.codec != CodecPattern {
+		stored, compressed := g.nextWord()
+		if compressed {
+			return g.decodeWord(stored, compressed, nil), g.dataP
+		}
+		return stored, g.dataP
+	}
 	wordLen := g.nextPos(true)
 	wordLen-- // because when create huffman tree we do ++ , because 0 is terminator
 	if wordLen == 0 {
@@ -614,6 +803,15 @@ func (g *Getter) NextUncompressed() ([]byte, uint64) {
 
 // Skip moves offset to the next word and returns the new offset.
 func (g *Getter) Skip() uint64 {
+	g.nextSegment()
+	return g.base + g.skip()
+}
+
+func (g *Getter) skip() uint64 {
+	if g.codec != CodecPattern {
+		g.nextWord()
+		return g.dataP
+	}
 	l := g.nextPos(true)
 	l-- // because when create huffman tree we do ++ , because 0 is terminator
 	if l == 0 {
@@ -651,6 +849,15 @@ func (g *Getter) Skip() uint64 {
 }
 
 func (g *Getter) SkipUncompressed() uint64 {
+	g.nextSegment()
+	return g.base + g.skipUncompressed()
+}
+
+func (g *Getter) skipUncompressed() uint64 {
+	if g.codec != CodecPattern {
+		g.nextWord()
+		return g.dataP
+	}
 	wordLen := g.nextPos(true)
 	wordLen-- // because when create huffman tree we do ++ , because 0 is terminator
 	if wordLen == 0 {
@@ -672,6 +879,15 @@ func (g *Getter) SkipUncompressed() uint64 {
 // Match returns true and next offset if the word at current offset fully matches the buf
 // returns false and current offset otherwise.
 func (g *Getter) Match(buf []byte) (bool, uint64) {
+	g.nextSegment()
+	ok, offset := g.match(buf)
+	return ok, g.base + offset
+}
+
+func (g *Getter) match(buf []byte) (bool, uint64) {
+	if g.codec != CodecPattern {
+		return g.matchWord(buf)
+	}
 	savePos := g.dataP
 	wordLen := g.nextPos(true)
 	wordLen-- // because when create huffman tree we do ++ , because 0 is terminator
@@ -737,6 +953,14 @@ func (g *Getter) Match(buf []byte) (bool, uint64) {
 
 // MatchPrefix only checks if the word at the current offset has a buf prefix. Does not move offset to the next word.
 func (g *Getter) MatchPrefix(prefix []byte) bool {
+	g.nextSegment()
+	return g.matchPrefix(prefix)
+}
+
+func (g *Getter) matchPrefix(prefix []byte) bool {
+	if g.codec != CodecPattern {
+		return g.matchWordPrefix(prefix)
+	}
 	savePos := g.dataP
 	defer func() {
 		g.dataP, g.dataBit = savePos, 0
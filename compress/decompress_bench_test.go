@@ -17,8 +17,10 @@
 package compress
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -72,6 +74,50 @@ func BenchmarkDecompressMatchPrefix(b *testing.B) {
 	}
 }
 
+func BenchmarkCodecs(b *testing.B) {
+	words := codecWords(20000)
+	for _, codec := range []Codec{CodecPattern, CodecZstd, CodecRaw} {
+		b.Run(codec.String(), func(b *testing.B) {
+			file := filepath.Join(b.TempDir(), "compressed")
+			b.Run("compress", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					prepareCodecFile(b, file, codec, words).Close()
+				}
+				st, err := os.Stat(file)
+				require.NoError(b, err)
+				b.ReportMetric(float64(st.Size()), "file_bytes")
+			})
+			d := prepareCodecFile(b, file, codec, words)
+			defer d.Close()
+			g := d.MakeGetter()
+			b.Run("next", func(b *testing.B) {
+				var buf []byte
+				for i := 0; i < b.N; i++ {
+					buf, _ = g.Next(buf[:0])
+					if !g.HasNext() {
+						g.Reset(0)
+					}
+				}
+			})
+			g.Reset(0)
+			b.Run("skip", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_ = g.Skip()
+					if !g.HasNext() {
+						g.Reset(0)
+					}
+				}
+			})
+			g.Reset(0)
+			b.Run("match", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_, _ = g.Match(words[2])
+				}
+			})
+		})
+	}
+}
+
 func BenchmarkDecompressTorrent(t *testing.B) {
 	t.Skip()
 
@@ -107,3 +153,28 @@ func BenchmarkDecompressTorrent(t *testing.B) {
 		}
 	})
 }
+
+func BenchmarkParallelScan(b *testing.B) {
+	d := prepareCodecFile(b, filepath.Join(b.TempDir(), "compressed"), CodecPattern, codecWords(100_000))
+	defer d.Close()
+	b.Run("sequential", func(b *testing.B) {
+		var buf []byte
+		for i := 0; i < b.N; i++ {
+			g := d.MakeGetter()
+			for g.HasNext() {
+				buf, _ = g.Next(buf[:0])
+			}
+		}
+	})
+	for _, workers := range []int{1, 4} {
+		for _, ordered := range []bool{true, false} {
+			b.Run(fmt.Sprintf("workers=%d,ordered=%t", workers, ordered), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if err := d.ParallelScan(context.Background(), workers, ordered, func(int, uint64, []byte) error { return nil }); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
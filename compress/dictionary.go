@@ -0,0 +1,298 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// Shared (external) dictionary is trained once and then used to compress many files without building
+// dictionary for each of them - useful for small files, where own dictionary would take significant part of the file.
+// Compressed file doesn't contain patterns of external dictionary, instead it's header has
+// `externalDictionaryFlag | dictionary ID` (56 bits, see Codec) in place of the patterns size. Decompressor resolves dictionary by ID
+// through DictionaryRegistry. Old decompressors can't open such files: they take the header as patterns size >= 2^63
+// and panic slicing patterns out of the file.
+//
+// Dictionary file has format:
+//   - dictionary ID (8 bytes)
+//   - patterns size (8 bytes)
+//   - patterns, encoded the same way as in compressed file: depth of the pattern in huffman tree, length, pattern
+
+// DictionaryFileExt - extension of persisted shared dictionary files
+const DictionaryFileExt = ".dict"
+
+const externalDictionaryFlag = uint64(1) << 63
+
+// ErrUnknownDictionary - compressed file refers to the dictionary which is not in registry
+var ErrUnknownDictionary = errors.New("unknown dictionary")
+
+// Dictionary - shared set of patterns with fixed huffman codes
+type Dictionary struct {
+	id       uint64
+	depths   []uint64
+	patterns [][]byte
+	codes    []uint64 // codes as decompressor reads them, see buildCondensedPatternTable
+	codeBits []int
+
+	tableOnce sync.Once
+	table     *patternTable // decoding table, shared by all decompressors which use the dictionary
+}
+
+// NewDictionary creates dictionary from patterns and their depths in huffman tree,
+// in the order they are written into compressed file
+func NewDictionary(depths []uint64, patterns [][]byte) (*Dictionary, error) {
+	if len(depths) != len(patterns) {
+		return nil, fmt.Errorf("dictionary is invalid: %d depths, %d patterns", len(depths), len(patterns))
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("dictionary is empty")
+	}
+	d := &Dictionary{
+		depths:   append([]uint64(nil), depths...),
+		patterns: make([][]byte, len(patterns)),
+		codes:    make([]uint64, len(patterns)),
+		codeBits: make([]int, len(patterns)),
+	}
+	for i, p := range patterns {
+		if depths[i] > 64 {
+			return nil, fmt.Errorf("dictionary is invalid: patternMaxDepth=%d", depths[i])
+		}
+		if len(p) == 0 {
+			return nil, fmt.Errorf("dictionary is invalid: empty pattern %d", i)
+		}
+		d.patterns[i] = common.Copy(p)
+	}
+	if n := assignDictionaryCodes(d.depths, d.codes, d.codeBits, 0, 0, 0); n != len(d.depths) {
+		return nil, fmt.Errorf("dictionary is invalid: only %d of %d patterns have codes", n, len(d.depths))
+	}
+	h := sha256.Sum256(d.encodePatterns(nil))
+	d.id = binary.BigEndian.Uint64(h[:8]) & headerValueMask
+	return d, nil
+}
+
+// assignDictionaryCodes mirrors buildCondensedPatternTable: bit N of the code is the branch taken at depth N
+func assignDictionaryCodes(depths []uint64, codes []uint64, codeBits []int, code uint64, bits int, depth uint64) int {
+	if len(depths) == 0 || depths[0] < depth {
+		return 0
+	}
+	if depth == depths[0] {
+		codes[0], codeBits[0] = code, bits
+		return 1
+	}
+	b0 := assignDictionaryCodes(depths, codes, codeBits, code, bits+1, depth+1)
+	return b0 + assignDictionaryCodes(depths[b0:], codes[b0:], codeBits[b0:], (uint64(1)<<bits)|code, bits+1, depth+1)
+}
+
+func (d *Dictionary) ID() uint64 { return d.id }
+func (d *Dictionary) Count() int { return len(d.patterns) }
+func (d *Dictionary) String() string {
+	return fmt.Sprintf("dictionary %x (%d patterns)", d.id, len(d.patterns))
+}
+
+func (d *Dictionary) encodePatterns(buf []byte) []byte {
+	var numBuf [binary.MaxVarintLen64]byte
+	for i, p := range d.patterns {
+		n := binary.PutUvarint(numBuf[:], d.depths[i])
+		buf = append(buf, numBuf[:n]...)
+		n = binary.PutUvarint(numBuf[:], uint64(len(p)))
+		buf = append(buf, numBuf[:n]...)
+		buf = append(buf, p...)
+	}
+	return buf
+}
+
+func (d *Dictionary) patternTable() *patternTable {
+	d.tableOnce.Do(func() {
+		var maxDepth uint64
+		for _, depth := range d.depths {
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+		}
+		d.table = newCondensedPatternTable(d.depths, d.patterns, maxDepth)
+	})
+	return d.table
+}
+
+// Persist writes dictionary to the file atomically (via temporary file)
+func (d *Dictionary) Persist(fileName string) error {
+	tmpFileName := fileName + ".tmp"
+	defer os.Remove(tmpFileName)
+	f, err := os.Create(tmpFileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	patterns := d.encodePatterns(nil)
+	w := bufio.NewWriter(f)
+	var numBuf [8]byte
+	binary.BigEndian.PutUint64(numBuf[:], d.id)
+	if _, err = w.Write(numBuf[:]); err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint64(numBuf[:], uint64(len(patterns)))
+	if _, err = w.Write(numBuf[:]); err != nil {
+		return err
+	}
+	if _, err = w.Write(patterns); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFileName, fileName)
+}
+
+// LoadDictionary reads dictionary written by Persist
+func LoadDictionary(fileName string) (*Dictionary, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 16 {
+		return nil, fmt.Errorf("dictionary file %s is too short: %d", fileName, len(data))
+	}
+	id := binary.BigEndian.Uint64(data[:8])
+	size := binary.BigEndian.Uint64(data[8:16])
+	if size != uint64(len(data)-16) {
+		return nil, fmt.Errorf("dictionary file %s: patterns size %d, expected %d", fileName, len(data)-16, size)
+	}
+	depths, patterns, _, err := readPatterns(data[16:])
+	if err != nil {
+		return nil, fmt.Errorf("dictionary file %s: %w", fileName, err)
+	}
+	d, err := NewDictionary(depths, patterns)
+	if err != nil {
+		return nil, fmt.Errorf("dictionary file %s: %w", fileName, err)
+	}
+	if d.id != id {
+		return nil, fmt.Errorf("dictionary file %s: id %x doesn't match content %x", fileName, id, d.id)
+	}
+	return d, nil
+}
+
+// TrainDictionary builds shared dictionary from the words of sample compressed files: all samples are compressed
+// together (with usual dictionary building and reduction) and effective dictionary of the result is taken
+func TrainDictionary(ctx context.Context, logPrefix, tmpDir string, samples []string, minPatternScore uint64, workers int, lvl log.Lvl) (*Dictionary, error) {
+	trainDir, err := os.MkdirTemp(tmpDir, "dict-train")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(trainDir)
+	trainFile := filepath.Join(trainDir, "samples.seg")
+	c, err := NewCompressor(ctx, logPrefix, trainFile, trainDir, minPatternScore, workers, lvl)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	for _, sample := range samples {
+		if err = addSampleWords(c, sample); err != nil {
+			return nil, err
+		}
+	}
+	if err = c.Compress(); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(trainFile)
+	if err != nil {
+		return nil, err
+	}
+	patternsSize := binary.BigEndian.Uint64(data[16:24])
+	depths, patterns, _, err := readPatterns(data[24 : 24+patternsSize])
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no patterns found in %d samples", len(samples))
+	}
+	return NewDictionary(depths, patterns)
+}
+
+func addSampleWords(c *Compressor, sample string) error {
+	d, err := NewDecompressor(sample)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	g := d.MakeGetter()
+	var w []byte
+	for g.HasNext() {
+		w, _ = g.Next(w[:0])
+		if err = c.AddWord(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DictionaryRegistry - dictionaries available to decompressors by ID
+type DictionaryRegistry struct {
+	lock  sync.RWMutex
+	dicts map[uint64]*Dictionary
+}
+
+func NewDictionaryRegistry(dicts ...*Dictionary) *DictionaryRegistry {
+	r := &DictionaryRegistry{dicts: map[uint64]*Dictionary{}}
+	for _, d := range dicts {
+		r.Register(d)
+	}
+	return r
+}
+
+// DefaultDictionaries - registry used by NewDecompressor
+var DefaultDictionaries = NewDictionaryRegistry()
+
+func (r *DictionaryRegistry) Register(d *Dictionary) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.dicts[d.id] = d
+}
+
+// RegisterFile loads dictionary persisted by Dictionary.Persist and registers it
+func (r *DictionaryRegistry) RegisterFile(fileName string) (*Dictionary, error) {
+	d, err := LoadDictionary(fileName)
+	if err != nil {
+		return nil, err
+	}
+	r.Register(d)
+	return d, nil
+}
+
+func (r *DictionaryRegistry) Get(id uint64) (*Dictionary, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	d, ok := r.dicts[id]
+	return d, ok
+}
@@ -0,0 +1,144 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func compressWithDictionary(t *testing.T, file string, words [][]byte, dict *Dictionary) {
+	t.Helper()
+	c, err := NewCompressor(context.Background(), t.Name(), file, t.TempDir(), 1, 2, log.LvlDebug)
+	require.NoError(t, err)
+	defer c.Close()
+	c.SetDictionary(dict)
+	for i, w := range words {
+		if i%5 == 4 {
+			require.NoError(t, c.AddUncompressedWord(w))
+		} else {
+			require.NoError(t, c.AddWord(w))
+		}
+	}
+	require.NoError(t, c.Compress())
+}
+
+func TestSharedDictionary(t *testing.T) {
+	tmpDir := t.TempDir()
+	var samples []string
+	for i := 0; i < 2; i++ {
+		sample := filepath.Join(tmpDir, fmt.Sprintf("sample%d", i))
+		prepareWordOffsetsFile(t, sample, loremWords(), false)
+		samples = append(samples, sample)
+	}
+	dict, err := TrainDictionary(context.Background(), t.Name(), tmpDir, samples, 1, 2, log.LvlDebug)
+	require.NoError(t, err)
+	require.Greater(t, dict.Count(), 0)
+
+	dictFile := filepath.Join(tmpDir, "lorem"+DictionaryFileExt)
+	require.NoError(t, dict.Persist(dictFile))
+	dicts := NewDictionaryRegistry()
+	loaded, err := dicts.RegisterFile(dictFile)
+	require.NoError(t, err)
+	require.Equal(t, dict.ID(), loaded.ID())
+
+	// small file, similar to the samples
+	var words [][]byte
+	for k, w := range loremStrings {
+		if k%11 == 0 {
+			words = append(words, nil)
+			continue
+		}
+		words = append(words, []byte(fmt.Sprintf("%s %d", w, k)))
+	}
+	withDict := filepath.Join(tmpDir, "with-dict")
+	compressWithDictionary(t, withDict, words, dict)
+	withoutDict := filepath.Join(tmpDir, "without-dict")
+	prepareWordOffsetsFile(t, withoutDict, words, false)
+
+	d, err := NewDecompressorWithDictionaries(withDict, dicts)
+	require.NoError(t, err)
+	defer d.Close()
+	id, external := d.DictionaryID()
+	require.True(t, external)
+	require.Equal(t, dict.ID(), id)
+	require.Equal(t, len(words), d.Count())
+	g := d.MakeGetter()
+	for i := 0; g.HasNext(); i++ {
+		if i%2 == 0 {
+			require.True(t, g.MatchPrefix(words[i]), i)
+		}
+		word, _ := g.Next(nil)
+		require.Equal(t, string(words[i]), string(word), i)
+	}
+
+	// patterns of shared dictionary are not stored in the file
+	withStat, err := os.Stat(withDict)
+	require.NoError(t, err)
+	withoutStat, err := os.Stat(withoutDict)
+	require.NoError(t, err)
+	require.Less(t, withStat.Size(), withoutStat.Size())
+
+	// dictionary is resolved only through registry
+	_, err = NewDecompressor(withDict)
+	require.ErrorIs(t, err, ErrUnknownDictionary)
+
+	plain, err := NewDecompressor(withoutDict)
+	require.NoError(t, err)
+	defer plain.Close()
+	_, external = plain.DictionaryID()
+	require.False(t, external)
+}
+
+func TestSharedDictionaryDeepCodes(t *testing.T) {
+	// skewed huffman tree: codes longer than 9 bits are decoded via nested tables
+	var depths []uint64
+	var patterns [][]byte
+	for i := 1; i <= 14; i++ {
+		depths = append(depths, uint64(i))
+		patterns = append(patterns, []byte(fmt.Sprintf("pattern-%02d", i)))
+	}
+	depths = append(depths, 14)
+	patterns = append(patterns, []byte("pattern-last"))
+	dict, err := NewDictionary(depths, patterns)
+	require.NoError(t, err)
+	_, err = NewDictionary(depths[:len(depths)-1], patterns[:len(patterns)-1])
+	require.NoError(t, err)
+	_, err = NewDictionary([]uint64{1, 1, 1}, [][]byte{[]byte("aaaaa"), []byte("bbbbb"), []byte("ccccc")})
+	require.Error(t, err)
+
+	var words [][]byte
+	for i := 0; i < 200; i++ {
+		words = append(words, []byte(fmt.Sprintf("%s %d %s", patterns[i%len(patterns)], i, patterns[(i*7)%len(patterns)])))
+	}
+	file := filepath.Join(t.TempDir(), "compressed")
+	compressWithDictionary(t, file, words, dict)
+	d, err := NewDecompressorWithDictionaries(file, NewDictionaryRegistry(dict))
+	require.NoError(t, err)
+	defer d.Close()
+	g := d.MakeGetter()
+	for i := 0; g.HasNext(); i++ {
+		word, _ := g.Next(nil)
+		require.Equal(t, string(words[i]), string(word), i)
+	}
+}
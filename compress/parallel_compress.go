@@ -238,25 +238,40 @@ func (cq *CompressionQueue) Pop() interface{} {
 }
 
 // reduceDict reduces the dictionary by trying the substitutions and counting frequency for each word
-func reducedict(ctx context.Context, trace bool, logPrefix, segmentFilePath string, datFile *DecompressedFile, workers int, dictBuilder *DictionaryBuilder, lvl log.Lvl) error {
+// If shared dictionary is given, it's patterns and codes are used as is and dictBuilder is ignored
+func reducedict(ctx context.Context, trace bool, logPrefix, segmentFilePath string, datFile *DecompressedFile, workers int, dictBuilder *DictionaryBuilder, dict *Dictionary, lvl log.Lvl) error {
 	logEvery := time.NewTicker(60 * time.Second)
 	defer logEvery.Stop()
 
 	// DictionaryBuilder is for sorting words by their freuency (to assign codes)
 	var pt patricia.PatriciaTree
 	code2pattern := make([]*Pattern, 0, 256)
-	dictBuilder.ForEach(func(score uint64, word []byte) {
-		p := &Pattern{
-			score:    score,
-			uses:     0,
-			code:     uint64(len(code2pattern)),
-			codeBits: 0,
-			word:     word,
-		}
-		pt.Insert(word, p)
-		code2pattern = append(code2pattern, p)
-	})
-	dictBuilder.Close()
+	if dict != nil {
+		for i, word := range dict.patterns {
+			p := &Pattern{
+				score:    64 - dict.depths[i], // prefer patterns with shorter codes
+				uses:     0,
+				code:     uint64(len(code2pattern)),
+				codeBits: 0,
+				word:     word,
+			}
+			pt.Insert(word, p)
+			code2pattern = append(code2pattern, p)
+		}
+	} else {
+		dictBuilder.ForEach(func(score uint64, word []byte) {
+			p := &Pattern{
+				score:    score,
+				uses:     0,
+				code:     uint64(len(code2pattern)),
+				codeBits: 0,
+				word:     word,
+			}
+			pt.Insert(word, p)
+			code2pattern = append(code2pattern, p)
+		})
+		dictBuilder.Close()
+	}
 	if lvl < log.LvlTrace {
 		log.Log(lvl, fmt.Sprintf("[%s] dictionary file parsed", logPrefix), "entries", len(code2pattern))
 	}
@@ -474,50 +489,60 @@ func reducedict(ctx context.Context, trace bool, logPrefix, segmentFilePath stri
 	logCtx = append(logCtx, "patternList.Len", patternList.Len())
 
 	i := 0
-	// Build Huffman tree for codes
-	var codeHeap PatternHeap
-	heap.Init(&codeHeap)
-	tieBreaker := uint64(0)
-	for codeHeap.Len()+(patternList.Len()-i) > 1 {
-		// New node
-		h := &PatternHuff{
-			tieBreaker: tieBreaker,
-		}
-		if codeHeap.Len() > 0 && (i >= patternList.Len() || codeHeap[0].uses < patternList[i].uses) {
-			// Take h0 from the heap
-			h.h0 = heap.Pop(&codeHeap).(*PatternHuff)
-			h.h0.AddZero()
-			h.uses += h.h0.uses
-		} else {
-			// Take p0 from the list
-			h.p0 = patternList[i]
-			h.p0.code = 0
-			h.p0.codeBits = 1
-			h.uses += h.p0.uses
-			i++
+	if dict != nil {
+		// codes are fixed by the shared dictionary
+		for j, p := range code2pattern {
+			p.code, p.codeBits = dict.codes[j], dict.codeBits[j]
+		}
+	} else {
+		// Build Huffman tree for codes
+		var codeHeap PatternHeap
+		heap.Init(&codeHeap)
+		tieBreaker := uint64(0)
+		for codeHeap.Len()+(patternList.Len()-i) > 1 {
+			// New node
+			h := &PatternHuff{
+				tieBreaker: tieBreaker,
+			}
+			if codeHeap.Len() > 0 && (i >= patternList.Len() || codeHeap[0].uses < patternList[i].uses) {
+				// Take h0 from the heap
+				h.h0 = heap.Pop(&codeHeap).(*PatternHuff)
+				h.h0.AddZero()
+				h.uses += h.h0.uses
+			} else {
+				// Take p0 from the list
+				h.p0 = patternList[i]
+				h.p0.code = 0
+				h.p0.codeBits = 1
+				h.uses += h.p0.uses
+				i++
+			}
+			if codeHeap.Len() > 0 && (i >= patternList.Len() || codeHeap[0].uses < patternList[i].uses) {
+				// Take h1 from the heap
+				h.h1 = heap.Pop(&codeHeap).(*PatternHuff)
+				h.h1.AddOne()
+				h.uses += h.h1.uses
+			} else {
+				// Take p1 from the list
+				h.p1 = patternList[i]
+				h.p1.code = 1
+				h.p1.codeBits = 1
+				h.uses += h.p1.uses
+				i++
+			}
+			tieBreaker++
+			heap.Push(&codeHeap, h)
 		}
-		if codeHeap.Len() > 0 && (i >= patternList.Len() || codeHeap[0].uses < patternList[i].uses) {
-			// Take h1 from the heap
-			h.h1 = heap.Pop(&codeHeap).(*PatternHuff)
-			h.h1.AddOne()
-			h.uses += h.h1.uses
-		} else {
-			// Take p1 from the list
-			h.p1 = patternList[i]
-			h.p1.code = 1
-			h.p1.codeBits = 1
-			h.uses += h.p1.uses
-			i++
+		if codeHeap.Len() > 0 {
+			root := heap.Pop(&codeHeap).(*PatternHuff)
+			root.SetDepth(0)
 		}
-		tieBreaker++
-		heap.Push(&codeHeap, h)
-	}
-	if codeHeap.Len() > 0 {
-		root := heap.Pop(&codeHeap).(*PatternHuff)
-		root.SetDepth(0)
 	}
 	// Calculate total size of the dictionary
 	var patternsSize uint64
+	if dict != nil {
+		patternList = patternList[:0] // patterns are not written into the file
+	}
 	for _, p := range patternList {
 		ns := binary.PutUvarint(numBuf[:], uint64(p.depth))    // Length of the word's depth
 		n := binary.PutUvarint(numBuf[:], uint64(len(p.word))) // Length of the word's length
@@ -548,8 +573,12 @@ func reducedict(ctx context.Context, trace bool, logPrefix, segmentFilePath stri
 	if _, err = cw.Write(numBuf[:8]); err != nil {
 		return err
 	}
-	// 2-nd, output dictionary size
-	binary.BigEndian.PutUint64(numBuf[:], patternsSize) // Dictionary size
+	// 2-nd, output dictionary size (or ID of shared dictionary)
+	if dict != nil {
+		binary.BigEndian.PutUint64(numBuf[:], externalDictionaryFlag|dict.id)
+	} else {
+		binary.BigEndian.PutUint64(numBuf[:], patternsSize) // Dictionary size
+	}
 	if _, err = cw.Write(numBuf[:8]); err != nil {
 		return err
 	}
@@ -583,7 +612,7 @@ func reducedict(ctx context.Context, trace bool, logPrefix, segmentFilePath stri
 	// Build Huffman tree for codes
 	var posHeap PositionHeap
 	heap.Init(&posHeap)
-	tieBreaker = uint64(0)
+	tieBreaker := uint64(0)
 	for posHeap.Len()+(positionList.Len()-i) > 1 {
 		// New node
 		h := &PositionHuff{
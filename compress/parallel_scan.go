@@ -0,0 +1,178 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ScanFunc receives i-th word of the file and offset of it's beginning (can be passed to Getter.Reset).
+// word is valid only until ScanFunc returns
+type ScanFunc func(i int, offset uint64, word []byte) error
+
+// scanChunkWords - amount of words decompressed by worker at once
+const scanChunkWords = 4096
+
+// scanChunk - range of consecutive words, decompressed by one worker
+type scanChunk struct {
+	seq       int
+	firstWord int
+	count     int
+	offset    uint64 // offset of the first word
+
+	words   []byte   // decompressed words, one after another
+	ends    []int    // end of each word in words
+	offsets []uint64 // offset of each word in the file
+}
+
+func (c *scanChunk) word(j int) []byte {
+	var start int
+	if j > 0 {
+		start = c.ends[j-1]
+	}
+	return c.words[start:c.ends[j]]
+}
+
+// ParallelScan decompresses all words of the file on given amount of workers and passes them to f.
+// If ordered - words are passed in the order of the file, otherwise in the order chunks of words are
+// decompressed (words of chunk are still passed in order). f is always called from the goroutine of
+// ParallelScan, so it doesn't need synchronisation. Amount of decompressed, but not yet consumed
+// chunks is limited, so slow f doesn't make memory usage grow.
+// File is split into chunks by the word offsets sidecar if it's available, otherwise by skipping over
+// the words on a separate goroutine. First error of f or cancellation of ctx stops the scan.
+func (d *Decompressor) ParallelScan(ctx context.Context, workers int, ordered bool, f ScanFunc) error {
+	if workers < 1 {
+		workers = 1
+	}
+	scanCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	g, scanCtx := errgroup.WithContext(scanCtx)
+
+	inFlight := make(chan struct{}, 2*workers) // chunks which are produced, but not consumed yet
+	chunks := make(chan *scanChunk, workers)
+	results := make(chan *scanChunk, 2*workers)
+	pool := sync.Pool{New: func() interface{} { return &scanChunk{} }}
+
+	g.Go(func() error {
+		defer close(chunks)
+		return d.splitScan(scanCtx, inFlight, chunks, &pool)
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		g.Go(func() error {
+			defer wg.Done()
+			getter := d.MakeGetter()
+			var buf []byte
+			for c := range chunks {
+				getter.Reset(c.offset)
+				for j := 0; j < c.count; j++ {
+					c.offsets = append(c.offsets, getter.offset())
+					// Next doesn't support appending of compressed word to non-empty buf
+					buf, _ = getter.Next(buf[:0])
+					c.words = append(c.words, buf...)
+					c.ends = append(c.ends, len(c.words))
+				}
+				select {
+				case results <- c:
+				case <-scanCtx.Done():
+					return scanCtx.Err()
+				}
+			}
+			return nil
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var err error
+	consume := func(c *scanChunk) {
+		for j := 0; j < c.count && err == nil; j++ {
+			err = f(c.firstWord+j, c.offsets[j], c.word(j))
+		}
+		if err != nil {
+			cancel()
+		}
+		c.words, c.ends, c.offsets = c.words[:0], c.ends[:0], c.offsets[:0]
+		pool.Put(c)
+		<-inFlight
+	}
+	pending := map[int]*scanChunk{}
+	var next int
+	for c := range results { // read until closed, even after error, so workers can exit
+		if err != nil || scanCtx.Err() != nil {
+			continue
+		}
+		if !ordered {
+			consume(c)
+			continue
+		}
+		pending[c.seq] = c
+		for c, ok := pending[next]; ok && err == nil; c, ok = pending[next] {
+			delete(pending, next)
+			consume(c)
+			next++
+		}
+	}
+	gErr := g.Wait()
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil { // chunks may be skipped
+		return ctx.Err()
+	}
+	return gErr
+}
+
+// splitScan sends chunks of words to workers, it waits for a free slot in inFlight before sending each chunk
+func (d *Decompressor) splitScan(ctx context.Context, inFlight chan struct{}, chunks chan *scanChunk, pool *sync.Pool) error {
+	getter := d.MakeGetter()
+	count := d.Count()
+	for seq, first := 0, 0; first < count; seq++ {
+		c := pool.Get().(*scanChunk)
+		c.seq, c.firstWord, c.count = seq, first, scanChunkWords
+		if rest := count - first; rest < c.count {
+			c.count = rest
+		}
+		if d.wordOffsets != nil {
+			c.offset, _ = d.WordOffset(first)
+		} else {
+			c.offset = getter.offset()
+			for j := 0; j < c.count; j++ {
+				getter.Skip()
+			}
+		}
+		first += c.count
+
+		select {
+		case inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		select {
+		case chunks <- c:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelScan(t *testing.T) {
+	words := codecWords(3*scanChunkWords + 100)
+	for _, codec := range []Codec{CodecPattern, CodecZstd, CodecRaw} {
+		codec := codec
+		t.Run(codec.String(), func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "compressed")
+			d := prepareCodecFile(t, file, codec, words)
+			var offsets []uint64
+			g := d.MakeGetter()
+			for g.HasNext() {
+				offsets = append(offsets, g.dataP)
+				g.Skip()
+			}
+			d.Close()
+
+			check := func(t *testing.T, d *Decompressor, workers int, ordered bool) {
+				seen := make([]bool, len(words))
+				next := 0
+				require.NoError(t, d.ParallelScan(context.Background(), workers, ordered, func(i int, offset uint64, word []byte) error {
+					if ordered {
+						require.Equal(t, next, i)
+					}
+					next++
+					require.False(t, seen[i])
+					seen[i] = true
+					require.Equal(t, offsets[i], offset)
+					require.Equal(t, string(words[i]), string(word))
+					return nil
+				}))
+				require.Equal(t, len(words), next)
+			}
+			for _, withOffsets := range []bool{true, false} {
+				if !withOffsets {
+					require.NoError(t, os.Remove(WordOffsetsPath(file)))
+				}
+				d, err := NewDecompressor(file)
+				require.NoError(t, err)
+				require.Equal(t, withOffsets, d.HasWordOffsets())
+				for _, workers := range []int{1, 4} {
+					check(t, d, workers, true)
+					check(t, d, workers, false)
+				}
+				d.Close()
+			}
+		})
+	}
+}
+
+func TestParallelScanStop(t *testing.T) {
+	words := codecWords(10 * scanChunkWords)
+	d := prepareCodecFile(t, filepath.Join(t.TempDir(), "compressed"), CodecPattern, words)
+	defer d.Close()
+
+	errStop := errors.New("stop")
+	var calls int
+	err := d.ParallelScan(context.Background(), 4, true, func(i int, offset uint64, word []byte) error {
+		calls++
+		if i == scanChunkWords+1 {
+			return errStop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, scanChunkWords+2, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = d.ParallelScan(ctx, 4, false, func(i int, offset uint64, word []byte) error {
+		calls++
+		if calls == 10 {
+			cancel()
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, calls, len(words))
+
+	// nothing to scan
+	empty := prepareCodecFile(t, filepath.Join(t.TempDir(), "empty"), CodecPattern, nil)
+	defer empty.Close()
+	require.NoError(t, empty.ParallelScan(context.Background(), 4, true, func(i int, offset uint64, word []byte) error {
+		return errStop
+	}))
+}
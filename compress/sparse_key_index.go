@@ -0,0 +1,208 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Sparse key index allows to seek by key in compressed file of sorted key/value pairs (words go as key, value, key, value...)
+// without external index: it keeps first key of every `step` pairs with it's offset. Seek does binary search over
+// these keys and then linear scan over at most `step` pairs. It's stored next to the compressed file and has format:
+//   - words count (8 bytes)
+//   - size of words data of compressed file (8 bytes)
+//   - step (8 bytes)
+//   - samples count (8 bytes)
+//   - for each sample: offset of the key word (8 bytes), key length (uvarint), key
+
+// SparseKeyIndexFileExt - extension appended to the compressed file name to get path of it's sparse key index
+const SparseKeyIndexFileExt = ".ski"
+
+// DefaultSparseKeyIndexStep - amount of key/value pairs covered by one sample of sparse key index
+const DefaultSparseKeyIndexStep = 64
+
+const sparseKeyIndexHeaderSize = 32
+
+func SparseKeyIndexPath(compressedFilePath string) string {
+	return compressedFilePath + SparseKeyIndexFileExt
+}
+
+// ErrNoSparseKeyIndex - returned by Getter.SeekGE if decompressor was opened without sparse key index
+var ErrNoSparseKeyIndex = errors.New("sparse key index is not available")
+
+type sparseKeyIndex struct {
+	keys    [][]byte
+	offsets []uint64
+	step    int
+}
+
+// BuildSparseKeyIndex scans compressed file of sorted key/value pairs and writes it's sparse key index, replacing existing one
+func BuildSparseKeyIndex(ctx context.Context, compressedFilePath string, step int) error {
+	return buildSparseKeyIndex(ctx, compressedFilePath, step, DefaultDictionaries)
+}
+
+func buildSparseKeyIndex(ctx context.Context, compressedFilePath string, step int, dicts *DictionaryRegistry) error {
+	if step <= 0 {
+		return fmt.Errorf("build sparse key index %s: invalid step %d", compressedFilePath, step)
+	}
+	d, err := newDecompressor(compressedFilePath, dicts, false)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if d.wordsCount%2 != 0 {
+		return fmt.Errorf("build sparse key index %s: odd amount of words %d, expected key/value pairs", d.fileName, d.wordsCount)
+	}
+
+	var buf bytes.Buffer
+	var numBuf [binary.MaxVarintLen64]byte
+	var key, prevKey []byte
+	var offset, pairs, samples uint64
+	g := d.MakeGetter()
+	for g.HasNext() {
+		if pairs%1_000_000 == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+		key, _ = g.Next(key[:0])
+		if pairs > 0 && bytes.Compare(prevKey, key) > 0 {
+			return fmt.Errorf("build sparse key index %s: keys are not sorted at pair %d", d.fileName, pairs)
+		}
+		if pairs%uint64(step) == 0 {
+			binary.BigEndian.PutUint64(numBuf[:8], offset)
+			buf.Write(numBuf[:8])
+			n := binary.PutUvarint(numBuf[:], uint64(len(key)))
+			buf.Write(numBuf[:n])
+			buf.Write(key)
+			samples++
+		}
+		prevKey = append(prevKey[:0], key...)
+		offset = g.Skip()
+		pairs++
+	}
+
+	path := SparseKeyIndexPath(compressedFilePath)
+	tmpPath := path + ".tmp"
+	defer os.Remove(tmpPath)
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	var header [sparseKeyIndexHeaderSize]byte
+	binary.BigEndian.PutUint64(header[:8], d.wordsCount)
+	binary.BigEndian.PutUint64(header[8:16], offset)
+	binary.BigEndian.PutUint64(header[16:24], uint64(step))
+	binary.BigEndian.PutUint64(header[24:], samples)
+	if _, err = w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err = w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// openSparseKeyIndex reads sparse key index if it exists and checks that it describes words of the decompressor
+func (d *Decompressor) openSparseKeyIndex() error {
+	path := SparseKeyIndexPath(d.filePath)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if len(data) < sparseKeyIndexHeaderSize {
+		return fmt.Errorf("sparse key index %s: too short %d", path, len(data))
+	}
+	words, size := binary.BigEndian.Uint64(data[:8]), binary.BigEndian.Uint64(data[8:16])
+	if words != d.wordsCount || size != uint64(len(d.data))-d.wordsStart {
+		return fmt.Errorf("sparse key index %s: built for %d words of %d bytes, file has %d words of %d bytes", path, words, size, d.wordsCount, uint64(len(d.data))-d.wordsStart)
+	}
+	samples := binary.BigEndian.Uint64(data[24:32])
+	idx := &sparseKeyIndex{keys: make([][]byte, 0, samples), offsets: make([]uint64, 0, samples), step: int(binary.BigEndian.Uint64(data[16:24]))}
+	data = data[sparseKeyIndexHeaderSize:]
+	for i := uint64(0); i < samples; i++ {
+		if len(data) < 8 {
+			return fmt.Errorf("sparse key index %s: truncated at sample %d", path, i)
+		}
+		offset := binary.BigEndian.Uint64(data[:8])
+		l, n := binary.Uvarint(data[8:])
+		if n <= 0 || uint64(len(data)-8-n) < l || offset >= size {
+			return fmt.Errorf("sparse key index %s: invalid sample %d", path, i)
+		}
+		idx.offsets = append(idx.offsets, offset)
+		idx.keys = append(idx.keys, data[8+n:8+n+int(l)])
+		data = data[8+n+int(l):]
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("sparse key index %s: %d trailing bytes", path, len(data))
+	}
+	d.keyIndex = idx
+	return nil
+}
+
+func (d *Decompressor) HasSparseKeyIndex() bool { return d.keyIndex != nil }
+
+// SeekGE positions getter at the first key which is greater or equal to seek in the file of sorted key/value pairs,
+// and returns this key and it's value. Getter continues from the next pair. ok=false if all keys are less than seek.
+// Requires sparse key index, see BuildSparseKeyIndex
+func (g *Getter) SeekGE(seek []byte) (key, value []byte, ok bool, err error) {
+	idx := g.keyIndex
+	if idx == nil {
+		return nil, nil, false, fmt.Errorf("%s: %w", g.fName, ErrNoSparseKeyIndex)
+	}
+	if len(idx.keys) == 0 {
+		return nil, nil, false, nil
+	}
+	// last sample with key <= seek, pairs before it have smaller keys
+	i := sort.Search(len(idx.keys), func(i int) bool { return bytes.Compare(idx.keys[i], seek) > 0 })
+	if i > 0 {
+		i--
+	}
+	g.Reset(idx.offsets[i])
+	for g.HasNext() {
+		key, _ = g.Next(key[:0])
+		if bytes.Compare(key, seek) >= 0 {
+			value, _ = g.Next(nil)
+			return key, value, true, nil
+		}
+		g.Skip()
+	}
+	return nil, nil, false, nil
+}
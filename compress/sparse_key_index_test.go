@@ -0,0 +1,101 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeekGE(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "compressed")
+	c, err := NewCompressor(context.Background(), t.Name(), file, t.TempDir(), 1, 2, log.LvlDebug)
+	require.NoError(t, err)
+	defer c.Close()
+	c.SetSparseKeyIndex(4)
+	var keys []string
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key %04d", i*2)
+		keys = append(keys, key)
+		require.NoError(t, c.AddWord([]byte(key)))
+		if i%3 == 0 {
+			require.NoError(t, c.AddUncompressedWord(nil))
+		} else {
+			require.NoError(t, c.AddWord([]byte(fmt.Sprintf("value %d", i))))
+		}
+	}
+	require.NoError(t, c.Compress())
+
+	d, err := NewDecompressor(file)
+	require.NoError(t, err)
+	defer d.Close()
+	require.True(t, d.HasSparseKeyIndex())
+	g := d.MakeGetter()
+	for i := 0; i < 199; i++ {
+		seek := fmt.Sprintf("key %04d", i)
+		key, value, ok, err := g.SeekGE([]byte(seek))
+		require.NoError(t, err)
+		require.True(t, ok, seek)
+		require.Equal(t, keys[(i+1)/2], string(key))
+		if (i+1)/2%3 == 0 {
+			require.Empty(t, value)
+		} else {
+			require.Equal(t, fmt.Sprintf("value %d", (i+1)/2), string(value))
+		}
+		// getter continues from the next pair
+		if (i+1)/2+1 < len(keys) {
+			next, _ := g.Next(nil)
+			require.Equal(t, keys[(i+1)/2+1], string(next))
+		}
+	}
+	key, _, ok, err := g.SeekGE(nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, keys[0], string(key))
+	_, _, ok, err = g.SeekGE([]byte("key 9999"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// unsorted keys
+	c, err = NewCompressor(context.Background(), t.Name(), file, t.TempDir(), 1, 2, log.LvlDebug)
+	require.NoError(t, err)
+	defer c.Close()
+	for _, w := range []string{"b", "1", "a", "2"} {
+		require.NoError(t, c.AddWord([]byte(w)))
+	}
+	require.NoError(t, c.Compress())
+	require.Error(t, BuildSparseKeyIndex(context.Background(), file, 1))
+	d2, err := NewDecompressor(file)
+	require.NoError(t, err)
+	defer d2.Close()
+	require.False(t, d2.HasSparseKeyIndex())
+	_, _, _, err = d2.MakeGetter().SeekGE([]byte("a"))
+	require.ErrorIs(t, err, ErrNoSparseKeyIndex)
+
+	// corrupt index is ignored
+	require.NoError(t, os.WriteFile(SparseKeyIndexPath(file), []byte("corrupt"), 0644))
+	d3, err := NewDecompressor(file)
+	require.NoError(t, err)
+	defer d3.Close()
+	require.False(t, d3.HasSparseKeyIndex())
+}
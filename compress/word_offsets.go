@@ -0,0 +1,186 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gateway-fm/cdk-erigon-lib/recsplit/eliasfano32"
+)
+
+// Word offsets sidecar allows to access words of compressed file by their index without external index.
+// It's stored next to the compressed file and has format:
+//   - words count (8 bytes)
+//   - size of words data of compressed file (8 bytes)
+//   - Elias-Fano list of words count + 1 offsets: start offset of each word and the end of words data
+
+// WordOffsetsFileExt - extension appended to the compressed file name to get path of it's word offsets sidecar
+const WordOffsetsFileExt = ".wo"
+
+const wordOffsetsHeaderSize = 16
+
+func WordOffsetsPath(compressedFilePath string) string {
+	return compressedFilePath + WordOffsetsFileExt
+}
+
+// ErrNoWordOffsets - returned by random access methods of Decompressor opened without word offsets sidecar
+var ErrNoWordOffsets = errors.New("word offsets are not available")
+
+// BuildWordOffsets scans compressed file and writes it's word offsets sidecar, replacing existing one
+func BuildWordOffsets(ctx context.Context, compressedFilePath string) error {
+	return buildWordOffsets(ctx, compressedFilePath, DefaultDictionaries)
+}
+
+func buildWordOffsets(ctx context.Context, compressedFilePath string, dicts *DictionaryRegistry) error {
+	d, err := newDecompressor(compressedFilePath, dicts, false)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	ef, err := collectWordOffsets(ctx, d)
+	if err != nil {
+		return err
+	}
+	words, offset := d.wordsCount, ef.Max()
+
+	path := WordOffsetsPath(compressedFilePath)
+	tmpPath := path + ".tmp"
+	defer os.Remove(tmpPath)
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	var header [wordOffsetsHeaderSize]byte
+	binary.BigEndian.PutUint64(header[:8], words)
+	binary.BigEndian.PutUint64(header[8:], offset)
+	if _, err = w.Write(header[:]); err != nil {
+		return err
+	}
+	if err = ef.Write(w); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// collectWordOffsets skips over words of the file itself (without tail segment) and returns their offsets
+func collectWordOffsets(ctx context.Context, d *Decompressor) (*eliasfano32.EliasFano, error) {
+	g := d.makeGetter()
+	ef := eliasfano32.NewEliasFano(d.wordsCount+1, uint64(len(g.data)))
+	var offset, words uint64
+	for g.HasNext() {
+		if words%1_000_000 == 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			default:
+			}
+		}
+		ef.AddOffset(offset)
+		offset = g.skip()
+		words++
+	}
+	if words != d.wordsCount {
+		return nil, fmt.Errorf("build word offsets %s: found %d words, expected %d", d.fileName, words, d.wordsCount)
+	}
+	ef.AddOffset(offset)
+	ef.Build()
+	return ef, nil
+}
+
+// openWordOffsets reads sidecar if it exists and checks that it describes words of the decompressor
+func (d *Decompressor) openWordOffsets() (err error) {
+	path := WordOffsetsPath(d.filePath)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if len(data) < wordOffsetsHeaderSize+16 {
+		return fmt.Errorf("word offsets %s: too short %d", path, len(data))
+	}
+	words, size := binary.BigEndian.Uint64(data[:8]), binary.BigEndian.Uint64(data[8:16])
+	if words != d.wordsCount || size != uint64(len(d.data))-d.wordsStart {
+		return fmt.Errorf("word offsets %s: built for %d words of %d bytes, file has %d words of %d bytes", path, words, size, d.wordsCount, uint64(len(d.data))-d.wordsStart)
+	}
+	efData := data[wordOffsetsHeaderSize:]
+	if eliasfano32.Count(efData) != words+1 || eliasfano32.Max(efData) != size {
+		return fmt.Errorf("word offsets %s: invalid offsets list", path)
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("word offsets %s: %v", path, rec)
+		}
+	}()
+	d.wordOffsets, _ = eliasfano32.ReadEliasFano(efData)
+	return nil
+}
+
+func (d *Decompressor) HasWordOffsets() bool { return d.wordOffsets != nil }
+
+// WordOffset returns offset of i-th word, which can be passed to Getter.Reset. Offsets of tail segment words
+// are collected when the tail is opened
+func (d *Decompressor) WordOffset(i int) (uint64, error) {
+	if d.wordOffsets == nil {
+		return 0, fmt.Errorf("%s: %w", d.fileName, ErrNoWordOffsets)
+	}
+	if i < 0 || i >= d.Count() {
+		return 0, fmt.Errorf("%s: word %d is out of range [0, %d)", d.fileName, i, d.Count())
+	}
+	if uint64(i) >= d.wordsCount {
+		return d.tailBase() + d.tail.wordOffsets.Get(uint64(i)-d.wordsCount), nil
+	}
+	return d.wordOffsets.Get(uint64(i)), nil
+}
+
+// MakeGetterAt creates getter positioned at the beginning of i-th word
+func (d *Decompressor) MakeGetterAt(i int) (*Getter, error) {
+	offset, err := d.WordOffset(i)
+	if err != nil {
+		return nil, err
+	}
+	g := d.MakeGetter()
+	g.Reset(offset)
+	return g, nil
+}
+
+// WordAt decompresses i-th word and appends it to buf
+func (d *Decompressor) WordAt(i int, buf []byte) ([]byte, error) {
+	g, err := d.MakeGetterAt(i)
+	if err != nil {
+		return nil, err
+	}
+	buf, _ = g.Next(buf)
+	return buf, nil
+}
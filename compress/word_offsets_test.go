@@ -0,0 +1,155 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compress
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareWordOffsetsFile(t *testing.T, file string, words [][]byte, wordOffsets bool) {
+	t.Helper()
+	c, err := NewCompressor(context.Background(), t.Name(), file, t.TempDir(), 1, 2, log.LvlDebug)
+	require.NoError(t, err)
+	defer c.Close()
+	c.SetWordOffsets(wordOffsets)
+	for i, w := range words {
+		if i%5 == 4 {
+			require.NoError(t, c.AddUncompressedWord(w))
+		} else {
+			require.NoError(t, c.AddWord(w))
+		}
+	}
+	require.NoError(t, c.Compress())
+}
+
+func loremWords() [][]byte {
+	var words [][]byte
+	for i := 0; i < 10; i++ {
+		for k, w := range loremStrings {
+			if k%7 == 0 {
+				words = append(words, nil)
+				continue
+			}
+			words = append(words, []byte(fmt.Sprintf("%s %d", w, i)))
+		}
+	}
+	return words
+}
+
+func TestWordAt(t *testing.T) {
+	words := loremWords()
+	file := filepath.Join(t.TempDir(), "compressed")
+	prepareWordOffsetsFile(t, file, words, true)
+
+	d, err := NewDecompressor(file)
+	require.NoError(t, err)
+	defer d.Close()
+	require.True(t, d.HasWordOffsets())
+
+	for _, i := range rand.New(rand.NewSource(0)).Perm(len(words)) {
+		word, err := d.WordAt(i, nil)
+		require.NoError(t, err)
+		require.Equal(t, string(words[i]), string(word), i)
+	}
+
+	// getter continues from the word sequentially
+	g, err := d.MakeGetterAt(len(words) - 20)
+	require.NoError(t, err)
+	for i := len(words) - 20; i < len(words); i++ {
+		require.True(t, g.HasNext())
+		if i%5 == 4 {
+			word, _ := g.NextUncompressed()
+			require.Equal(t, string(words[i]), string(word), i)
+		} else {
+			word, _ := g.Next(nil)
+			require.Equal(t, string(words[i]), string(word), i)
+		}
+	}
+	require.False(t, g.HasNext())
+
+	_, err = d.WordAt(len(words), nil)
+	require.Error(t, err)
+	_, err = d.WordAt(-1, nil)
+	require.Error(t, err)
+}
+
+func TestWordOffsetsSidecar(t *testing.T) {
+	words := loremWords()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "compressed")
+	prepareWordOffsetsFile(t, file, words, false)
+	_, err := os.Stat(WordOffsetsPath(file))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	d, err := NewDecompressor(file)
+	require.NoError(t, err)
+	require.False(t, d.HasWordOffsets())
+	_, err = d.WordAt(0, nil)
+	require.ErrorIs(t, err, ErrNoWordOffsets)
+	d.Close()
+
+	// sidecar can be built for existing file
+	require.NoError(t, BuildWordOffsets(context.Background(), file))
+	d, err = NewDecompressor(file)
+	require.NoError(t, err)
+	word, err := d.WordAt(3, nil)
+	require.NoError(t, err)
+	require.Equal(t, words[3], word)
+	d.Close()
+
+	// sidecar of another file is ignored
+	other := filepath.Join(dir, "other")
+	prepareWordOffsetsFile(t, other, words[:len(words)-1], false)
+	sidecar, err := os.ReadFile(WordOffsetsPath(file))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(WordOffsetsPath(other), sidecar, 0644))
+	d, err = NewDecompressor(other)
+	require.NoError(t, err)
+	require.False(t, d.HasWordOffsets())
+	require.Equal(t, len(words)-1, d.Count())
+	d.Close()
+
+	// corrupt sidecar is ignored
+	require.NoError(t, os.WriteFile(WordOffsetsPath(other), sidecar[:len(sidecar)/2], 0644))
+	d, err = NewDecompressor(other)
+	require.NoError(t, err)
+	require.False(t, d.HasWordOffsets())
+	d.Close()
+
+	// recompression without offsets removes stale sidecar
+	prepareWordOffsetsFile(t, other, words, false)
+	_, err = os.Stat(WordOffsetsPath(other))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// empty file
+	empty := filepath.Join(dir, "empty")
+	prepareWordOffsetsFile(t, empty, nil, true)
+	d, err = NewDecompressor(empty)
+	require.NoError(t, err)
+	defer d.Close()
+	require.True(t, d.HasWordOffsets())
+	_, err = d.WordAt(0, nil)
+	require.Error(t, err)
+}
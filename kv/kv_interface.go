@@ -468,6 +468,18 @@ type (
 	History     string
 	InvertedIdx string
 )
+
+const (
+	AccountsDomain Domain = "AccountsDomain"
+	StorageDomain  Domain = "StorageDomain"
+	CodeDomain     Domain = "CodeDomain"
+
+	// History of commitment roots. k - 8 bytes big-endian number of block (or txNum),
+	// v - 8 bytes big-endian txNum (or number of block) at which commitment was saved, then root hash
+	CommitmentRootByBlockDomain Domain = "CommitmentRootByBlock"
+	CommitmentRootByTxNumDomain Domain = "CommitmentRootByTxNum"
+)
+
 type TemporalRoDB interface {
 	RoDB
 	BeginTemporalRo(ctx context.Context) (TemporalTx, error)
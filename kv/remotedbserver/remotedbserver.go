@@ -19,6 +19,7 @@ package remotedbserver
 import (
 	"context"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -64,7 +65,8 @@ const MaxTxTTL = 60 * time.Second
 // 6.0.0 - Blocks now have system-txs - in the begin/end of block
 // 6.1.0 - Add methods Range, IndexRange, HistoryGet, HistoryRange
 // 6.2.0 - Add HistoryFiles to reply of Snapshots() method
-var KvServiceAPIVersion = &types.VersionReply{Major: 6, Minor: 2, Patch: 0}
+// 6.3.0 - DomainGet serves history of commitment roots
+var KvServiceAPIVersion = &types.VersionReply{Major: 6, Minor: 3, Patch: 0}
 
 type KvServer struct {
 	remote.UnimplementedKVServer // must be embedded to have forward compatible implementations.
@@ -73,6 +75,7 @@ type KvServer struct {
 	stateChangeStreams *StateChangePubSub
 	blockSnapshots     Snapsthots
 	historySnapshots   Snapsthots
+	commitmentRoots    CommitmentRoots
 	ctx                context.Context
 
 	//v3 fields
@@ -93,6 +96,12 @@ type Snapsthots interface {
 	Files() []string
 }
 
+// CommitmentRoots - history of commitment roots, implemented by state.Aggregator
+type CommitmentRoots interface {
+	CommitmentRootByBlock(blockNum uint64, tx kv.Tx) (rootHash []byte, txNum uint64, ok bool, err error)
+	CommitmentRootByTxNum(txNum uint64, tx kv.Tx) (rootHash []byte, blockNum uint64, ok bool, err error)
+}
+
 func NewKvServer(ctx context.Context, db kv.RoDB, snapshots Snapsthots, historySnapshots Snapsthots) *KvServer {
 	return &KvServer{
 		trace:     false,
@@ -103,6 +112,9 @@ func NewKvServer(ctx context.Context, db kv.RoDB, snapshots Snapsthots, historyS
 	}
 }
 
+// SetCommitmentRoots - makes DomainGet serve kv.CommitmentRootByBlockDomain and kv.CommitmentRootByTxNumDomain
+func (s *KvServer) SetCommitmentRoots(roots CommitmentRoots) { s.commitmentRoots = roots }
+
 // Version returns the service-side interface version number
 func (s *KvServer) Version(context.Context, *emptypb.Empty) (*types.VersionReply, error) {
 	dbSchemaVersion := &kv.DBSchemaVersion
@@ -507,6 +519,12 @@ func (s *StateChangePubSub) remove(id uint) {
 
 // Temporal methods
 func (s *KvServer) DomainGet(ctx context.Context, req *remote.DomainGetReq) (reply *remote.DomainGetReply, err error) {
+	if s.commitmentRoots != nil {
+		switch kv.Domain(req.Table) {
+		case kv.CommitmentRootByBlockDomain, kv.CommitmentRootByTxNumDomain:
+			return s.commitmentRootGet(req)
+		}
+	}
 	reply = &remote.DomainGetReply{}
 	if err := s.with(req.TxId, func(tx kv.Tx) error {
 		ttx, ok := tx.(kv.TemporalTx)
@@ -530,6 +548,33 @@ func (s *KvServer) DomainGet(ctx context.Context, req *remote.DomainGetReq) (rep
 	}
 	return reply, nil
 }
+
+func (s *KvServer) commitmentRootGet(req *remote.DomainGetReq) (*remote.DomainGetReply, error) {
+	if len(req.K) != 8 {
+		return nil, fmt.Errorf("%s: expected 8 bytes key, got %d", req.Table, len(req.K))
+	}
+	reply := &remote.DomainGetReply{}
+	if err := s.with(req.TxId, func(tx kv.Tx) (err error) {
+		var rootHash []byte
+		var num uint64
+		if kv.Domain(req.Table) == kv.CommitmentRootByBlockDomain {
+			rootHash, num, reply.Ok, err = s.commitmentRoots.CommitmentRootByBlock(binary.BigEndian.Uint64(req.K), tx)
+		} else {
+			rootHash, num, reply.Ok, err = s.commitmentRoots.CommitmentRootByTxNum(binary.BigEndian.Uint64(req.K), tx)
+		}
+		if err != nil || !reply.Ok {
+			return err
+		}
+		reply.V = make([]byte, 8+len(rootHash))
+		binary.BigEndian.PutUint64(reply.V, num)
+		copy(reply.V[8:], rootHash)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
 func (s *KvServer) HistoryGet(ctx context.Context, req *remote.HistoryGetReq) (reply *remote.HistoryGetReply, err error) {
 	reply = &remote.HistoryGetReply{}
 	if err := s.with(req.TxId, func(tx kv.Tx) error {
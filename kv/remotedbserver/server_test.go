@@ -17,10 +17,13 @@
 package remotedbserver
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"runtime"
 	"testing"
 
+	"github.com/gateway-fm/cdk-erigon-lib/gointerfaces/remote"
 	"github.com/gateway-fm/cdk-erigon-lib/kv"
 	"github.com/gateway-fm/cdk-erigon-lib/kv/memdb"
 	"github.com/stretchr/testify/require"
@@ -94,3 +97,44 @@ func TestKvServer_renew(t *testing.T) {
 	}
 	require.NoError(g.Wait())
 }
+
+type testCommitmentRoots map[uint64][]byte
+
+func (r testCommitmentRoots) CommitmentRootByBlock(blockNum uint64, tx kv.Tx) ([]byte, uint64, bool, error) {
+	root, ok := r[blockNum]
+	return root, blockNum * 10, ok, nil
+}
+
+func (r testCommitmentRoots) CommitmentRootByTxNum(txNum uint64, tx kv.Tx) ([]byte, uint64, bool, error) {
+	root, ok := r[txNum/10]
+	return root, txNum / 10, ok, nil
+}
+
+func TestKvServer_CommitmentRoots(t *testing.T) {
+	require, ctx, db := require.New(t), context.Background(), memdb.NewTestDB(t)
+
+	s := NewKvServer(ctx, db, nil, nil)
+	s.SetCommitmentRoots(testCommitmentRoots{7: bytes.Repeat([]byte{7}, 32)})
+	id, err := s.begin(ctx)
+	require.NoError(err)
+	defer s.rollback(id)
+
+	key := func(n uint64) []byte { return binary.BigEndian.AppendUint64(nil, n) }
+	reply, err := s.DomainGet(ctx, &remote.DomainGetReq{TxId: id, Table: string(kv.CommitmentRootByBlockDomain), K: key(7), Latest: true})
+	require.NoError(err)
+	require.True(reply.Ok)
+	require.Equal(append(key(70), bytes.Repeat([]byte{7}, 32)...), reply.V)
+
+	reply, err = s.DomainGet(ctx, &remote.DomainGetReq{TxId: id, Table: string(kv.CommitmentRootByTxNumDomain), K: key(70), Latest: true})
+	require.NoError(err)
+	require.True(reply.Ok)
+	require.Equal(append(key(7), bytes.Repeat([]byte{7}, 32)...), reply.V)
+
+	reply, err = s.DomainGet(ctx, &remote.DomainGetReq{TxId: id, Table: string(kv.CommitmentRootByBlockDomain), K: key(8), Latest: true})
+	require.NoError(err)
+	require.False(reply.Ok)
+	require.Nil(reply.V)
+
+	_, err = s.DomainGet(ctx, &remote.DomainGetReq{TxId: id, Table: string(kv.CommitmentRootByBlockDomain), K: []byte{7}, Latest: true})
+	require.Error(err)
+}
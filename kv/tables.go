@@ -379,6 +379,10 @@ const (
 	CommitmentHistoryVals = "CommitmentHistoryVals"
 	CommitmentIdx         = "CommitmentIdx"
 
+	// projection of latest value -> key, for Domains with value index, see state.Domain.SetValueProjection
+	AccountValueIdx = "AccountValueIdx"
+	StorageValueIdx = "StorageValueIdx"
+
 	LogAddressKeys = "LogAddressKeys"
 	LogAddressIdx  = "LogAddressIdx"
 	LogTopicsKeys  = "LogTopicsKeys"
@@ -405,6 +409,11 @@ const (
 	PlainContractR = "PlainContractR" // temporary table for PlainContract reconstitution
 	PlainContractD = "PlainContractD" // temporary table for PlainContract reconstitution, deletes
 
+	// Persistent bptree.Tree23
+	Tree23Nodes = "Tree23Nodes" // nodeID -> encoded node
+	Tree23Roots = "Tree23Roots" // version -> rootID + root hash
+	Tree23Stale = "Tree23Stale" // version + nodeID -> nil, nodes replaced by the version
+
 	// Erigon-CL Objects
 
 	// [slot] => [Beacon state]
@@ -562,6 +571,9 @@ var ChaindataTables = []string{
 	CommitmentHistoryVals,
 	CommitmentIdx,
 
+	AccountValueIdx,
+	StorageValueIdx,
+
 	LogAddressKeys,
 	LogAddressIdx,
 	LogTopicsKeys,
@@ -614,6 +626,9 @@ var ChaindataTables = []string{
 	INTERMEDIATE_TX_STATEROOTS,
 	BATCH_WITNESSES,
 	BATCH_COUNTERS,
+	Tree23Nodes,
+	Tree23Roots,
+	Tree23Stale,
 }
 
 const (
@@ -712,6 +727,8 @@ var ChaindataTablesCfg = TableCfg{
 	CommitmentKeys:        {Flags: DupSort},
 	CommitmentHistoryKeys: {Flags: DupSort},
 	CommitmentIdx:         {Flags: DupSort},
+	AccountValueIdx:       {Flags: DupSort},
+	StorageValueIdx:       {Flags: DupSort},
 	LogAddressKeys:        {Flags: DupSort},
 	LogAddressIdx:         {Flags: DupSort},
 	LogTopicsKeys:         {Flags: DupSort},
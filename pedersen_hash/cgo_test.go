@@ -0,0 +1,49 @@
+//go:build linux && cgo
+// +build linux,cgo
+
+package hash
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gateway-fm/cdk-erigon-lib/pedersen_hash/internal/cgohash"
+)
+
+func TestHashAsCgo(t *testing.T) {
+	rnd := rand.New(rand.NewSource(4))
+	pMinusOne := new(big.Int).Sub(fieldModulusBig, big.NewInt(1))
+	inputs := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(0xff), pMinusOne, new(big.Int).Lsh(big.NewInt(1), 250)}
+	for i := 0; i < 100; i++ {
+		inputs = append(inputs, randomElement(rnd))
+	}
+	x, y := make([]*big.Int, 0), make([]*big.Int, 0)
+	for i, a := range inputs {
+		b := inputs[(i*13+7)%len(inputs)]
+		x, y = append(x, a), append(y, b)
+	}
+	hashes, err := HashMany(x, y)
+	require.NoError(t, err)
+	for i := range x {
+		in1, in2 := fmt.Sprintf("%064x", x[i]), fmt.Sprintf("%064x", y[i])
+		expected, err := cgohash.Hash(in1, in2)
+		require.NoError(t, err)
+		hash, err := Hash(in1, in2)
+		require.NoError(t, err)
+		require.Equal(t, expected, hash, "%s %s", in1, in2)
+		require.Equal(t, expected, fmt.Sprintf("0x%064x", hashes[i]))
+	}
+}
+
+func BenchmarkHashCgo(b *testing.B) {
+	rnd := rand.New(rand.NewSource(3))
+	x, y := fmt.Sprintf("%064x", randomElement(rnd)), fmt.Sprintf("%064x", randomElement(rnd))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = cgohash.Hash(x, y)
+	}
+}
@@ -0,0 +1,160 @@
+package hash
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// fieldElement - element of the STARK prime field p = 2^251 + 17*2^192 + 1 in Montgomery form (x*2^256 mod p),
+// little-endian 64-bit limbs
+type fieldElement [4]uint64
+
+var (
+	fieldModulus = fieldElement{1, 0, 0, 0x0800000000000011}
+	// -p^-1 mod 2^64, p = 1 mod 2^64
+	fieldModulusInv uint64 = 0xffffffffffffffff
+
+	fieldModulusBig              = new(big.Int).SetBytes(fieldModulus.bytes())
+	fieldR2         fieldElement // 2^512 mod p, converts into Montgomery form
+	fieldOne        fieldElement
+)
+
+func init() {
+	r2 := new(big.Int).Lsh(big.NewInt(1), 512)
+	fieldR2 = limbsFromBig(r2.Mod(r2, fieldModulusBig))
+	fieldOne.setBig(big.NewInt(1))
+}
+
+// bytes returns big-endian 32 bytes of limbs as they are (no conversion from Montgomery form)
+func (z *fieldElement) bytes() []byte {
+	b := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			b[31-i*8-j] = byte(z[i] >> (8 * j))
+		}
+	}
+	return b
+}
+
+func limbsFromBig(v *big.Int) (z fieldElement) {
+	b := v.FillBytes(make([]byte, 32))
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			z[i] |= uint64(b[31-i*8-j]) << (8 * j)
+		}
+	}
+	return z
+}
+
+// setBig sets z to v which must be less than p
+func (z *fieldElement) setBig(v *big.Int) *fieldElement {
+	limbs := limbsFromBig(v)
+	return z.mul(&limbs, &fieldR2)
+}
+
+func (z *fieldElement) big() *big.Int {
+	one := fieldElement{1}
+	var standard fieldElement
+	standard.mul(z, &one)
+	return new(big.Int).SetBytes(standard.bytes())
+}
+
+func (z *fieldElement) isZero() bool {
+	return z[0]|z[1]|z[2]|z[3] == 0
+}
+
+func (z *fieldElement) add(x, y *fieldElement) *fieldElement {
+	var carry uint64
+	z[0], carry = bits.Add64(x[0], y[0], 0)
+	z[1], carry = bits.Add64(x[1], y[1], carry)
+	z[2], carry = bits.Add64(x[2], y[2], carry)
+	z[3], _ = bits.Add64(x[3], y[3], carry) // p < 2^252, no overflow
+	return z.reduce()
+}
+
+func (z *fieldElement) sub(x, y *fieldElement) *fieldElement {
+	var borrow uint64
+	z[0], borrow = bits.Sub64(x[0], y[0], 0)
+	z[1], borrow = bits.Sub64(x[1], y[1], borrow)
+	z[2], borrow = bits.Sub64(x[2], y[2], borrow)
+	z[3], borrow = bits.Sub64(x[3], y[3], borrow)
+	if borrow != 0 {
+		var carry uint64
+		z[0], carry = bits.Add64(z[0], fieldModulus[0], 0)
+		z[1], carry = bits.Add64(z[1], fieldModulus[1], carry)
+		z[2], carry = bits.Add64(z[2], fieldModulus[2], carry)
+		z[3], _ = bits.Add64(z[3], fieldModulus[3], carry)
+	}
+	return z
+}
+
+func (z *fieldElement) double(x *fieldElement) *fieldElement {
+	return z.add(x, x)
+}
+
+// reduce subtracts p once if z >= p
+func (z *fieldElement) reduce() *fieldElement {
+	var r fieldElement
+	var borrow uint64
+	r[0], borrow = bits.Sub64(z[0], fieldModulus[0], 0)
+	r[1], borrow = bits.Sub64(z[1], fieldModulus[1], borrow)
+	r[2], borrow = bits.Sub64(z[2], fieldModulus[2], borrow)
+	r[3], borrow = bits.Sub64(z[3], fieldModulus[3], borrow)
+	if borrow == 0 {
+		*z = r
+	}
+	return z
+}
+
+// mul - Montgomery multiplication (CIOS)
+func (z *fieldElement) mul(x, y *fieldElement) *fieldElement {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		var c, hi, lo, carry uint64
+		for j := 0; j < 4; j++ {
+			hi, lo = bits.Mul64(x[j], y[i])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j], c = lo, hi
+		}
+		t[4], carry = bits.Add64(t[4], c, 0)
+		t[5] = carry
+
+		m := t[0] * fieldModulusInv
+		hi, lo = bits.Mul64(m, fieldModulus[0])
+		_, carry = bits.Add64(lo, t[0], 0)
+		c = hi + carry
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(m, fieldModulus[j])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j-1], c = lo, hi
+		}
+		t[3], carry = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + carry
+	}
+	*z = fieldElement{t[0], t[1], t[2], t[3]}
+	return z.reduce()
+}
+
+func (z *fieldElement) square(x *fieldElement) *fieldElement {
+	return z.mul(x, x)
+}
+
+// inverse of non-zero element by Fermat's little theorem: x^(p-2)
+func (z *fieldElement) inverse(x *fieldElement) *fieldElement {
+	exponent := new(big.Int).Sub(fieldModulusBig, big.NewInt(2))
+	result, base := fieldOne, *x
+	for i := 0; i < exponent.BitLen(); i++ {
+		if exponent.Bit(i) != 0 {
+			result.mul(&result, &base)
+		}
+		base.square(&base)
+	}
+	*z = result
+	return z
+}
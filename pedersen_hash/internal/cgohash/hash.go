@@ -1,7 +1,7 @@
-//go:build linux
-// +build linux
+//go:build linux && cgo
+// +build linux,cgo
 
-package hash
+package cgohash
 
 /*
 #include <stdlib.h>
@@ -0,0 +1,261 @@
+package hash
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+	"sync"
+)
+
+// Pedersen hash over the STARK curve in pure Go, it gives the same results as StarkWare's C++ implementation:
+// H(x, y) = [shift + x_0*P_0 + ... + x_251*P_251 + y_0*P_252 + ... + y_251*P_503].x
+// Points are summed by 4-bit windows from tables precomputed on first use.
+
+const (
+	elementBits = 252
+	windowBits  = 4
+	windowCount = elementBits / windowBits
+)
+
+// ErrSamePoint is returned if partial sum hits selected point or its inverse, it does not happen with unknown
+// discrete logarithms of the curve points
+var ErrSamePoint = errors.New("pedersen hash: adding a point to itself or to its inverse point")
+
+type affinePoint struct {
+	x, y fieldElement
+}
+
+type jacobianPoint struct {
+	x, y, z fieldElement
+}
+
+var (
+	shiftPoint   affinePoint
+	windowTables [2 * windowCount][1 << windowBits]affinePoint // entry 0 is not used
+	tablesOnce   sync.Once
+	tablesErr    error
+)
+
+func initTables() {
+	points := make([]affinePoint, len(curvePoints))
+	for i, p := range curvePoints {
+		x, _ := new(big.Int).SetString(p[0], 16)
+		y, _ := new(big.Int).SetString(p[1], 16)
+		points[i].x.setBig(x)
+		points[i].y.setBig(y)
+	}
+	shiftPoint = points[0]
+	selected := points[2:]
+	for w := range windowTables {
+		for v := 1; v < 1<<windowBits; v++ {
+			// entry is the point of the lowest bit added to the entry without this bit
+			bit := bits.TrailingZeros(uint(v))
+			rest := v &^ (1 << bit)
+			point := selected[w*windowBits+bit]
+			if rest == 0 {
+				windowTables[w][v] = point
+				continue
+			}
+			sum, err := addAffine(&windowTables[w][rest], &point)
+			if err != nil {
+				tablesErr = err
+				return
+			}
+			windowTables[w][v] = sum
+		}
+	}
+}
+
+func addAffine(p, q *affinePoint) (affinePoint, error) {
+	var dx, dy, lambda, t fieldElement
+	dx.sub(&q.x, &p.x)
+	if dx.isZero() {
+		return affinePoint{}, ErrSamePoint
+	}
+	dy.sub(&q.y, &p.y)
+	lambda.mul(&dy, t.inverse(&dx))
+	var r affinePoint
+	r.x.square(&lambda)
+	r.x.sub(&r.x, &p.x)
+	r.x.sub(&r.x, &q.x)
+	t.sub(&p.x, &r.x)
+	r.y.mul(&lambda, &t)
+	r.y.sub(&r.y, &p.y)
+	return r, nil
+}
+
+// addMixed adds affine q to jacobian p (madd-2007-bl)
+func (p *jacobianPoint) addMixed(q *affinePoint) error {
+	var z1z1, u2, s2, h, hh, i, j, r, v, t fieldElement
+	z1z1.square(&p.z)
+	u2.mul(&q.x, &z1z1)
+	s2.mul(&q.y, &p.z)
+	s2.mul(&s2, &z1z1)
+	h.sub(&u2, &p.x)
+	if h.isZero() {
+		return ErrSamePoint
+	}
+	hh.square(&h)
+	i.double(&hh)
+	i.double(&i)
+	j.mul(&h, &i)
+	r.sub(&s2, &p.y)
+	r.double(&r)
+	v.mul(&p.x, &i)
+
+	// X3 = r^2 - J - 2*V
+	var x3 fieldElement
+	x3.square(&r)
+	x3.sub(&x3, &j)
+	x3.sub(&x3, &v)
+	x3.sub(&x3, &v)
+	// Y3 = r*(V - X3) - 2*Y1*J
+	var y3 fieldElement
+	t.sub(&v, &x3)
+	y3.mul(&r, &t)
+	t.mul(&p.y, &j)
+	t.double(&t)
+	y3.sub(&y3, &t)
+	// Z3 = (Z1 + H)^2 - Z1Z1 - HH
+	var z3 fieldElement
+	z3.add(&p.z, &h)
+	z3.square(&z3)
+	z3.sub(&z3, &z1z1)
+	z3.sub(&z3, &hh)
+
+	p.x, p.y, p.z = x3, y3, z3
+	return nil
+}
+
+// elementWindows splits element in standard form into little-endian 4-bit windows
+func elementWindows(v *big.Int) (windows [windowCount]byte) {
+	b := v.FillBytes(make([]byte, 32))
+	for w := 0; w < windowCount; w++ {
+		octet := b[31-w/2]
+		if w%2 == 0 {
+			windows[w] = octet & 0x0f
+		} else {
+			windows[w] = octet >> 4
+		}
+	}
+	return windows
+}
+
+func checkElement(v *big.Int) error {
+	if v.Sign() < 0 || v.Cmp(fieldModulusBig) >= 0 {
+		return fmt.Errorf("pedersen hash: input %x is not a field element", v)
+	}
+	return nil
+}
+
+// hashJacobian returns sum point which x coordinate (in affine form) is the hash
+func hashJacobian(x, y *big.Int) (jacobianPoint, error) {
+	if err := checkElement(x); err != nil {
+		return jacobianPoint{}, err
+	}
+	if err := checkElement(y); err != nil {
+		return jacobianPoint{}, err
+	}
+	tablesOnce.Do(initTables)
+	if tablesErr != nil {
+		return jacobianPoint{}, tablesErr
+	}
+	sum := jacobianPoint{x: shiftPoint.x, y: shiftPoint.y, z: fieldOne}
+	for i, element := range []*big.Int{x, y} {
+		for w, v := range elementWindows(element) {
+			if v == 0 {
+				continue
+			}
+			if err := sum.addMixed(&windowTables[i*windowCount+w][v]); err != nil {
+				return jacobianPoint{}, err
+			}
+		}
+	}
+	return sum, nil
+}
+
+// HashElements returns Pedersen hash of two field elements
+func HashElements(x, y *big.Int) (*big.Int, error) {
+	sum, err := hashJacobian(x, y)
+	if err != nil {
+		return nil, err
+	}
+	var zInv, hash fieldElement
+	zInv.inverse(&sum.z)
+	hash.square(&zInv)
+	hash.mul(&hash, &sum.x)
+	return hash.big(), nil
+}
+
+// HashMany returns Pedersen hashes of pairs (x[i], y[i]), it shares one field inversion among all pairs
+func HashMany(x, y []*big.Int) ([]*big.Int, error) {
+	if len(x) != len(y) {
+		return nil, fmt.Errorf("pedersen hash: %d first and %d second inputs", len(x), len(y))
+	}
+	if len(x) == 0 {
+		return []*big.Int{}, nil
+	}
+	sums := make([]jacobianPoint, len(x))
+	// prefix products of z for batch inversion
+	products := make([]fieldElement, len(x))
+	for i := range x {
+		var err error
+		if sums[i], err = hashJacobian(x[i], y[i]); err != nil {
+			return nil, fmt.Errorf("pair %d: %w", i, err)
+		}
+		if i == 0 {
+			products[i] = sums[i].z
+		} else {
+			products[i].mul(&products[i-1], &sums[i].z)
+		}
+	}
+	var inv fieldElement
+	inv.inverse(&products[len(x)-1])
+	hashes := make([]*big.Int, len(x))
+	for i := len(x) - 1; i >= 0; i-- {
+		var zInv fieldElement
+		if i > 0 {
+			zInv.mul(&inv, &products[i-1])
+			inv.mul(&inv, &sums[i].z)
+		} else {
+			zInv = inv
+		}
+		var hash fieldElement
+		hash.square(&zInv)
+		hash.mul(&hash, &sums[i].x)
+		hashes[i] = hash.big()
+	}
+	return hashes, nil
+}
+
+// HashBytes returns Pedersen hash of two big-endian field elements as 32 big-endian bytes
+func HashBytes(x, y []byte) ([]byte, error) {
+	if len(x) > 32 || len(y) > 32 {
+		return nil, fmt.Errorf("pedersen hash: inputs of %d and %d bytes, expected up to 32", len(x), len(y))
+	}
+	hash, err := HashElements(new(big.Int).SetBytes(x), new(big.Int).SetBytes(y))
+	if err != nil {
+		return nil, err
+	}
+	return hash.FillBytes(make([]byte, 32)), nil
+}
+
+// Hash takes two big-endian hex field elements (up to 64 digits, optionally 0x-prefixed) and returns
+// 0x-prefixed 64 hex digits of the hash, the format used by zkEVM
+func Hash(input1, input2 string) (string, error) {
+	x, ok := new(big.Int).SetString(strings.TrimPrefix(input1, "0x"), 16)
+	if !ok {
+		return "", fmt.Errorf("pedersen hash: invalid hex input %q", input1)
+	}
+	y, ok := new(big.Int).SetString(strings.TrimPrefix(input2, "0x"), 16)
+	if !ok {
+		return "", fmt.Errorf("pedersen hash: invalid hex input %q", input2)
+	}
+	hash, err := HashElements(x, y)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("0x%064x", hash), nil
+}
@@ -0,0 +1,110 @@
+package hash
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func randomElement(rnd *rand.Rand) *big.Int {
+	return new(big.Int).Rand(rnd, fieldModulusBig)
+}
+
+func requireBigEqual(t *testing.T, expected, actual *big.Int) {
+	t.Helper()
+	require.Zero(t, expected.Cmp(actual), "expected %x, actual %x", expected, actual)
+}
+
+func TestFieldArithmetic(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(fieldModulusBig, big.NewInt(1))}
+	for i := 0; i < 200; i++ {
+		values = append(values, randomElement(rnd))
+	}
+	for i, a := range values {
+		b := values[(i*7+3)%len(values)]
+		var fa, fb, r fieldElement
+		fa.setBig(a)
+		fb.setBig(b)
+		requireBigEqual(t, a, fa.big())
+		requireBigEqual(t, new(big.Int).Mod(new(big.Int).Add(a, b), fieldModulusBig), r.add(&fa, &fb).big())
+		requireBigEqual(t, new(big.Int).Mod(new(big.Int).Sub(a, b), fieldModulusBig), r.sub(&fa, &fb).big())
+		requireBigEqual(t, new(big.Int).Mod(new(big.Int).Mul(a, b), fieldModulusBig), r.mul(&fa, &fb).big())
+		if a.Sign() != 0 {
+			requireBigEqual(t, new(big.Int).ModInverse(a, fieldModulusBig), r.inverse(&fa).big())
+		}
+	}
+}
+
+func TestCurvePoints(t *testing.T) {
+	beta, _ := new(big.Int).SetString(curveBeta, 16)
+	for i, p := range curvePoints {
+		x, _ := new(big.Int).SetString(p[0], 16)
+		y, _ := new(big.Int).SetString(p[1], 16)
+		// y^2 = x^3 + x + beta
+		left := new(big.Int).Mul(y, y)
+		right := new(big.Int).Add(new(big.Int).Mul(new(big.Int).Mul(x, x), x), x)
+		right.Add(right, beta)
+		require.Zero(t, left.Mod(left, fieldModulusBig).Cmp(right.Mod(right, fieldModulusBig)), "point %d", i)
+	}
+}
+
+func TestHash(t *testing.T) {
+	// StarkNet test vector
+	hash, err := Hash(
+		"0x03d937c035c878245caf64531a5756109c53068da139362728feb561405371cb",
+		"0x0208a0a10250e382e1e4bbe2880906c2791bf6275695e02fbbc6aeff9cd8b31a",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "0x030e480bed5fe53fa909cc0f8c4d99b8f9f2c016be4c41e13a4848797979c662", hash)
+
+	_, err = Hash("0x"+fieldModulusBig.Text(16), "00")
+	require.Error(t, err)
+	_, err = Hash("xyz", "00")
+	require.Error(t, err)
+}
+
+func TestHashMany(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	x, y := make([]*big.Int, 50), make([]*big.Int, 50)
+	for i := range x {
+		x[i], y[i] = randomElement(rnd), randomElement(rnd)
+	}
+	x[0], y[0] = big.NewInt(0), big.NewInt(0)
+	hashes, err := HashMany(x, y)
+	require.NoError(t, err)
+	for i := range x {
+		hash, err := HashElements(x[i], y[i])
+		require.NoError(t, err)
+		requireBigEqual(t, hash, hashes[i])
+
+		hashBytes, err := HashBytes(x[i].Bytes(), y[i].Bytes())
+		require.NoError(t, err)
+		require.Equal(t, hash.FillBytes(make([]byte, 32)), hashBytes)
+	}
+	_, err = HashMany(x, y[1:])
+	require.Error(t, err)
+}
+
+func BenchmarkHashElements(b *testing.B) {
+	rnd := rand.New(rand.NewSource(3))
+	x, y := randomElement(rnd), randomElement(rnd)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = HashElements(x, y)
+	}
+}
+
+func BenchmarkHashMany(b *testing.B) {
+	rnd := rand.New(rand.NewSource(3))
+	x, y := make([]*big.Int, 1000), make([]*big.Int, 1000)
+	for i := range x {
+		x[i], y[i] = randomElement(rnd), randomElement(rnd)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = HashMany(x, y)
+	}
+}
@@ -82,6 +82,19 @@ func (g *GolombRice) appendFixed(v uint64, log2golomb int) {
 	g.bitCount += log2golomb
 }
 
+// appendBits adds the encoding built by another GolombRice (starting from bit 0) to the end of the current encoding
+func (g *GolombRice) appendBits(other *GolombRice) {
+	bitCount := other.bitCount
+	for _, v := range other.data {
+		if bitCount < 64 {
+			g.appendFixed(v, bitCount)
+			break
+		}
+		g.appendFixed(v, 64)
+		bitCount -= 64
+	}
+}
+
 // Bits returns currrent number of bits in the compact encoding of the hash function representation
 func (g *GolombRice) Bits() int {
 	return g.bitCount
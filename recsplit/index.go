@@ -55,6 +55,8 @@ type Index struct {
 	keyCount           uint64
 	recMask            uint64
 	bytesPerRec        int
+	fingerprintBits    int    // 0 if index has no fingerprints
+	fingerprints       []byte // fingerprint of every record, see RecSplitArgs.FingerprintBits
 	salt               uint32
 	leafSize           uint16 // Leaf size for recursive split algorithms
 	secondaryAggrBound uint16 // The lower bound for secondary key aggregation (computed from leadSize)
@@ -154,7 +156,10 @@ func OpenIndex(indexFilePath string) (*Index, error) {
 	p := (*[maxDataSize / 8]uint64)(unsafe.Pointer(&idx.data[offset]))
 	idx.grData = p[:l]
 	offset += 8 * int(l)
-	idx.ef.Read(idx.data[offset:])
+	offset += idx.ef.Read(idx.data[offset:])
+	if err = idx.readExtensions(idx.data[offset:]); err != nil {
+		return nil, fmt.Errorf("%w, file: %s", err, indexFilePath)
+	}
 
 	idx.readers = &sync.Pool{
 		New: func() interface{} {
@@ -164,6 +169,30 @@ func OpenIndex(indexFilePath string) (*Index, error) {
 	return idx, nil
 }
 
+// Index file of version 0 ends with double Elias-Fano. Newer versions append extension section, which
+// is ignored by older readers:
+//   - version (1 byte)
+//   - indexVersionFingerprints: fingerprint bits (1 byte), fingerprint of every record (FingerprintBits/8 bytes each)
+const indexVersionFingerprints = 1
+
+func (idx *Index) readExtensions(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if data[0] != indexVersionFingerprints || len(data) < 2 {
+		return fmt.Errorf("unsupported index version %d", data[0])
+	}
+	idx.fingerprintBits = int(data[1])
+	if idx.fingerprintBits != 8 && idx.fingerprintBits != 16 {
+		return fmt.Errorf("invalid fingerprint bits %d", idx.fingerprintBits)
+	}
+	idx.fingerprints = data[2:]
+	if uint64(len(idx.fingerprints)) != idx.keyCount*uint64(idx.fingerprintBits/8) {
+		return fmt.Errorf("fingerprints size %d doesn't match %d keys", len(idx.fingerprints), idx.keyCount)
+	}
+	return nil
+}
+
 func (idx *Index) Size() int64        { return idx.size }
 func (idx *Index) ModTime() time.Time { return idx.modTime }
 func (idx *Index) BaseDataID() uint64 { return idx.baseDataID }
@@ -206,6 +235,11 @@ func (idx *Index) KeyCount() uint64 {
 	return idx.keyCount
 }
 
+// FingerprintBits returns size of per-key fingerprints, 0 if index doesn't have them
+func (idx *Index) FingerprintBits() int {
+	return idx.fingerprintBits
+}
+
 // Lookup is not thread-safe because it used id.hasher
 func (idx *Index) Lookup(bucketHash, fingerprint uint64) uint64 {
 	if idx.keyCount == 0 {
@@ -215,6 +249,41 @@ func (idx *Index) Lookup(bucketHash, fingerprint uint64) uint64 {
 	if idx.keyCount == 1 {
 		return 0
 	}
+	rec := idx.lookupRecord(bucketHash, fingerprint)
+	pos := 1 + 8 + idx.bytesPerRec*(rec+1)
+	return binary.BigEndian.Uint64(idx.data[pos:]) & idx.recMask
+}
+
+// TryLookup is the same as Lookup, but it also compares fingerprint of the key with fingerprint of the found record
+// (if index has them): ok=false means that the key was not added to the index. Without fingerprints ok is true for
+// any key of non-empty index. Like Lookup, it returns 0 for the key of one-key index
+func (idx *Index) TryLookup(bucketHash, fingerprint uint64) (uint64, bool) {
+	if idx.keyCount == 0 {
+		return 0, false
+	}
+	var rec int
+	if idx.keyCount > 1 {
+		rec = idx.lookupRecord(bucketHash, fingerprint)
+	}
+	switch idx.fingerprintBits {
+	case 8:
+		if idx.fingerprints[rec] != byte(fingerprint>>56) {
+			return 0, false
+		}
+	case 16:
+		if binary.BigEndian.Uint16(idx.fingerprints[2*rec:]) != uint16(fingerprint>>48) {
+			return 0, false
+		}
+	}
+	if idx.keyCount == 1 {
+		return 0, true
+	}
+	pos := 1 + 8 + idx.bytesPerRec*(rec+1)
+	return binary.BigEndian.Uint64(idx.data[pos:]) & idx.recMask, true
+}
+
+// lookupRecord evaluates perfect hash function and returns number of the record for the key
+func (idx *Index) lookupRecord(bucketHash, fingerprint uint64) int {
 	var gr GolombRiceReader
 	gr.data = idx.grData
 
@@ -267,10 +336,7 @@ func (idx *Index) Lookup(bucketHash, fingerprint uint64) uint64 {
 		level++
 	}
 	b := gr.ReadNext(idx.golombParam(m))
-	rec := int(cumKeys) + int(remap16(remix(fingerprint+idx.startSeed[level]+b), m))
-	pos := 1 + 8 + idx.bytesPerRec*(rec+1)
-
-	return binary.BigEndian.Uint64(idx.data[pos:]) & idx.recMask
+	return int(cumKeys) + int(remap16(remix(fingerprint+idx.startSeed[level]+b), m))
 }
 
 // OrdinalLookup returns the offset of i-th element in the index
@@ -71,6 +71,23 @@ func (r *IndexReader) Lookup2(key1, key2 []byte) uint64 {
 	return 0
 }
 
+// TryLookup wraps index TryLookup: ok=false means that the key is absent, see RecSplitArgs.FingerprintBits
+func (r *IndexReader) TryLookup(key []byte) (uint64, bool) {
+	bucketHash, fingerprint := r.sum(key)
+	if r.index != nil {
+		return r.index.TryLookup(bucketHash, fingerprint)
+	}
+	return 0, false
+}
+
+func (r *IndexReader) TryLookup2(key1, key2 []byte) (uint64, bool) {
+	bucketHash, fingerprint := r.sum2(key1, key2)
+	if r.index != nil {
+		return r.index.TryLookup(bucketHash, fingerprint)
+	}
+	return 0, false
+}
+
 func (r *IndexReader) Empty() bool {
 	return r.index.Empty()
 }
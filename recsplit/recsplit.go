@@ -20,12 +20,14 @@ import (
 	"bufio"
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"math/bits"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/c2h5oh/datasize"
 	"github.com/gateway-fm/cdk-erigon-lib/common/assert"
@@ -73,18 +75,23 @@ type RecSplit struct {
 	gr                GolombRice // Helper object to encode the tree of hash function salts using Golomb-Rice code.
 	bucketPosAcc      []uint64   // Accumulator for position of every bucket in the encoding of the hash function
 	startSeed         []uint64
-	count             []uint16
 	currentBucket     []uint64 // 64-bit fingerprints of keys in the current bucket accumulated before the recsplit is performed for that bucket
 	currentBucketOffs []uint64 // Index offsets for the current bucket
-	offsetBuffer      []uint64
-	buffer            []uint64
+	fingerprintF      *os.File // temporary file for fingerprints of the keys, in the order of records
+	fingerprintW      *bufio.Writer
 	golombRice        []uint32
-	bucketSizeAcc     []uint64 // Bucket size accumulator
+	builders          []*bucketBuilder     // One per worker, only exist during the build
+	bucket            recsplitBucket       // Bucket reused by the sequential build
+	jobs              chan *recsplitBucket // Buckets to recsplit, consumed by the workers
+	pending           []*recsplitBucket    // Buckets handed over to the workers, but not yet written into the index
+	bucketSizeAcc     []uint64             // Bucket size accumulator
 	// Helper object to encode the sequence of cumulative number of keys in the buckets
 	// and the sequence of of cumulative bit offsets of buckets in the Golomb-Rice code.
 	ef                 eliasfano16.DoubleEliasFano
 	lvl                log.Lvl
 	bytesPerRec        int
+	fingerprintBits    int
+	workers            int
 	minDelta           uint64 // minDelta for Elias Fano encoding of "enum -> offset" index
 	prevOffset         uint64 // Previously added offset (for calculating minDelta for Elias Fano encoding of "enum -> offset" index)
 	bucketSize         int
@@ -122,6 +129,13 @@ type RecSplitArgs struct {
 	EtlBufLimit datasize.ByteSize
 	Salt        uint32 // Hash seed (salt) for the hash function used for allocating the initial buckets - need to be generated randomly
 	LeafSize    uint16
+
+	// Size of per-key fingerprint (0 - no fingerprints, 8 or 16): allows IndexReader.TryLookup to reject keys which were not added,
+	// except 1/2^FingerprintBits of them, without access to the data. Costs FingerprintBits/8 bytes per key
+	FingerprintBits int
+
+	// Number of goroutines recsplitting the buckets (0 or 1 - sequential build). Produced index does not depend on it
+	Workers int
 }
 
 // NewRecSplit creates a new RecSplit instance with given number of keys and given bucket size
@@ -177,7 +191,11 @@ func NewRecSplit(args RecSplitArgs) (*RecSplit, error) {
 		rs.secondaryAggrBound = rs.primaryAggrBound * uint16(math.Ceil(0.21*float64(rs.leafSize)+9./10.))
 	}
 	rs.startSeed = args.StartSeed
-	rs.count = make([]uint16, rs.secondaryAggrBound)
+	rs.workers = args.Workers
+	if args.FingerprintBits != 0 && args.FingerprintBits != 8 && args.FingerprintBits != 16 {
+		return nil, fmt.Errorf("fingerprint bits must be 0, 8 or 16: %d", args.FingerprintBits)
+	}
+	rs.fingerprintBits = args.FingerprintBits
 	return rs, nil
 }
 
@@ -185,6 +203,7 @@ func (rs *RecSplit) Close() {
 	if rs.indexF != nil {
 		rs.indexF.Close()
 	}
+	rs.closeFingerprints()
 	if rs.bucketCollector != nil {
 		rs.bucketCollector.Close()
 	}
@@ -289,21 +308,22 @@ func computeGolombRice(m uint16, table []uint32, leafSize, primaryAggrBound, sec
 // golombParam returns the optimal Golomb parameter to use for encoding
 // salt for the part of the hash function separating m elements. It is based on
 // calculations with assumptions that we draw hash functions at random
-func (rs *RecSplit) golombParam(m uint16) int {
-	s := uint16(len(rs.golombRice))
+func (bb *bucketBuilder) golombParam(m uint16) int {
+	rs := bb.rs
+	s := uint16(len(bb.golombRice))
 	for m >= s {
-		rs.golombRice = append(rs.golombRice, 0)
+		bb.golombRice = append(bb.golombRice, 0)
 		// For the case where bucket is larger than planned
 		if s == 0 {
-			rs.golombRice[0] = (bijMemo[0] << 27) | bijMemo[0]
+			bb.golombRice[0] = (bijMemo[0] << 27) | bijMemo[0]
 		} else if s <= rs.leafSize {
-			rs.golombRice[s] = (bijMemo[s] << 27) | (uint32(1) << 16) | bijMemo[s]
+			bb.golombRice[s] = (bijMemo[s] << 27) | (uint32(1) << 16) | bijMemo[s]
 		} else {
-			computeGolombRice(s, rs.golombRice, rs.leafSize, rs.primaryAggrBound, rs.secondaryAggrBound)
+			computeGolombRice(s, bb.golombRice, rs.leafSize, rs.primaryAggrBound, rs.secondaryAggrBound)
 		}
 		s++
 	}
-	return int(rs.golombRice[m] >> 27)
+	return int(bb.golombRice[m] >> 27)
 }
 
 // Add key to the RecSplit. There can be many more keys than what fits in RAM, and RecSplit
@@ -347,59 +367,80 @@ func (rs *RecSplit) AddKey(key []byte, offset uint64) error {
 	return nil
 }
 
-func (rs *RecSplit) recsplitCurrentBucket() error {
-	// Extend rs.bucketSizeAcc to accomodate current bucket index + 1
-	for len(rs.bucketSizeAcc) <= int(rs.currentBucketIdx)+1 {
-		rs.bucketSizeAcc = append(rs.bucketSizeAcc, rs.bucketSizeAcc[len(rs.bucketSizeAcc)-1])
-	}
-	rs.bucketSizeAcc[int(rs.currentBucketIdx)+1] += uint64(len(rs.currentBucket))
+// recsplitBucket is a bucket of keys together with the result of recsplitting it. Buckets are recsplit
+// independently of each other (possibly by different workers), but written into the index in order
+type recsplitBucket struct {
+	idx          uint64
+	keys         []uint64      // 64-bit fingerprints of keys in the bucket
+	offsets      []uint64      // Index offsets for the bucket
+	gr           GolombRice    // Golomb-Rice encoding of the hash function for this bucket only
+	records      []byte        // Index records in the order defined by the hash function
+	fingerprints []byte        // Fingerprints of the keys in the order of records
+	err          error         // Error encountered while recsplitting the bucket
+	done         chan struct{} // Closed by the worker when the bucket is processed
+}
+
+func (b *recsplitBucket) reset() {
+	b.gr.data, b.gr.bitCount = b.gr.data[:0], 0
+	b.records, b.fingerprints = b.records[:0], b.fingerprints[:0]
+	b.err = nil
+}
+
+// bucketBuilder holds the scratch space for recsplitting buckets - one per worker.
+// Parameters of the hash function are read from rs, which is not modified while buckets are recsplit
+type bucketBuilder struct {
+	rs           *RecSplit
+	golombRice   []uint32
+	buffer       []uint64
+	offsetBuffer []uint64
+	count        []uint16
+	numBuf       [8]byte
+}
+
+func newBucketBuilder(rs *RecSplit) *bucketBuilder {
+	return &bucketBuilder{rs: rs, count: make([]uint16, rs.secondaryAggrBound)}
+}
+
+func (bb *bucketBuilder) recsplitBucket(b *recsplitBucket) error {
 	// Sets of size 0 and 1 are not further processed, just write them to index
-	if len(rs.currentBucket) > 1 {
-		for i, key := range rs.currentBucket[1:] {
-			if key == rs.currentBucket[i] {
-				rs.collision = true
+	if len(b.keys) > 1 {
+		for i, key := range b.keys[1:] {
+			if key == b.keys[i] {
 				return fmt.Errorf("%w: %x", ErrCollision, key)
 			}
 		}
-		bitPos := rs.gr.bitCount
-		if rs.buffer == nil {
-			rs.buffer = make([]uint64, len(rs.currentBucket))
-			rs.offsetBuffer = make([]uint64, len(rs.currentBucketOffs))
-		} else {
-			for len(rs.buffer) < len(rs.currentBucket) {
-				rs.buffer = append(rs.buffer, 0)
-				rs.offsetBuffer = append(rs.offsetBuffer, 0)
-			}
-		}
-		unary, err := rs.recsplit(0 /* level */, rs.currentBucket, rs.currentBucketOffs, nil /* unary */)
-		if err != nil {
-			return err
+		for len(bb.buffer) < len(b.keys) {
+			bb.buffer = append(bb.buffer, 0)
+			bb.offsetBuffer = append(bb.offsetBuffer, 0)
 		}
-		rs.gr.appendUnaryAll(unary)
-		if rs.trace {
-			fmt.Printf("recsplitBucket(%d, %d, bitsize = %d)\n", rs.currentBucketIdx, len(rs.currentBucket), rs.gr.bitCount-bitPos)
+		unary := bb.recsplit(b, 0 /* level */, b.keys, b.offsets, nil /* unary */)
+		b.gr.appendUnaryAll(unary)
+		if bb.rs.trace {
+			fmt.Printf("recsplitBucket(%d, %d, bitsize = %d)\n", b.idx, len(b.keys), b.gr.bitCount)
 		}
 	} else {
-		for _, offset := range rs.currentBucketOffs {
-			binary.BigEndian.PutUint64(rs.numBuf[:], offset)
-			if _, err := rs.indexW.Write(rs.numBuf[8-rs.bytesPerRec:]); err != nil {
-				return err
-			}
+		for i, offset := range b.offsets {
+			bb.addRecord(b, b.keys[i], offset)
 		}
 	}
-	// Extend rs.bucketPosAcc to accomodate current bucket index + 1
-	for len(rs.bucketPosAcc) <= int(rs.currentBucketIdx)+1 {
-		rs.bucketPosAcc = append(rs.bucketPosAcc, rs.bucketPosAcc[len(rs.bucketPosAcc)-1])
-	}
-	rs.bucketPosAcc[int(rs.currentBucketIdx)+1] = uint64(rs.gr.Bits())
-	// clear for the next buckey
-	rs.currentBucket = rs.currentBucket[:0]
-	rs.currentBucketOffs = rs.currentBucketOffs[:0]
 	return nil
 }
 
+// addRecord adds index record and fingerprint of the key (top bits of it's 64-bit hash) to the bucket
+func (bb *bucketBuilder) addRecord(b *recsplitBucket, key, offset uint64) {
+	binary.BigEndian.PutUint64(bb.numBuf[:], offset)
+	b.records = append(b.records, bb.numBuf[8-bb.rs.bytesPerRec:]...)
+	switch bb.rs.fingerprintBits {
+	case 8:
+		b.fingerprints = append(b.fingerprints, byte(key>>56))
+	case 16:
+		b.fingerprints = binary.BigEndian.AppendUint16(b.fingerprints, uint16(key>>48))
+	}
+}
+
 // recsplit applies recSplit algorithm to the given bucket
-func (rs *RecSplit) recsplit(level int, bucket []uint64, offsets []uint64, unary []uint64) ([]uint64, error) {
+func (bb *bucketBuilder) recsplit(b *recsplitBucket, level int, bucket []uint64, offsets []uint64, unary []uint64) []uint64 {
+	rs := bb.rs
 	if rs.trace {
 		fmt.Printf("recsplit(%d, %d, %x)\n", level, len(bucket), bucket)
 	}
@@ -427,24 +468,22 @@ func (rs *RecSplit) recsplit(level int, bucket []uint64, offsets []uint64, unary
 		}
 		for i := uint16(0); i < m; i++ {
 			j := remap16(remix(bucket[i]+salt), m)
-			rs.offsetBuffer[j] = offsets[i]
+			bb.offsetBuffer[j] = offsets[i]
+			bb.buffer[j] = bucket[i]
 		}
-		for _, offset := range rs.offsetBuffer[:m] {
-			binary.BigEndian.PutUint64(rs.numBuf[:], offset)
-			if _, err := rs.indexW.Write(rs.numBuf[8-rs.bytesPerRec:]); err != nil {
-				return nil, err
-			}
+		for i, offset := range bb.offsetBuffer[:m] {
+			bb.addRecord(b, bb.buffer[i], offset)
 		}
 		salt -= rs.startSeed[level]
-		log2golomb := rs.golombParam(m)
+		log2golomb := bb.golombParam(m)
 		if rs.trace {
-			fmt.Printf("encode bij %d with log2golomn %d at p = %d\n", salt, log2golomb, rs.gr.bitCount)
+			fmt.Printf("encode bij %d with log2golomn %d at p = %d\n", salt, log2golomb, b.gr.bitCount)
 		}
-		rs.gr.appendFixed(salt, log2golomb)
+		b.gr.appendFixed(salt, log2golomb)
 		unary = append(unary, salt>>log2golomb)
 	} else {
 		fanout, unit := splitParams(m, rs.leafSize, rs.primaryAggrBound, rs.secondaryAggrBound)
-		count := rs.count
+		count := bb.count
 		for {
 			for i := uint16(0); i < fanout-1; i++ {
 				count[i] = 0
@@ -467,38 +506,149 @@ func (rs *RecSplit) recsplit(level int, bucket []uint64, offsets []uint64, unary
 		}
 		for i := uint16(0); i < m; i++ {
 			j := remap16(remix(bucket[i]+salt), m) / unit
-			rs.buffer[count[j]] = bucket[i]
-			rs.offsetBuffer[count[j]] = offsets[i]
+			bb.buffer[count[j]] = bucket[i]
+			bb.offsetBuffer[count[j]] = offsets[i]
 			count[j]++
 		}
-		copy(bucket, rs.buffer)
-		copy(offsets, rs.offsetBuffer)
+		copy(bucket, bb.buffer)
+		copy(offsets, bb.offsetBuffer)
 		salt -= rs.startSeed[level]
-		log2golomb := rs.golombParam(m)
+		log2golomb := bb.golombParam(m)
 		if rs.trace {
-			fmt.Printf("encode fanout %d: %d with log2golomn %d at p = %d\n", fanout, salt, log2golomb, rs.gr.bitCount)
+			fmt.Printf("encode fanout %d: %d with log2golomn %d at p = %d\n", fanout, salt, log2golomb, b.gr.bitCount)
 		}
-		rs.gr.appendFixed(salt, log2golomb)
+		b.gr.appendFixed(salt, log2golomb)
 		unary = append(unary, salt>>log2golomb)
-		var err error
 		var i uint16
 		for i = 0; i < m-unit; i += unit {
-			if unary, err = rs.recsplit(level+1, bucket[i:i+unit], offsets[i:i+unit], unary); err != nil {
-				return nil, err
-			}
+			unary = bb.recsplit(b, level+1, bucket[i:i+unit], offsets[i:i+unit], unary)
 		}
 		if m-i > 1 {
-			if unary, err = rs.recsplit(level+1, bucket[i:], offsets[i:], unary); err != nil {
-				return nil, err
-			}
+			unary = bb.recsplit(b, level+1, bucket[i:], offsets[i:], unary)
 		} else if m-i == 1 {
-			binary.BigEndian.PutUint64(rs.numBuf[:], offsets[i])
-			if _, err := rs.indexW.Write(rs.numBuf[8-rs.bytesPerRec:]); err != nil {
-				return nil, err
+			bb.addRecord(b, bucket[i], offsets[i])
+		}
+	}
+	return unary
+}
+
+// recsplitCurrentBucket recsplits the accumulated bucket right away, or hands it over to the workers
+func (rs *RecSplit) recsplitCurrentBucket() error {
+	defer func() {
+		// clear for the next buckey
+		rs.currentBucket = rs.currentBucket[:0]
+		rs.currentBucketOffs = rs.currentBucketOffs[:0]
+	}()
+	if rs.jobs == nil {
+		b := &rs.bucket
+		b.reset()
+		b.idx, b.keys, b.offsets = rs.currentBucketIdx, rs.currentBucket, rs.currentBucketOffs
+		b.err = rs.builders[0].recsplitBucket(b)
+		return rs.writeBucket(b)
+	}
+	b := &recsplitBucket{
+		idx:     rs.currentBucketIdx,
+		keys:    append([]uint64(nil), rs.currentBucket...),
+		offsets: append([]uint64(nil), rs.currentBucketOffs...),
+		done:    make(chan struct{}),
+	}
+	rs.pending = append(rs.pending, b)
+	rs.jobs <- b
+	return rs.writePendingBuckets(2 * len(rs.builders))
+}
+
+// writePendingBuckets writes buckets handed over to the workers into the index, in order,
+// waiting for the workers until no more than limit buckets are pending
+func (rs *RecSplit) writePendingBuckets(limit int) error {
+	for len(rs.pending) > limit {
+		b := rs.pending[0]
+		<-b.done
+		rs.pending[0] = nil
+		rs.pending = rs.pending[1:]
+		if err := rs.writeBucket(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBucket writes records of the recsplit bucket into the index and appends its encoding to the hash function
+func (rs *RecSplit) writeBucket(b *recsplitBucket) error {
+	// Extend rs.bucketSizeAcc to accomodate current bucket index + 1
+	for len(rs.bucketSizeAcc) <= int(b.idx)+1 {
+		rs.bucketSizeAcc = append(rs.bucketSizeAcc, rs.bucketSizeAcc[len(rs.bucketSizeAcc)-1])
+	}
+	rs.bucketSizeAcc[int(b.idx)+1] += uint64(len(b.keys))
+	if b.err != nil {
+		if errors.Is(b.err, ErrCollision) {
+			rs.collision = true
+		}
+		return b.err
+	}
+	if _, err := rs.indexW.Write(b.records); err != nil {
+		return err
+	}
+	if rs.fingerprintW != nil {
+		if _, err := rs.fingerprintW.Write(b.fingerprints); err != nil {
+			return err
+		}
+	}
+	rs.gr.appendBits(&b.gr)
+	// Extend rs.bucketPosAcc to accomodate current bucket index + 1
+	for len(rs.bucketPosAcc) <= int(b.idx)+1 {
+		rs.bucketPosAcc = append(rs.bucketPosAcc, rs.bucketPosAcc[len(rs.bucketPosAcc)-1])
+	}
+	rs.bucketPosAcc[int(b.idx)+1] = uint64(rs.gr.Bits())
+	return nil
+}
+
+// recsplitBuckets loads the keys sorted by buckets and recsplits the buckets, sequentially or on rs.workers goroutines.
+// The resulting index does not depend on the number of workers
+func (rs *RecSplit) recsplitBuckets() error {
+	rs.builders = make([]*bucketBuilder, 1)
+	if rs.workers > 1 {
+		rs.builders = make([]*bucketBuilder, rs.workers)
+	}
+	for i := range rs.builders {
+		rs.builders[i] = newBucketBuilder(rs)
+	}
+	defer func() {
+		// Golomb-Rice parameters are written up to the largest bucket seen by any of the builders
+		for _, bb := range rs.builders {
+			if len(bb.golombRice) > len(rs.golombRice) {
+				rs.golombRice = bb.golombRice
 			}
 		}
+		rs.builders = nil
+	}()
+	if rs.workers > 1 {
+		rs.jobs = make(chan *recsplitBucket, rs.workers)
+		var wg sync.WaitGroup
+		for _, bb := range rs.builders {
+			wg.Add(1)
+			go func(bb *bucketBuilder) {
+				defer wg.Done()
+				for b := range rs.jobs {
+					b.err = bb.recsplitBucket(b)
+					close(b.done)
+				}
+			}(bb)
+		}
+		defer func() {
+			close(rs.jobs)
+			wg.Wait()
+			rs.jobs, rs.pending = nil, nil
+		}()
+	}
+	if err := rs.bucketCollector.Load(nil, "", rs.loadFuncBucket, etl.TransformArgs{}); err != nil {
+		return err
+	}
+	if len(rs.currentBucket) > 0 {
+		if err := rs.recsplitCurrentBucket(); err != nil {
+			return err
+		}
 	}
-	return unary, nil
+	return rs.writePendingBuckets(0)
 }
 
 // loadFuncBucket is required to satisfy the type etl.LoadFunc type, to use with collector.Load
@@ -560,19 +710,22 @@ func (rs *RecSplit) Build() error {
 		return fmt.Errorf("write bytes per record: %w", err)
 	}
 
+	if rs.fingerprintBits > 0 {
+		if rs.fingerprintF, err = os.CreateTemp(rs.tmpDir, rs.indexFileName+".fp"); err != nil {
+			return fmt.Errorf("create fingerprints file: %w", err)
+		}
+		defer rs.closeFingerprints()
+		rs.fingerprintW = bufio.NewWriterSize(rs.fingerprintF, etl.BufIOSize)
+	}
+
 	rs.currentBucketIdx = math.MaxUint64 // To make sure 0 bucket is detected
 	defer rs.bucketCollector.Close()
 	if rs.lvl < log.LvlTrace {
 		log.Log(rs.lvl, "[index] calculating", "file", rs.indexFileName)
 	}
-	if err := rs.bucketCollector.Load(nil, "", rs.loadFuncBucket, etl.TransformArgs{}); err != nil {
+	if err := rs.recsplitBuckets(); err != nil {
 		return err
 	}
-	if len(rs.currentBucket) > 0 {
-		if err := rs.recsplitCurrentBucket(); err != nil {
-			return err
-		}
-	}
 
 	if assert.Enable {
 		rs.indexW.Flush()
@@ -655,6 +808,11 @@ func (rs *RecSplit) Build() error {
 	if err := rs.ef.Write(rs.indexW); err != nil {
 		return fmt.Errorf("writing elias fano: %w", err)
 	}
+	if rs.fingerprintBits > 0 {
+		if err := rs.writeFingerprints(); err != nil {
+			return fmt.Errorf("writing fingerprints: %w", err)
+		}
+	}
 
 	_ = rs.indexW.Flush()
 	_ = rs.indexF.Sync()
@@ -663,6 +821,36 @@ func (rs *RecSplit) Build() error {
 	return nil
 }
 
+// writeFingerprints writes extension section of the index with collected fingerprints, see indexVersionFingerprints
+func (rs *RecSplit) writeFingerprints() error {
+	if err := rs.fingerprintW.Flush(); err != nil {
+		return err
+	}
+	if _, err := rs.fingerprintF.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := rs.indexW.Write([]byte{indexVersionFingerprints, byte(rs.fingerprintBits)}); err != nil {
+		return err
+	}
+	n, err := io.Copy(rs.indexW, rs.fingerprintF)
+	if err != nil {
+		return err
+	}
+	if n != int64(rs.keysAdded)*int64(rs.fingerprintBits/8) {
+		return fmt.Errorf("expected %d fingerprints, got %d bytes", rs.keysAdded, n)
+	}
+	return nil
+}
+
+func (rs *RecSplit) closeFingerprints() {
+	if rs.fingerprintF == nil {
+		return
+	}
+	rs.fingerprintF.Close()
+	os.Remove(rs.fingerprintF.Name())
+	rs.fingerprintF, rs.fingerprintW = nil, nil
+}
+
 // Stats returns the size of golomb rice encoding and ellias fano encoding
 func (rs *RecSplit) Stats() (int, int) {
 	return len(rs.gr.Data()), len(rs.ef.Data())
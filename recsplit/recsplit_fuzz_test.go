@@ -18,6 +18,7 @@ limitations under the License.
 package recsplit
 
 import (
+	"fmt"
 	"path/filepath"
 	"testing"
 )
@@ -47,47 +48,52 @@ func FuzzRecSplit(f *testing.F) {
 			t.Skip()
 		}
 		tmpDir := t.TempDir()
-		indexFile := filepath.Join(tmpDir, "index")
-		rs, err := NewRecSplit(RecSplitArgs{
-			KeyCount:   count,
-			Enums:      true,
-			BucketSize: 10,
-			Salt:       0,
-			TmpDir:     tmpDir,
-			IndexFile:  indexFile,
-			LeafSize:   8,
-		})
-		if err != nil {
-			t.Fatal(err)
-		}
-		var off uint64
-		for i = 0; i < len(in)-l; i += l {
-			if err := rs.AddKey(in[i:i+l], off); err != nil {
+		// Build the index sequentially and on several workers
+		for _, workers := range []int{1, 4} {
+			indexFile := filepath.Join(tmpDir, fmt.Sprintf("index%d", workers))
+			rs, err := NewRecSplit(RecSplitArgs{
+				KeyCount:   count,
+				Enums:      true,
+				BucketSize: 10,
+				Salt:       0,
+				TmpDir:     tmpDir,
+				IndexFile:  indexFile,
+				LeafSize:   8,
+				Workers:    workers,
+			})
+			if err != nil {
 				t.Fatal(err)
 			}
-			off++
-		}
-		if err := rs.AddKey(in[i:], off); err != nil {
-			t.Fatal(err)
-		}
-		if err = rs.Build(); err != nil {
-			t.Fatal(err)
-		}
-		// Check that there is a bijection
-		idx := MustOpen(indexFile)
-		bitCount := (count + 63) / 64
-		bits := make([]uint64, bitCount)
-		reader := NewIndexReader(idx)
-		for i = 0; i < len(in)-l; i += l {
-			off = reader.Lookup(in[i : i+l])
-			if int(off) >= count {
-				t.Errorf("off %d >= count %d", off, count)
+			var off uint64
+			for i = 0; i < len(in)-l; i += l {
+				if err := rs.AddKey(in[i:i+l], off); err != nil {
+					t.Fatal(err)
+				}
+				off++
+			}
+			if err := rs.AddKey(in[i:], off); err != nil {
+				t.Fatal(err)
+			}
+			if err = rs.Build(); err != nil {
+				t.Fatal(err)
 			}
-			mask := uint64(1) << (off & 63)
-			if bits[off>>6]&mask != 0 {
-				t.Fatalf("no bijection count=%d, i=%d", count, i/l)
+			// Check that there is a bijection
+			idx := MustOpen(indexFile)
+			bitCount := (count + 63) / 64
+			bits := make([]uint64, bitCount)
+			reader := NewIndexReader(idx)
+			for i = 0; i < len(in)-l; i += l {
+				off = reader.Lookup(in[i : i+l])
+				if int(off) >= count {
+					t.Errorf("off %d >= count %d", off, count)
+				}
+				mask := uint64(1) << (off & 63)
+				if bits[off>>6]&mask != 0 {
+					t.Fatalf("no bijection count=%d, i=%d", count, i/l)
+				}
+				bits[off>>6] |= mask
 			}
-			bits[off>>6] |= mask
+			idx.Close()
 		}
 	})
 }
@@ -17,7 +17,10 @@
 package recsplit
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -165,3 +168,202 @@ func TestTwoLayerIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestIndexFingerprints(t *testing.T) {
+	for _, fingerprintBits := range []int{0, 8, 16} {
+		for _, enums := range []bool{false, true} {
+			tmpDir := t.TempDir()
+			indexFile := filepath.Join(tmpDir, "index")
+			rs, err := NewRecSplit(RecSplitArgs{
+				KeyCount:        1000,
+				BucketSize:      100,
+				Salt:            0,
+				TmpDir:          tmpDir,
+				IndexFile:       indexFile,
+				LeafSize:        8,
+				Enums:           enums,
+				FingerprintBits: fingerprintBits,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 1000; i++ {
+				if err = rs.AddKey([]byte(fmt.Sprintf("key %d", i)), uint64(i*17)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := rs.Build(); err != nil {
+				t.Fatal(err)
+			}
+			idx := MustOpen(indexFile)
+			if idx.FingerprintBits() != fingerprintBits {
+				t.Errorf("expected fingerprint bits: %d, got: %d", fingerprintBits, idx.FingerprintBits())
+			}
+			reader := NewIndexReader(idx)
+			for i := 0; i < 1000; i++ {
+				key := []byte(fmt.Sprintf("key %d", i))
+				offset, ok := reader.TryLookup(key)
+				if !ok {
+					t.Fatalf("bits=%d, enums=%t: key %d is rejected", fingerprintBits, enums, i)
+				}
+				if offset != reader.Lookup(key) {
+					t.Errorf("bits=%d, enums=%t: TryLookup %d != Lookup %d", fingerprintBits, enums, offset, reader.Lookup(key))
+				}
+			}
+			var accepted int
+			for i := 0; i < 10000; i++ {
+				if _, ok := reader.TryLookup([]byte(fmt.Sprintf("absent key %d", i))); ok {
+					accepted++
+				}
+			}
+			switch fingerprintBits {
+			case 0:
+				if accepted != 10000 {
+					t.Errorf("without fingerprints all keys are expected to be accepted, got %d", accepted)
+				}
+			case 8: // 39 expected
+				if accepted > 100 {
+					t.Errorf("bits=8, enums=%t: too many absent keys accepted: %d", enums, accepted)
+				}
+			case 16:
+				if accepted > 5 {
+					t.Errorf("bits=16, enums=%t: too many absent keys accepted: %d", enums, accepted)
+				}
+			}
+			idx.Close()
+		}
+	}
+
+	_, err := NewRecSplit(RecSplitArgs{KeyCount: 2, BucketSize: 10, TmpDir: t.TempDir(), IndexFile: "index", LeafSize: 8, FingerprintBits: 12})
+	if err == nil {
+		t.Errorf("test is expected to fail, unsupported fingerprint bits")
+	}
+}
+
+func TestIndexSingleKey(t *testing.T) {
+	for _, bits := range []int{0, 16} {
+		tmpDir := t.TempDir()
+		indexFile := filepath.Join(tmpDir, "index")
+		rs, err := NewRecSplit(RecSplitArgs{
+			KeyCount:        1,
+			BucketSize:      10,
+			Salt:            0,
+			TmpDir:          tmpDir,
+			IndexFile:       indexFile,
+			LeafSize:        8,
+			FingerprintBits: bits,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = rs.AddKey([]byte("first_key"), 42); err != nil {
+			t.Fatal(err)
+		}
+		if err = rs.Build(); err != nil {
+			t.Fatal(err)
+		}
+		idx := MustOpen(indexFile)
+		reader := NewIndexReader(idx)
+		// like Lookup, TryLookup returns 0 for the key of one-key index
+		if offset := reader.Lookup([]byte("first_key")); offset != 0 {
+			t.Errorf("fingerprint bits %d: expected offset: 0, looked up: %d", bits, offset)
+		}
+		if offset, ok := reader.TryLookup([]byte("first_key")); !ok || offset != 0 {
+			t.Errorf("fingerprint bits %d: expected offset: 0, looked up: %d, %t", bits, offset, ok)
+		}
+		if _, ok := reader.TryLookup([]byte("second_key")); ok == (bits > 0) {
+			t.Errorf("fingerprint bits %d: absent key is accepted: %t", bits, ok)
+		}
+		idx.Close()
+	}
+}
+
+func TestRecSplitWorkers(t *testing.T) {
+	build := func(t *testing.T, workers int, enums bool, fingerprintBits int) []byte {
+		tmpDir := t.TempDir()
+		indexFile := filepath.Join(tmpDir, "index")
+		rs, err := NewRecSplit(RecSplitArgs{
+			KeyCount:        10_000,
+			BucketSize:      100,
+			Salt:            1,
+			TmpDir:          tmpDir,
+			IndexFile:       indexFile,
+			LeafSize:        8,
+			Enums:           enums,
+			FingerprintBits: fingerprintBits,
+			Workers:         workers,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rs.Close()
+		for i := 0; i < 10_000; i++ {
+			if err = rs.AddKey([]byte(fmt.Sprintf("key %d", i)), uint64(i*17)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := rs.Build(); err != nil {
+			t.Fatal(err)
+		}
+		idx := MustOpen(indexFile)
+		defer idx.Close()
+		reader := NewIndexReader(idx)
+		for i := 0; i < 10_000; i++ {
+			offset := reader.Lookup([]byte(fmt.Sprintf("key %d", i)))
+			if enums {
+				offset = idx.OrdinalLookup(offset)
+			}
+			if offset != uint64(i*17) {
+				t.Fatalf("workers=%d: expected offset: %d, looked up: %d", workers, i*17, offset)
+			}
+		}
+		data, err := os.ReadFile(indexFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	for _, enums := range []bool{false, true} {
+		for _, fingerprintBits := range []int{0, 16} {
+			t.Run(fmt.Sprintf("enums=%t,fingerprints=%d", enums, fingerprintBits), func(t *testing.T) {
+				expected := build(t, 0, enums, fingerprintBits)
+				for _, workers := range []int{2, 3, 16} {
+					if !bytes.Equal(expected, build(t, workers, enums, fingerprintBits)) {
+						t.Errorf("index built on %d workers differs from the sequential one", workers)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRecSplitWorkersDuplicate(t *testing.T) {
+	tmpDir := t.TempDir()
+	rs, err := NewRecSplit(RecSplitArgs{
+		KeyCount:   1000,
+		BucketSize: 10,
+		Salt:       0,
+		TmpDir:     tmpDir,
+		IndexFile:  filepath.Join(tmpDir, "index"),
+		LeafSize:   8,
+		Workers:    4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	for i := 0; i < 999; i++ {
+		if err := rs.AddKey([]byte(fmt.Sprintf("key %d", i)), uint64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rs.AddKey([]byte("key 500"), 999); err != nil {
+		t.Fatal(err)
+	}
+	if err := rs.Build(); !errors.Is(err, ErrCollision) {
+		t.Errorf("test is expected to fail with collision, got: %v", err)
+	}
+	if !rs.Collision() {
+		t.Errorf("collision is expected to be detected")
+	}
+}
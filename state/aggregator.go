@@ -19,6 +19,7 @@ package state
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"math"
 	"math/bits"
@@ -155,6 +156,34 @@ func NewAggregator(dir, tmpdir string, aggregationStep uint64, commitmentMode Co
 
 func (a *Aggregator) SetDB(db kv.RwDB) { a.db = db }
 
+// EnableValueIndices - builds value indices for accounts (by code hash) and storage (by value).
+// Must be called before ReopenFolder.
+func (a *Aggregator) EnableValueIndices() {
+	a.accounts.SetValueProjection(accountCodeHashProjection, kv.AccountValueIdx)
+	a.storage.SetValueProjection(IdentityProjection, kv.StorageValueIdx)
+}
+
+// EnableExistenceFilters - build bloom filters for new files to skip negative lookups. Must be called before ReopenFolder.
+func (a *Aggregator) EnableExistenceFilters() {
+	a.accounts.EnableExistenceFilter()
+	a.storage.EnableExistenceFilter()
+	a.code.EnableExistenceFilter()
+	a.commitment.EnableExistenceFilter()
+	a.logAddrs.EnableExistenceFilter()
+	a.logTopics.EnableExistenceFilter()
+	a.tracesFrom.EnableExistenceFilter()
+	a.tracesTo.EnableExistenceFilter()
+}
+
+// accountCodeHashProjection - indexes accounts by code hash, accounts without code are skipped
+func accountCodeHashProjection(enc []byte) []byte {
+	_, _, codeHash := DecodeAccountBytes(enc)
+	if codeHash == nil || bytes.Equal(codeHash, commitment.EmptyCodeHash) {
+		return nil
+	}
+	return codeHash
+}
+
 func (a *Aggregator) buildMissedIdxBlocking(d *Domain) error {
 	eg, ctx := errgroup.WithContext(context.Background())
 	eg.SetLimit(32)
@@ -290,6 +319,10 @@ func (a *Aggregator) Close() {
 }
 
 func (a *Aggregator) SetTx(tx kv.RwTx) {
+	if a.rwTx != tx {
+		// branches cached from previous transaction could be rolled back
+		a.commitment.branchCache.Reset()
+	}
 	a.rwTx = tx
 	a.accounts.SetTx(tx)
 	a.storage.SetTx(tx)
@@ -335,6 +368,20 @@ func (a *Aggregator) SetCommitmentMode(mode CommitmentMode) {
 	a.commitment.mode = mode
 }
 
+// SetBranchCacheSize changes the number of commitment branches cached between blocks, 0 disables the cache
+func (a *Aggregator) SetBranchCacheSize(size int) {
+	a.commitment.branchCache = commitment.NewBranchCache(size)
+	if a.defaultCtx != nil {
+		a.commitment.patriciaTrie.ResetFns(a.commitment.cachedBranchFn(a.defaultCtx.branchFn), a.defaultCtx.accountFn, a.defaultCtx.storageFn)
+		setTrieCodeFn(a.commitment.patriciaTrie, a.defaultCtx.codeFn)
+	}
+}
+
+// BranchCacheStats returns number of hits and misses of commitment branch cache
+func (a *Aggregator) BranchCacheStats() (hits, misses uint64) {
+	return a.commitment.branchCache.Stats()
+}
+
 func (a *Aggregator) EndTxNumMinimax() uint64 {
 	min := a.accounts.endTxNumMinimax()
 	if txNum := a.storage.endTxNumMinimax(); txNum < min {
@@ -376,6 +423,7 @@ func (a *Aggregator) DomainEndTxNumMinimax() uint64 {
 }
 
 func (a *Aggregator) SeekCommitment() (blockNum, txNum uint64, err error) {
+	a.commitment.branchCache.Reset()
 	filesTxNum := a.EndTxNumMinimax()
 	blockNum, txNum, err = a.commitment.SeekCommitment(a.aggregationStep, filesTxNum)
 	if err != nil {
@@ -388,6 +436,99 @@ func (a *Aggregator) SeekCommitment() (blockNum, txNum uint64, err error) {
 	return blockNum, txNum + 1, nil
 }
 
+// Unwind reverts domains to the state before txUnwindTo: changes made at txUnwindTo and later are removed together
+// with their history. Commitment trie is restored from the latest state saved before txUnwindTo and checked against
+// the root saved with it; plain keys changed after that state are committed again. Returned root is the root of
+// state as of txUnwindTo, it's checked against the root saved at txUnwindTo-1 (if block ended there).
+// Unwind leaves aggregator at txUnwindTo. Only data which is not in files yet can be unwound.
+func (a *Aggregator) Unwind(ctx context.Context, txUnwindTo uint64) (rootHash []byte, err error) {
+	if a.defaultCtx == nil {
+		return nil, fmt.Errorf("unwind: aggregator context is not initialized, call StartWrites first")
+	}
+	// history is buffered until flush, it has to be in the db to be unwound
+	if err = a.Flush(ctx); err != nil {
+		return nil, err
+	}
+	a.SetTxNum(txUnwindTo)
+	for _, d := range []*Domain{a.accounts, a.storage, a.code, a.commitment.Domain} {
+		if err = d.unwind(ctx, txUnwindTo); err != nil {
+			return nil, err
+		}
+	}
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	for _, ii := range []*InvertedIndex{a.logAddrs, a.logTopics, a.tracesFrom, a.tracesTo} {
+		if err = ii.prune(ctx, txUnwindTo, math.MaxUint64, math.MaxUint64, logEvery); err != nil {
+			return nil, err
+		}
+	}
+
+	a.commitment.branchCache.Reset()
+	a.commitment.commTree.Clear(true)
+	cs, _, ok, err := a.commitment.restoreCommitmentState(txUnwindTo)
+	if err != nil {
+		return nil, fmt.Errorf("unwind commitment: %w", err)
+	}
+	var fromTxNum uint64
+	if ok {
+		fromTxNum = cs.txNum + 1
+	}
+	a.seekTxNum = fromTxNum
+	if fromTxNum < txUnwindTo {
+		if err = a.touchChangedKeys(fromTxNum, txUnwindTo); err != nil {
+			return nil, fmt.Errorf("unwind commitment: %w", err)
+		}
+	}
+	// changes made after the restored state are committed right away, so returned root is the one of state as of txUnwindTo
+	if rootHash, err = a.ComputeCommitment(false, false); err != nil {
+		return nil, fmt.Errorf("unwind commitment: %w", err)
+	}
+	if txUnwindTo == 0 {
+		return rootHash, nil
+	}
+	// root saved by the last kept tx (if it was the last tx of block) must match the recomputed one
+	savedRoot, _, ok, err := a.defaultCtx.CommitmentRootByTxNum(txUnwindTo-1, a.rwTx)
+	if err != nil {
+		return nil, fmt.Errorf("unwind commitment: %w", err)
+	}
+	if ok && !bytes.Equal(savedRoot, rootHash) {
+		return nil, fmt.Errorf("unwind commitment: root %x recomputed at txNum %d differs from saved root %x", rootHash, txUnwindTo, savedRoot)
+	}
+	return rootHash, nil
+}
+
+// touchChangedKeys marks plain keys changed in [fromTxNum, toTxNum) as updated for commitment
+func (a *Aggregator) touchChangedKeys(fromTxNum, toTxNum uint64) error {
+	touch := func(dc *DomainContext, fn func(c *CommitmentItem, val []byte)) error {
+		it, err := dc.hc.HistoryRange(int(fromTxNum), int(toTxNum), order.Asc, -1, a.rwTx)
+		if err != nil {
+			return err
+		}
+		if casted, ok := it.(iter.Closer); ok {
+			defer casted.Close()
+		}
+		for it.HasNext() {
+			k, _, err := it.Next()
+			if err != nil {
+				return err
+			}
+			v, err := dc.Get(k, nil, a.rwTx)
+			if err != nil {
+				return err
+			}
+			a.commitment.TouchPlainKey(k, v, fn)
+		}
+		return nil
+	}
+	if err := touch(a.defaultCtx.accounts, a.commitment.TouchPlainKeyAccount); err != nil {
+		return err
+	}
+	if err := touch(a.defaultCtx.storage, a.commitment.TouchPlainKeyStorage); err != nil {
+		return err
+	}
+	return touch(a.defaultCtx.code, a.commitment.TouchPlainKeyCode)
+}
+
 func (a *Aggregator) mergeDomainSteps(ctx context.Context) error {
 	mergeStartedAt := time.Now()
 	maxEndTxNum := a.DomainEndTxNumMinimax()
@@ -857,18 +998,34 @@ func (a *Aggregator) ComputeCommitment(saveStateAfter, trace bool) (rootHash []b
 
 	defer func(t time.Time) { mxCommitmentWriteTook.UpdateDuration(t) }(time.Now())
 
+	if err = a.applyBranchNodeUpdates(branchNodeUpdates, trace); err != nil {
+		return nil, err
+	}
+
+	if saveStateAfter {
+		if err := a.commitment.storeCommitmentState(a.blockNum, a.txNum, rootHash); err != nil {
+			return nil, err
+		}
+	}
+
+	return rootHash, nil
+}
+
+// applyBranchNodeUpdates merges branch updates produced by the trie into branches stored in commitment domain
+func (a *Aggregator) applyBranchNodeUpdates(branchNodeUpdates map[string]commitment.BranchData, trace bool) error {
+	a.commitment.branchCache.Invalidate(branchNodeUpdates)
 	for pref, update := range branchNodeUpdates {
 		prefix := []byte(pref)
 
 		stateValue, err := a.defaultCtx.ReadCommitment(prefix, a.rwTx)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		mxCommitmentUpdates.Inc()
 		stated := commitment.BranchData(stateValue)
 		merged, err := a.commitment.branchMerger.Merge(stated, update)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if bytes.Equal(stated, merged) {
 			continue
@@ -877,18 +1034,36 @@ func (a *Aggregator) ComputeCommitment(saveStateAfter, trace bool) (rootHash []b
 			fmt.Printf("computeCommitment merge [%x] [%x]+[%x]=>[%x]\n", prefix, stated, update, merged)
 		}
 		if err = a.UpdateCommitmentData(prefix, merged); err != nil {
-			return nil, err
+			return err
 		}
 		mxCommitmentUpdatesApplied.Inc()
 	}
+	return nil
+}
 
-	if saveStateAfter {
-		if err := a.commitment.storeCommitmentState(a.blockNum, a.txNum); err != nil {
-			return nil, err
-		}
+// ProveAccount returns eth_getProof-style proof of account and it's storage slots against the last computed
+// commitment root. Use commitment.AccountProof.Verify to check it.
+func (a *Aggregator) ProveAccount(addr []byte, storageKeys ...[]byte) (*commitment.AccountProof, error) {
+	if a.defaultCtx == nil {
+		return nil, fmt.Errorf("prove account: aggregator context is not initialized, call StartWrites first")
 	}
+	a.commitment.patriciaTrie.ResetFns(a.commitment.cachedBranchFn(a.defaultCtx.branchFn), a.defaultCtx.accountFn, a.defaultCtx.storageFn)
+	setTrieCodeFn(a.commitment.patriciaTrie, a.defaultCtx.codeFn)
+	return a.commitment.ProveAccount(addr, storageKeys...)
+}
 
-	return rootHash, nil
+// CommitmentRootByBlock returns commitment root saved at the end of given block, see AggregatorContext.CommitmentRootByBlock
+func (a *Aggregator) CommitmentRootByBlock(blockNum uint64, roTx kv.Tx) (rootHash []byte, txNum uint64, ok bool, err error) {
+	ac := a.MakeContext()
+	defer ac.Close()
+	return ac.CommitmentRootByBlock(blockNum, roTx)
+}
+
+// CommitmentRootByTxNum returns commitment root saved at given txNum, see AggregatorContext.CommitmentRootByTxNum
+func (a *Aggregator) CommitmentRootByTxNum(txNum uint64, roTx kv.Tx) (rootHash []byte, blockNum uint64, ok bool, err error) {
+	ac := a.MakeContext()
+	defer ac.Close()
+	return ac.CommitmentRootByTxNum(txNum, roTx)
 }
 
 // Provides channel which receives commitment hash each time aggregation is occured
@@ -918,7 +1093,8 @@ func (a *Aggregator) FinishTx() (err error) {
 	mxRunningMerges.Inc()
 	defer mxRunningMerges.Dec()
 
-	a.commitment.patriciaTrie.ResetFns(a.defaultCtx.branchFn, a.defaultCtx.accountFn, a.defaultCtx.storageFn)
+	a.commitment.patriciaTrie.ResetFns(a.commitment.cachedBranchFn(a.defaultCtx.branchFn), a.defaultCtx.accountFn, a.defaultCtx.storageFn)
+	setTrieCodeFn(a.commitment.patriciaTrie, a.defaultCtx.codeFn)
 	rootHash, err := a.ComputeCommitment(true, false)
 	if err != nil {
 		return err
@@ -959,7 +1135,14 @@ func (a *Aggregator) UpdateAccountCode(addr []byte, code []byte) error {
 }
 
 func (a *Aggregator) UpdateCommitmentData(prefix []byte, code []byte) error {
-	return a.commitment.Put(prefix, nil, code)
+	if err := a.commitment.Put(prefix, nil, code); err != nil {
+		return err
+	}
+	if len(code) >= 2 {
+		// keep cached branch in the same form as branchFn returns it
+		a.commitment.branchCache.Put(prefix, a.txNum/a.aggregationStep, code[2:])
+	}
+	return nil
 }
 
 func (a *Aggregator) DeleteAccount(addr []byte) error {
@@ -1036,7 +1219,8 @@ func (a *Aggregator) StartWrites() *Aggregator {
 		tracesFrom: a.tracesFrom.MakeContext(),
 		tracesTo:   a.tracesTo.MakeContext(),
 	}
-	a.commitment.patriciaTrie.ResetFns(a.defaultCtx.branchFn, a.defaultCtx.accountFn, a.defaultCtx.storageFn)
+	a.commitment.patriciaTrie.ResetFns(a.commitment.cachedBranchFn(a.defaultCtx.branchFn), a.defaultCtx.accountFn, a.defaultCtx.storageFn)
+	setTrieCodeFn(a.commitment.patriciaTrie, a.defaultCtx.codeFn)
 	return a
 }
 
@@ -1159,6 +1343,30 @@ func (ac *AggregatorContext) ReadCommitmentBeforeTxNum(addr []byte, txNum uint64
 	return v, err
 }
 
+// CommitmentRootByBlock returns commitment root saved at the end of given block, and txNum it was saved at.
+// ok=false if commitment state was not saved for the block.
+func (ac *AggregatorContext) CommitmentRootByBlock(blockNum uint64, roTx kv.Tx) (rootHash []byte, txNum uint64, ok bool, err error) {
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], blockNum)
+	v, err := ac.commitment.Get(keyCommitmentRootByBlock, key[:], roTx)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return decodeCommitmentRoot(v)
+}
+
+// CommitmentRootByTxNum returns commitment root saved at given txNum, and number of the block it belongs to.
+// ok=false if commitment state was not saved at the txNum.
+func (ac *AggregatorContext) CommitmentRootByTxNum(txNum uint64, roTx kv.Tx) (rootHash []byte, blockNum uint64, ok bool, err error) {
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], txNum)
+	v, err := ac.commitment.Get(keyCommitmentRootByTxNum, key[:], roTx)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return decodeCommitmentRoot(v)
+}
+
 func (ac *AggregatorContext) ReadAccountCodeBeforeTxNum(addr []byte, txNum uint64, roTx kv.Tx) ([]byte, error) {
 	v, err := ac.code.GetBeforeTxNum(addr, txNum, roTx)
 	return v, err
@@ -1180,6 +1388,16 @@ func (ac *AggregatorContext) ReadAccountCodeSizeBeforeTxNum(addr []byte, txNum u
 	return len(code), nil
 }
 
+// IterateAccountsByCodeHash - requires Aggregator.EnableValueIndices
+func (ac *AggregatorContext) IterateAccountsByCodeHash(codeHash []byte, roTx kv.Tx, it func(addr []byte) error) error {
+	return ac.accounts.IterateKeysByValue(codeHash, roTx, it)
+}
+
+// IterateStorageByValue - requires Aggregator.EnableValueIndices. `it` receives addr+loc keys
+func (ac *AggregatorContext) IterateStorageByValue(value []byte, roTx kv.Tx, it func(key []byte) error) error {
+	return ac.storage.IterateKeysByValue(value, roTx, it)
+}
+
 func (ac *AggregatorContext) branchFn(prefix []byte) ([]byte, error) {
 	// Look in the summary table first
 	stateValue, err := ac.ReadCommitment(prefix, ac.a.rwTx)
@@ -1223,6 +1441,18 @@ func (ac *AggregatorContext) accountFn(plainKey []byte, cell *commitment.Cell) e
 	return nil
 }
 
+func (ac *AggregatorContext) codeFn(addr []byte) ([]byte, error) {
+	return ac.ReadAccountCode(addr, ac.a.rwTx)
+}
+
+// setTrieCodeFn gives sparse Merkle trie access to contract code, it's leaves hold Poseidon hash of the code.
+// Other tries take code hash from account updates
+func setTrieCodeFn(trie commitment.Trie, codeFn func(addr []byte) ([]byte, error)) {
+	if smt, ok := trie.(*commitment.SparseMerkleTrie); ok {
+		smt.SetCodeFn(codeFn)
+	}
+}
+
 func (ac *AggregatorContext) storageFn(plainKey []byte, cell *commitment.Cell) error {
 	// Look in the summary table first
 	enc, err := ac.ReadAccountStorage(plainKey[:length.Addr], plainKey[length.Addr:], ac.a.rwTx)
@@ -1282,8 +1512,8 @@ func DecodeAccountBytes(enc []byte) (nonce uint64, balance *uint256.Int, hash []
 		codeHashBytes := int(enc[pos])
 		pos++
 		if codeHashBytes > 0 {
-			codeHash := make([]byte, length.Hash)
-			copy(codeHash, enc[pos:pos+codeHashBytes])
+			hash = make([]byte, length.Hash)
+			copy(hash, enc[pos:pos+codeHashBytes])
 		}
 	}
 	return
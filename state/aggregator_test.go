@@ -1,13 +1,16 @@
 package state
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -459,6 +462,121 @@ func TestAggregator_ReplaceCommittedKeys(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestAggregator_ProveAccount(t *testing.T) {
+	_, db, agg := testDbAndAggregator(t, 1000)
+	t.Cleanup(agg.Close)
+
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	agg.SetTx(tx)
+	defer agg.StartWrites().FinishWrites()
+
+	rnd := rand.New(rand.NewSource(0))
+	keys := make([][]byte, 100)
+	for txNum := uint64(1); txNum <= uint64(len(keys)); txNum++ {
+		agg.SetTxNum(txNum)
+
+		addr, loc := make([]byte, length.Addr), make([]byte, length.Hash)
+		rnd.Read(addr)
+		rnd.Read(loc)
+		keys[txNum-1] = append(addr, loc...)
+
+		err = agg.UpdateAccountData(addr, EncodeAccountBytes(txNum, uint256.NewInt(txNum*10), nil, 0))
+		require.NoError(t, err)
+		err = agg.WriteAccountStorage(addr, loc, []byte{addr[0], loc[0]})
+		require.NoError(t, err)
+	}
+	rootHash, err := agg.ComputeCommitment(false, false)
+	require.NoError(t, err)
+
+	for _, key := range keys {
+		addr, loc := key[:length.Addr], key[length.Addr:]
+		proof, err := agg.ProveAccount(addr, loc, make([]byte, length.Hash))
+		require.NoError(t, err)
+		account, storage, err := proof.Verify(rootHash)
+		require.NoError(t, err)
+		require.NotNil(t, account)
+		require.EqualValues(t, []byte{addr[0], loc[0]}, storage[0])
+		require.Nil(t, storage[1])
+	}
+
+	proof, err := agg.ProveAccount(make([]byte, length.Addr))
+	require.NoError(t, err)
+	account, _, err := proof.Verify(rootHash)
+	require.NoError(t, err)
+	require.Nil(t, account)
+}
+
+func TestAggregator_CommitmentRoots(t *testing.T) {
+	_, db, agg := testDbAndAggregator(t, 20)
+	t.Cleanup(agg.Close)
+
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}()
+	agg.SetTx(tx)
+	agg.StartWrites()
+
+	type savedRoot struct {
+		txNum    uint64
+		rootHash []byte
+	}
+	roots := make(map[uint64]savedRoot)
+	rnd := rand.New(rand.NewSource(0))
+	blockNum := uint64(1)
+	for txNum := uint64(1); txNum <= 200; txNum++ {
+		agg.SetTxNum(txNum)
+		agg.SetBlockNum(blockNum)
+
+		addr, loc := make([]byte, length.Addr), make([]byte, length.Hash)
+		rnd.Read(addr)
+		rnd.Read(loc)
+		require.NoError(t, agg.UpdateAccountData(addr, EncodeAccountBytes(txNum, uint256.NewInt(txNum), nil, 0)))
+		require.NoError(t, agg.WriteAccountStorage(addr, loc, []byte{addr[0], loc[0]}))
+
+		if txNum%5 == 4 { // block end
+			rootHash, err := agg.ComputeCommitment(true, false)
+			require.NoError(t, err)
+			roots[blockNum] = savedRoot{txNum: txNum, rootHash: rootHash}
+			blockNum++
+		}
+		require.NoError(t, agg.FinishTx())
+	}
+	agg.FinishWrites()
+	require.NoError(t, tx.Commit())
+	tx = nil
+
+	roTx, err := db.BeginRo(context.Background())
+	require.NoError(t, err)
+	defer roTx.Rollback()
+
+	for bn, saved := range roots {
+		rootHash, txNum, ok, err := agg.CommitmentRootByBlock(bn, roTx)
+		require.NoError(t, err)
+		require.True(t, ok, bn)
+		require.EqualValues(t, saved.txNum, txNum)
+		require.EqualValues(t, saved.rootHash, rootHash)
+
+		rootHash, blockNum, ok, err := agg.CommitmentRootByTxNum(saved.txNum, roTx)
+		require.NoError(t, err)
+		require.True(t, ok, saved.txNum)
+		require.EqualValues(t, bn, blockNum)
+		require.EqualValues(t, saved.rootHash, rootHash)
+	}
+
+	_, _, ok, err := agg.CommitmentRootByBlock(blockNum+1, roTx)
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, _, ok, err = agg.CommitmentRootByTxNum(roots[1].txNum+1, roTx)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
 func Test_EncodeCommitmentState(t *testing.T) {
 	cs := commitmentState{
 		txNum:     rand.Uint64(),
@@ -632,3 +750,337 @@ func Test_InitBtreeIndex(t *testing.T) {
 	require.EqualValues(t, bt.KeyCount(), keyCount)
 	bt.Close()
 }
+
+func Test_SparseKeyIndex_SeekAgreesWithBtIndex(t *testing.T) {
+	tmp := t.TempDir()
+	keyCount, M := 10000, uint64(16)
+
+	rnd := rand.New(rand.NewSource(0))
+	keys := make([][]byte, keyCount)
+	for i := range keys {
+		keys[i] = make([]byte, 20)
+		rnd.Read(keys[i])
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+	dataPath := path.Join(tmp, "sorted.kv")
+	comp, err := compress.NewCompressor(context.Background(), "cmp", dataPath, tmp, compress.MinPatternScore, 1, log.LvlDebug)
+	require.NoError(t, err)
+	comp.SetSparseKeyIndex(compress.DefaultSparseKeyIndexStep)
+	values := make([]byte, 100)
+	for _, key := range keys {
+		require.NoError(t, comp.AddWord(key))
+		n, _ := rnd.Read(values[:rnd.Intn(len(values))+1])
+		require.NoError(t, comp.AddWord(values[:n]))
+	}
+	require.NoError(t, comp.Compress())
+	comp.Close()
+
+	indexPath := path.Join(tmp, "sorted.bt")
+	require.NoError(t, BuildBtreeIndex(dataPath, indexPath))
+	bt, err := OpenBtreeIndex(indexPath, dataPath, M)
+	require.NoError(t, err)
+	defer bt.Close()
+
+	decomp, err := compress.NewDecompressor(dataPath)
+	require.NoError(t, err)
+	defer decomp.Close()
+	require.True(t, decomp.HasSparseKeyIndex())
+	getter := decomp.MakeGetter()
+
+	for i := 1; i < len(keys); i++ {
+		alt := common.Copy(keys[i])
+		for j := len(alt) - 1; j >= 0; j-- {
+			if alt[j] > 0 {
+				alt[j]--
+				break
+			}
+		}
+		for _, seek := range [][]byte{keys[i], alt} {
+			cur, err := bt.Seek(seek)
+			require.NoError(t, err)
+			key, value, ok, err := getter.SeekGE(seek)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, cur.Key(), key, "seek %x", seek)
+			require.Equal(t, cur.Value(), value, "seek %x", seek)
+		}
+	}
+}
+
+func TestAggregator_RebuildCommitment(t *testing.T) {
+	_, db, agg := testDbAndAggregator(t, 20)
+	t.Cleanup(agg.Close)
+
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	agg.SetTx(tx)
+	defer agg.StartWrites().FinishWrites()
+
+	rnd := rand.New(rand.NewSource(0))
+	addrs := make([][]byte, 30)
+	for i := range addrs {
+		addrs[i] = make([]byte, length.Addr)
+		rnd.Read(addrs[i])
+	}
+	roots := make(map[uint64][]byte) // txNum => root of state before it
+	for txNum := uint64(1); txNum <= 120; txNum++ {
+		agg.SetTxNum(txNum)
+		if txNum == 1 { // trie with single leaf as a root is not restored after Reset, start with a branch
+			for _, addr := range addrs {
+				require.NoError(t, agg.UpdateAccountData(addr, EncodeAccountBytes(0, uint256.NewInt(1), nil, 0)))
+			}
+		}
+		addr := addrs[rnd.Intn(len(addrs))]
+		loc := make([]byte, length.Hash)
+		loc[0] = byte(rnd.Intn(4))
+		require.NoError(t, agg.UpdateAccountData(addr, EncodeAccountBytes(txNum, uint256.NewInt(txNum), nil, 0)))
+		require.NoError(t, agg.WriteAccountStorage(addr, loc, []byte{byte(txNum)}))
+		switch txNum % 10 {
+		case 3:
+			require.NoError(t, agg.UpdateAccountCode(addr, []byte{0x60, byte(txNum)}))
+		case 7:
+			require.NoError(t, agg.DeleteAccount(addrs[rnd.Intn(len(addrs))]))
+		}
+
+		rootHash, err := agg.ComputeCommitment(true, false)
+		require.NoError(t, err)
+		roots[txNum+1] = rootHash
+		require.NoError(t, agg.FinishTx())
+	}
+	latestRoot := roots[121]
+
+	// subtests are not used: rwTx can't be used from another goroutine
+	rootHash, err := agg.RebuildCommitment(context.Background(), RebuildCommitmentCfg{BatchSize: 7})
+	require.NoError(t, err)
+	require.Equal(t, latestRoot, rootHash)
+
+	// trie keeps working after rebuild
+	rootHash, err = agg.ComputeCommitment(false, false)
+	require.NoError(t, err)
+	require.Equal(t, latestRoot, rootHash)
+
+	for _, asOf := range []uint64{45, 110} {
+		rootHash, err := agg.RebuildCommitment(context.Background(), RebuildCommitmentCfg{AsOfTxNum: asOf, BatchSize: 5})
+		require.NoError(t, err)
+		require.Equal(t, roots[asOf], rootHash, asOf)
+	}
+
+	// uninterrupted rebuild, to know how many batches full rebuild takes
+	var fullBatches int
+	rootHash, err = agg.RebuildCommitment(context.Background(), RebuildCommitmentCfg{BatchSize: 10, Checkpoint: func() error {
+		fullBatches++
+		return nil
+	}})
+	require.NoError(t, err)
+	require.Equal(t, latestRoot, rootHash)
+	require.Greater(t, fullBatches, 3)
+
+	// interrupted rebuild continues from checkpoint
+	interrupted := errors.New("interrupted")
+	var batches int
+	_, err = agg.RebuildCommitment(context.Background(), RebuildCommitmentCfg{BatchSize: 10, Checkpoint: func() error {
+		if batches++; batches == 3 {
+			return interrupted
+		}
+		return nil
+	}})
+	require.ErrorIs(t, err, interrupted)
+
+	_, err = agg.RebuildCommitment(context.Background(), RebuildCommitmentCfg{AsOfTxNum: 45, BatchSize: 10})
+	require.Error(t, err)
+
+	var resumedBatches int
+	rootHash, err = agg.RebuildCommitment(context.Background(), RebuildCommitmentCfg{BatchSize: 10, Checkpoint: func() error {
+		resumedBatches++
+		return nil
+	}})
+	require.NoError(t, err)
+	require.Equal(t, latestRoot, rootHash)
+	// checkpoint of the interrupting batch was saved before callback, so resumed run skips all batches done before
+	require.Equal(t, fullBatches-batches, resumedBatches)
+
+	// switch to sparse Merkle trie, which keeps evaluating commitment incrementally
+	smtRoot, err := agg.RebuildCommitment(context.Background(), RebuildCommitmentCfg{Variant: commitment.VariantSparseMerkleTrie, BatchSize: 10})
+	require.NoError(t, err)
+	require.NotEqual(t, latestRoot, smtRoot)
+	for txNum := uint64(121); txNum <= 130; txNum++ {
+		agg.SetTxNum(txNum)
+		addr := addrs[rnd.Intn(len(addrs))]
+		loc := make([]byte, length.Hash)
+		loc[0] = byte(rnd.Intn(4))
+		require.NoError(t, agg.UpdateAccountData(addr, EncodeAccountBytes(txNum, uint256.NewInt(txNum), nil, 0)))
+		require.NoError(t, agg.WriteAccountStorage(addr, loc, []byte{byte(txNum)}))
+		if txNum%5 == 0 {
+			require.NoError(t, agg.DeleteAccount(addrs[rnd.Intn(len(addrs))]))
+		}
+		smtRoot, err = agg.ComputeCommitment(true, false)
+		require.NoError(t, err)
+		require.NoError(t, agg.FinishTx())
+	}
+	rootHash, err = agg.RebuildCommitment(context.Background(), RebuildCommitmentCfg{BatchSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, smtRoot, rootHash)
+}
+
+func TestAggregator_BranchCache(t *testing.T) {
+	_, db, agg := testDbAndAggregator(t, 20)
+	t.Cleanup(agg.Close)
+	_, dbUncached, uncached := testDbAndAggregator(t, 20)
+	t.Cleanup(uncached.Close)
+	uncached.SetBranchCacheSize(0)
+
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	agg.SetTx(tx)
+	defer agg.StartWrites().FinishWrites()
+
+	txUncached, err := dbUncached.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer txUncached.Rollback()
+	uncached.SetTx(txUncached)
+	defer uncached.StartWrites().FinishWrites()
+
+	rnd := rand.New(rand.NewSource(0))
+	addrs := make([][]byte, 50)
+	for i := range addrs {
+		addrs[i] = make([]byte, length.Addr)
+		rnd.Read(addrs[i])
+	}
+	for txNum := uint64(1); txNum <= 200; txNum++ {
+		addr := addrs[rnd.Intn(len(addrs))]
+		loc := make([]byte, length.Hash)
+		loc[0] = byte(rnd.Intn(4))
+		deleted := addrs[rnd.Intn(len(addrs))]
+		for _, a := range []*Aggregator{agg, uncached} {
+			a.SetTxNum(txNum)
+			if txNum == 1 {
+				for _, addr := range addrs {
+					require.NoError(t, a.UpdateAccountData(addr, EncodeAccountBytes(0, uint256.NewInt(1), nil, 0)))
+				}
+			}
+			require.NoError(t, a.UpdateAccountData(addr, EncodeAccountBytes(txNum, uint256.NewInt(txNum), nil, 0)))
+			require.NoError(t, a.WriteAccountStorage(addr, loc, []byte{byte(txNum)}))
+			if txNum%10 == 7 {
+				require.NoError(t, a.DeleteAccount(deleted))
+			}
+		}
+		rootHash, err := agg.ComputeCommitment(true, false)
+		require.NoError(t, err)
+		expected, err := uncached.ComputeCommitment(true, false)
+		require.NoError(t, err)
+		require.Equal(t, expected, rootHash, txNum)
+
+		require.NoError(t, agg.FinishTx())
+		require.NoError(t, uncached.FinishTx())
+	}
+
+	hits, _ := agg.BranchCacheStats()
+	require.NotZero(t, hits)
+	hits, misses := uncached.BranchCacheStats()
+	require.Zero(t, hits+misses)
+}
+
+func TestAggregator_Unwind(t *testing.T) {
+	_, db, agg := testDbAndAggregator(t, 20)
+	t.Cleanup(agg.Close)
+	_, dbRef, ref := testDbAndAggregator(t, 20)
+	t.Cleanup(ref.Close)
+
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	agg.SetTx(tx)
+	defer agg.StartWrites().FinishWrites()
+
+	txRef, err := dbRef.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer txRef.Rollback()
+	ref.SetTx(txRef)
+	defer ref.StartWrites().FinishWrites()
+
+	addrs := make([][]byte, 30)
+	for i := range addrs {
+		addrs[i] = make([]byte, length.Addr)
+		rand.New(rand.NewSource(int64(i))).Read(addrs[i])
+	}
+	// execute applies changes of txNum in given fork and returns root if txNum is the last one in block
+	execute := func(a *Aggregator, txNum uint64, fork int64) []byte {
+		rnd := rand.New(rand.NewSource(int64(txNum)*10 + fork))
+		a.SetTxNum(txNum)
+		a.SetBlockNum(txNum / 5)
+		if txNum == 1 {
+			for _, addr := range addrs {
+				require.NoError(t, a.UpdateAccountData(addr, EncodeAccountBytes(0, uint256.NewInt(1), nil, 0)))
+			}
+		}
+		addr := addrs[rnd.Intn(len(addrs))]
+		loc := make([]byte, length.Hash)
+		loc[0] = byte(rnd.Intn(4))
+		require.NoError(t, a.UpdateAccountData(addr, EncodeAccountBytes(txNum, uint256.NewInt(txNum), nil, 0)))
+		require.NoError(t, a.WriteAccountStorage(addr, loc, []byte{byte(txNum), byte(fork)}))
+		switch rnd.Intn(10) {
+		case 3:
+			require.NoError(t, a.UpdateAccountCode(addr, []byte{0x60, byte(txNum)}))
+		case 7:
+			require.NoError(t, a.DeleteAccount(addrs[rnd.Intn(len(addrs))]))
+		}
+		var rootHash []byte
+		if txNum%5 == 4 {
+			rootHash, err = a.ComputeCommitment(true, false)
+			require.NoError(t, err)
+		}
+		require.NoError(t, a.FinishTx())
+		return rootHash
+	}
+
+	roots := make(map[uint64][]byte) // txNum => root of state before it
+	for txNum := uint64(1); txNum <= 115; txNum++ {
+		if rootHash := execute(agg, txNum, 0); rootHash != nil {
+			roots[txNum+1] = rootHash
+		}
+	}
+	for txNum := uint64(1); txNum < 105; txNum++ {
+		execute(ref, txNum, 0)
+	}
+
+	// subtests are not used: rwTx can't be used from another goroutine
+	_, err = agg.Unwind(context.Background(), 50)
+	require.Error(t, err, "data is already in files")
+
+	// unwind to block boundary, state is saved right before it
+	rootHash, err := agg.Unwind(context.Background(), 105)
+	require.NoError(t, err)
+	require.Equal(t, roots[105], rootHash)
+	_, _, ok, err := agg.CommitmentRootByTxNum(109, tx)
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, _, ok, err = agg.CommitmentRootByTxNum(104, tx)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	for txNum := uint64(105); txNum < 112; txNum++ {
+		require.Equal(t, execute(ref, txNum, 1), execute(agg, txNum, 1), txNum)
+	}
+	midBlockRoot, err := ref.ComputeCommitment(false, false)
+	require.NoError(t, err)
+	forkRoot, _, ok, err := agg.CommitmentRootByTxNum(109, tx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NotEqual(t, roots[110], forkRoot)
+
+	// unwind into the middle of block, changes after the saved state are committed by unwind
+	rootHash, err = agg.Unwind(context.Background(), 112)
+	require.NoError(t, err)
+	require.Equal(t, midBlockRoot, rootHash)
+	for txNum := uint64(112); txNum <= 115; txNum++ {
+		require.Equal(t, execute(ref, txNum, 2), execute(agg, txNum, 2), txNum)
+	}
+	expected, err := ref.ComputeCommitment(false, false)
+	require.NoError(t, err)
+	rootHash, err = agg.ComputeCommitment(false, false)
+	require.NoError(t, err)
+	require.Equal(t, expected, rootHash)
+}
@@ -79,6 +79,12 @@ type AggregatorV3 struct {
 	onFreeze OnFreezeFunc
 	walLock  sync.RWMutex
 
+	filesWriter   bool // see SetFilesWriter
+	manifestDirty bool // files were integrated, but manifest wasn't written. Guarded by filesMutationLock
+	// files replaced by merges while manifest wasn't written, they are deleted after it's written. Guarded by filesMutationLock
+	pendingDelete []*filesItem
+	pendingFrozen []MergedFilesV3
+
 	ps *background.ProgressSet
 }
 
@@ -114,38 +120,50 @@ func NewAggregatorV3(ctx context.Context, dir, tmpdir string, aggregationStep ui
 }
 func (a *AggregatorV3) OnFreeze(f OnFreezeFunc) { a.onFreeze = f }
 
+// SetFilesWriter - this process builds/merges files of the folder: OpenFolder removes orphan files and rewrites manifest.
+// Other processes (rpcdaemon, etc...) open folder read-only: orphans are ignored, manifest is not touched.
+func (a *AggregatorV3) SetFilesWriter(v bool) { a.filesWriter = v }
+
+// OpenFolder - opens files of folder, except orphans: files which are not in manifest but overlap with it's files
+// (outputs of interrupted merge, or already merged files). See manifest.go
+// Orphans are removed and manifest is rewritten only by files writer, see SetFilesWriter.
 func (a *AggregatorV3) OpenFolder() error {
 	a.filesMutationLock.Lock()
 	defer a.filesMutationLock.Unlock()
-	var err error
-	if err = a.accounts.OpenFolder(); err != nil {
-		return fmt.Errorf("OpenFolder: %w", err)
-	}
-	if err = a.storage.OpenFolder(); err != nil {
+	fNames, err := a.accounts.fileNamesOnDisk()
+	if err != nil {
 		return fmt.Errorf("OpenFolder: %w", err)
 	}
-	if err = a.code.OpenFolder(); err != nil {
+	m, err := ReadManifest(a.dir)
+	if err != nil {
 		return fmt.Errorf("OpenFolder: %w", err)
 	}
-	if err = a.logAddrs.OpenFolder(); err != nil {
-		return fmt.Errorf("OpenFolder: %w", err)
+	if m != nil {
+		if a.filesWriter {
+			fNames = m.removeOrphans(a.dir, fNames)
+		} else {
+			fNames = m.skipOrphans(fNames)
+		}
 	}
-	if err = a.logTopics.OpenFolder(); err != nil {
+	if err = a.openList(fNames); err != nil {
 		return fmt.Errorf("OpenFolder: %w", err)
 	}
-	if err = a.tracesFrom.OpenFolder(); err != nil {
-		return fmt.Errorf("OpenFolder: %w", err)
+	if !a.filesWriter {
+		return nil
 	}
-	if err = a.tracesTo.OpenFolder(); err != nil {
+	// files which don't overlap with manifest (downloaded, or built before manifest was introduced) become part of it
+	if err = a.saveManifest(); err != nil {
 		return fmt.Errorf("OpenFolder: %w", err)
 	}
-	a.recalcMaxTxNum()
 	return nil
 }
 func (a *AggregatorV3) OpenList(fNames []string) error {
 	a.filesMutationLock.Lock()
 	defer a.filesMutationLock.Unlock()
+	return a.openList(fNames)
+}
 
+func (a *AggregatorV3) openList(fNames []string) error {
 	var err error
 	if err = a.accounts.OpenList(fNames); err != nil {
 		return err
@@ -172,6 +190,73 @@ func (a *AggregatorV3) OpenList(fNames []string) error {
 	return nil
 }
 
+// manifest - current set of files, must be called under filesMutationLock
+func (a *AggregatorV3) manifest() *Manifest {
+	m := NewManifest()
+	m.Add(a.accounts.filenameBase, a.accounts.Files()...)
+	m.Add(a.storage.filenameBase, a.storage.Files()...)
+	m.Add(a.code.filenameBase, a.code.Files()...)
+	m.Add(a.logAddrs.filenameBase, a.logAddrs.Files()...)
+	m.Add(a.logTopics.filenameBase, a.logTopics.Files()...)
+	m.Add(a.tracesFrom.filenameBase, a.tracesFrom.Files()...)
+	m.Add(a.tracesTo.filenameBase, a.tracesTo.Files()...)
+	return m
+}
+
+// saveManifest - must be called under filesMutationLock, after new files are integrated.
+// Returns after manifest is fsync'ed, if it fails - manifestDirty stays set
+func (a *AggregatorV3) saveManifest() error {
+	a.manifestDirty = true
+	if err := a.manifest().Write(a.dir); err != nil {
+		return fmt.Errorf("save manifest: %w", err)
+	}
+	a.manifestDirty = false
+	a.deletePendingFiles()
+	return nil
+}
+
+// holdReplacedFiles - files which were in `before`, but were replaced by merge, are not deleted until manifest is written
+func (a *AggregatorV3) holdReplacedFiles(before []*filesItem, in MergedFilesV3) {
+	current := map[*filesItem]struct{}{}
+	for _, item := range a.allFilesItems() {
+		current[item] = struct{}{}
+	}
+	for _, item := range before {
+		if _, ok := current[item]; ok {
+			continue
+		}
+		item.canDelete.Store(false)
+		a.pendingDelete = append(a.pendingDelete, item)
+	}
+	a.pendingFrozen = append(a.pendingFrozen, in)
+}
+
+// deletePendingFiles - deletes files held by holdReplacedFiles, manifest doesn't list them anymore
+func (a *AggregatorV3) deletePendingFiles() {
+	for _, item := range a.pendingDelete {
+		// reference is held while flag is set: file is removed either here or by the last reader, not by both
+		item.refcount.Add(1)
+		item.canDelete.Store(true)
+		if item.refcount.Add(-1) == 0 {
+			item.closeFilesAndRemove()
+		}
+	}
+	for _, in := range a.pendingFrozen {
+		a.cleanFrozenParts(in)
+	}
+	a.pendingDelete, a.pendingFrozen = nil, nil
+}
+
+// syncManifest - writes manifest if some integration failed to write it
+func (a *AggregatorV3) syncManifest() error {
+	a.filesMutationLock.Lock()
+	defer a.filesMutationLock.Unlock()
+	if !a.manifestDirty {
+		return nil
+	}
+	return a.saveManifest()
+}
+
 func (a *AggregatorV3) Close() {
 	a.ctxCancel()
 	a.wg.Wait()
@@ -211,6 +296,17 @@ func (a *AggregatorV3) SetWorkers(i int) {
 	a.tracesTo.compressWorkers = i
 }
 
+// EnableExistenceFilters - build bloom filters for new files to skip negative lookups. Must be called before OpenFolder.
+func (a *AggregatorV3) EnableExistenceFilters() {
+	a.accounts.EnableExistenceFilter()
+	a.storage.EnableExistenceFilter()
+	a.code.EnableExistenceFilter()
+	a.logAddrs.EnableExistenceFilter()
+	a.logTopics.EnableExistenceFilter()
+	a.tracesFrom.EnableExistenceFilter()
+	a.tracesTo.EnableExistenceFilter()
+}
+
 func (a *AggregatorV3) HasBackgroundFilesBuild() bool { return a.ps.Has() }
 func (a *AggregatorV3) BackgroundProgress() string    { return a.ps.String() }
 
@@ -593,7 +689,10 @@ func (a *AggregatorV3) mergeLoopStep(ctx context.Context, workers int) (somethin
 			in.Close()
 		}
 	}()
-	a.integrateMergedFiles(outs, in)
+	if err = a.integrateMergedFiles(outs, in); err != nil {
+		closeAll = false // merged files are already integrated
+		return true, err
+	}
 	a.onFreeze(in.FrozenList())
 	closeAll = false
 	return true, nil
@@ -622,6 +721,11 @@ func (a *AggregatorV3) integrateFiles(sf AggV3StaticFiles, txNumFrom, txNumTo ui
 	a.logTopics.integrateFiles(sf.logTopics, txNumFrom, txNumTo)
 	a.tracesFrom.integrateFiles(sf.tracesFrom, txNumFrom, txNumTo)
 	a.tracesTo.integrateFiles(sf.tracesTo, txNumFrom, txNumTo)
+	// new files don't overlap with manifest, so they are not orphans even if manifest wasn't updated.
+	// Prune will retry to write it - before data of these files is removed from DB
+	if err := a.saveManifest(); err != nil {
+		log.Warn("[snapshots] integrate files", "err", err)
+	}
 }
 
 func (a *AggregatorV3) NeedSaveFilesListInDB() bool {
@@ -800,6 +904,10 @@ func (a *AggregatorV3) Prune(ctx context.Context, limit uint64) error {
 	//		_ = a.Warmup(ctx, 0, cmp.Max(a.aggregationStep, limit)) // warmup is asyn and moving faster than data deletion
 	//	}()
 	//}
+	// data is removed from DB only when files which have it are listed in manifest
+	if err := a.syncManifest(); err != nil {
+		return err
+	}
 	return a.prune(ctx, 0, a.minimaxTxNumInFiles.Load(), limit)
 }
 
@@ -1135,11 +1243,15 @@ func (a *AggregatorV3) mergeFiles(ctx context.Context, files SelectedStaticFiles
 	return mf, err
 }
 
-func (a *AggregatorV3) integrateMergedFiles(outs SelectedStaticFilesV3, in MergedFilesV3) (frozen []string) {
+// integrateMergedFiles - replaces merged files by result of merge. Replaced files are deleted (when last reader releases them)
+// only if new manifest was written: after crash they must be listed in manifest or be orphans.
+// Caller must hold context with replaced files - so they are not deleted before manifest is written.
+func (a *AggregatorV3) integrateMergedFiles(outs SelectedStaticFilesV3, in MergedFilesV3) error {
 	a.filesMutationLock.Lock()
 	defer a.filesMutationLock.Unlock()
 	defer a.needSaveFilesListInDB.Store(true)
 	defer a.recalcMaxTxNum()
+	before := a.allFilesItems()
 	a.accounts.integrateMergedFiles(outs.accountsIdx, outs.accountsHist, in.accountsIdx, in.accountsHist)
 	a.storage.integrateMergedFiles(outs.storageIdx, outs.storageHist, in.storageIdx, in.storageHist)
 	a.code.integrateMergedFiles(outs.codeIdx, outs.codeHist, in.codeIdx, in.codeHist)
@@ -1147,8 +1259,29 @@ func (a *AggregatorV3) integrateMergedFiles(outs SelectedStaticFilesV3, in Merge
 	a.logTopics.integrateMergedFiles(outs.logTopics, in.logTopics)
 	a.tracesFrom.integrateMergedFiles(outs.tracesFrom, in.tracesFrom)
 	a.tracesTo.integrateMergedFiles(outs.tracesTo, in.tracesTo)
+	if err := a.saveManifest(); err != nil {
+		// manifest on disk still lists replaced files, merged files are orphans there
+		a.holdReplacedFiles(before, in)
+		return fmt.Errorf("integrate merged files: %w", err)
+	}
 	a.cleanFrozenParts(in)
-	return frozen
+	return nil
+}
+
+// allFilesItems - files of all components, must be called under filesMutationLock
+func (a *AggregatorV3) allFilesItems() (res []*filesItem) {
+	collect := func(items []*filesItem) bool {
+		res = append(res, items...)
+		return true
+	}
+	for _, h := range []*History{a.accounts, a.storage, a.code} {
+		h.files.Walk(collect)
+		h.InvertedIndex.files.Walk(collect)
+	}
+	for _, ii := range []*InvertedIndex{a.logAddrs, a.logTopics, a.tracesFrom, a.tracesTo} {
+		ii.files.Walk(collect)
+	}
+	return res
 }
 func (a *AggregatorV3) cleanFrozenParts(in MergedFilesV3) {
 	a.accounts.cleanFrozenParts(in.accountsHist)
@@ -1407,6 +1540,22 @@ func (ac *AggregatorV3Context) CodeHistoricalStateRange(startTxNum uint64, from,
 	return ac.code.WalkAsOf(startTxNum, from, to, tx, limit)
 }
 
+// StateDiff - returns iterator over keys of given domain changed in [fromTxNum, toTxNum),
+// with their values before `fromTxNum` and as of `toTxNum`. Latest values are not stored by AggregatorV3,
+// so `latest` must provide current value for keys which were not changed since `toTxNum`.
+func (ac *AggregatorV3Context) StateDiff(domain kv.Domain, fromTxNum, toTxNum uint64, latest LatestStateReader, tx kv.Tx) (*StateDiffIter, error) {
+	switch domain {
+	case kv.AccountsDomain:
+		return ac.accounts.StateDiff(fromTxNum, toTxNum, latest, tx)
+	case kv.StorageDomain:
+		return ac.storage.StateDiff(fromTxNum, toTxNum, latest, tx)
+	case kv.CodeDomain:
+		return ac.code.StateDiff(fromTxNum, toTxNum, latest, tx)
+	default:
+		return nil, fmt.Errorf("StateDiff: unexpected domain %s", domain)
+	}
+}
+
 type FilesStats22 struct {
 }
 
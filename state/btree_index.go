@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math"
 	"math/bits"
@@ -430,7 +431,7 @@ func (a *btAlloc) bsKey(x []byte, l, r uint64) (*Cursor, error) {
 	}
 	k, v, err := a.dataLookup(l)
 	if err != nil {
-		return nil, fmt.Errorf("key >= %x was not found at pos %d", x, l)
+		return nil, fmt.Errorf("key >= %x was not found at pos %d: %w", x, l, err)
 	}
 	return a.newCursor(context.TODO(), k, v, l), nil
 }
@@ -803,6 +804,9 @@ func CreateBtreeIndex(indexPath, dataPath string, M uint64) (*BtIndex, error) {
 
 var DefaultBtreeM = uint64(2048)
 
+// ErrBtIndexLookupBounds - ordinal is out of index, e.g. sought key is greater than all keys of file
+var ErrBtIndexLookupBounds = errors.New("BtIndex: lookup di bounds error")
+
 func CreateBtreeIndexWithDecompressor(indexPath string, M uint64, decompressor *compress.Decompressor, p *background.Progress) (*BtIndex, error) {
 	err := BuildBtreeIndexWithDecompressor(indexPath, decompressor, p)
 	if err != nil {
@@ -992,20 +996,14 @@ func OpenBtreeIndex(indexPath, dataPath string, M uint64) (*BtIndex, error) {
 }
 
 func (b *BtIndex) dataLookup(di uint64) ([]byte, []byte, error) {
-	if b.keyCount < di {
-		return nil, nil, fmt.Errorf("ki is greater than key count in index")
+	if b.keyCount <= di {
+		return nil, nil, fmt.Errorf("%w: %d >= key count %d", ErrBtIndexLookupBounds, di, b.keyCount)
 	}
 
-	p := b.dataoffset + di*uint64(b.bytesPerRec)
-	if uint64(len(b.data)) < p+uint64(b.bytesPerRec) {
-		return nil, nil, fmt.Errorf("data lookup gone too far (%d after %d)", p+uint64(b.bytesPerRec)-uint64(len(b.data)), len(b.data))
+	offset, err := b.offset(di)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	offt := b.data[p : p+uint64(b.bytesPerRec)]
-	var aux [8]byte
-	copy(aux[8-len(offt):], offt)
-
-	offset := binary.BigEndian.Uint64(aux[:])
 	b.getter.Reset(offset)
 	if !b.getter.HasNext() {
 		return nil, nil, fmt.Errorf("pair %d not found", di)
@@ -1021,6 +1019,19 @@ func (b *BtIndex) dataLookup(di uint64) ([]byte, []byte, error) {
 	return key, val, nil
 }
 
+// offset returns position of the pair with given ordinal in data file
+func (b *BtIndex) offset(di uint64) (uint64, error) {
+	p := b.dataoffset + di*uint64(b.bytesPerRec)
+	if uint64(len(b.data)) < p+uint64(b.bytesPerRec) {
+		return 0, fmt.Errorf("data lookup gone too far (%d after %d)", p+uint64(b.bytesPerRec)-uint64(len(b.data)), len(b.data))
+	}
+
+	offt := b.data[p : p+uint64(b.bytesPerRec)]
+	var aux [8]byte
+	copy(aux[8-len(offt):], offt)
+	return binary.BigEndian.Uint64(aux[:]), nil
+}
+
 func (b *BtIndex) Size() int64 { return b.size }
 
 func (b *BtIndex) ModTime() time.Time { return b.modTime }
@@ -0,0 +1,431 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/gateway-fm/cdk-erigon-lib/commitment"
+	"github.com/gateway-fm/cdk-erigon-lib/common"
+	"github.com/gateway-fm/cdk-erigon-lib/common/length"
+	"github.com/gateway-fm/cdk-erigon-lib/etl"
+	"github.com/gateway-fm/cdk-erigon-lib/kv/iter"
+	"github.com/gateway-fm/cdk-erigon-lib/kv/order"
+)
+
+// RebuildCommitmentCfg - parameters of commitment rebuild, see Aggregator.RebuildCommitment
+type RebuildCommitmentCfg struct {
+	// AsOfTxNum - plain state is taken as it was before AsOfTxNum. 0 means latest state.
+	AsOfTxNum uint64
+	// Variant - trie variant to rebuild commitment with, rebuilt trie replaces trie of the aggregator.
+	// Empty means variant of the current trie.
+	Variant commitment.TrieVariant
+	// BatchSize - amount of keys evaluated by the trie at once. Bounds memory used by branch updates. Default 100_000.
+	BatchSize int
+	// Checkpoint is called after each batch, when progress is saved into commitment domain.
+	// Caller may Flush aggregator and commit transaction there (passing new one via SetTx), to make progress durable.
+	Checkpoint func() error
+}
+
+var keyCommitmentRebuild = []byte("rebuild")
+
+// rebuildCheckpoint - progress of the commitment rebuild, stored in commitment domain
+type rebuildCheckpoint struct {
+	asOfTxNum uint64
+	variant   commitment.TrieVariant
+	keys      uint64 // amount of processed keys
+	lastKey   []byte // hashed key of last processed key
+	trieState []byte
+}
+
+func (cp *rebuildCheckpoint) Encode() []byte {
+	buf := make([]byte, 0, 8+1+8+2+len(cp.lastKey)+2+len(cp.trieState))
+	buf = binary.BigEndian.AppendUint64(buf, cp.asOfTxNum)
+	buf = append(buf, byte(len(cp.variant)))
+	buf = append(buf, cp.variant...)
+	buf = binary.BigEndian.AppendUint64(buf, cp.keys)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(cp.lastKey)))
+	buf = append(buf, cp.lastKey...)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(cp.trieState)))
+	return append(buf, cp.trieState...)
+}
+
+func (cp *rebuildCheckpoint) Decode(buf []byte) error {
+	if len(buf) < 9 || len(buf) < 9+int(buf[8])+8+2 {
+		return fmt.Errorf("invalid commitment rebuild checkpoint size %d", len(buf))
+	}
+	cp.asOfTxNum = binary.BigEndian.Uint64(buf)
+	pos := 9 + int(buf[8])
+	cp.variant = commitment.TrieVariant(buf[9:pos])
+	cp.keys = binary.BigEndian.Uint64(buf[pos:])
+	pos += 8
+	for _, b := range []*[]byte{&cp.lastKey, &cp.trieState} {
+		if len(buf) < pos+2 {
+			return fmt.Errorf("invalid commitment rebuild checkpoint size %d", len(buf))
+		}
+		l := int(binary.BigEndian.Uint16(buf[pos:]))
+		pos += 2
+		if len(buf) < pos+l {
+			return fmt.Errorf("invalid commitment rebuild checkpoint size %d", len(buf))
+		}
+		*b = common.Copy(buf[pos : pos+l])
+		pos += l
+	}
+	return nil
+}
+
+// RebuildCommitment recomputes commitment from plain state of accounts, code and storage domains,
+// without replaying blocks. Useful when commitment domain is corrupted, or to switch trie variant.
+// Existing branches and trie states are deleted from commitment domain (history of roots is kept), then all keys
+// of plain state are hashed and sorted with etl.Collector and evaluated by the fresh trie in batches of cfg.BatchSize.
+// Progress is saved after each batch, so rebuild interrupted by error or crash continues from the last
+// checkpoint when called again with the same cfg.AsOfTxNum and cfg.Variant. Plain state must not change meanwhile.
+// Branches and commitment state are written at current txNum of the aggregator.
+func (a *Aggregator) RebuildCommitment(ctx context.Context, cfg RebuildCommitmentCfg) (rootHash []byte, err error) {
+	if a.defaultCtx == nil {
+		return nil, fmt.Errorf("rebuild commitment: aggregator context is not initialized, call StartWrites first")
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100_000
+	}
+	if cfg.Variant == "" {
+		cfg.Variant = a.commitment.patriciaTrie.Variant()
+	}
+	r := &commitmentRebuild{a: a, ac: a.MakeContext(), cfg: cfg, keccak: sha3.NewLegacyKeccak256()}
+	defer r.ac.Close()
+
+	checkpoint, err := r.a.defaultCtx.ReadCommitment(keyCommitmentRebuild, a.rwTx)
+	if err != nil {
+		return nil, err
+	}
+	if len(checkpoint) > 0 {
+		if err = r.checkpoint.Decode(checkpoint); err != nil {
+			return nil, err
+		}
+		if r.checkpoint.asOfTxNum != cfg.AsOfTxNum || r.checkpoint.variant != cfg.Variant {
+			return nil, fmt.Errorf("rebuild commitment: unfinished rebuild of %s trie as of txNum %d, can't start rebuild of %s trie as of txNum %d",
+				r.checkpoint.variant, r.checkpoint.asOfTxNum, cfg.Variant, cfg.AsOfTxNum)
+		}
+		log.Info("[commitment] resuming rebuild", "asOfTxNum", cfg.AsOfTxNum, "variant", cfg.Variant, "processed", r.checkpoint.keys)
+	} else {
+		r.checkpoint = rebuildCheckpoint{asOfTxNum: cfg.AsOfTxNum, variant: cfg.Variant}
+		if err = r.clearCommitment(ctx); err != nil {
+			return nil, fmt.Errorf("rebuild commitment: clear: %w", err)
+		}
+		if err = r.saveCheckpoint(); err != nil {
+			return nil, err
+		}
+	}
+
+	r.trie = commitment.InitializeTrie(cfg.Variant)
+	r.trie.ResetFns(a.commitment.cachedBranchFn(a.defaultCtx.branchFn), r.accountFn, r.storageFn)
+	setTrieCodeFn(r.trie, r.readCode)
+	if hph, ok := r.trie.(*commitment.HexPatriciaHashed); ok && len(r.checkpoint.trieState) > 0 {
+		if err = hph.SetState(r.checkpoint.trieState); err != nil {
+			return nil, fmt.Errorf("rebuild commitment: restore trie state: %w", err)
+		}
+	}
+
+	keys := etl.NewCollector("commitment rebuild", a.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	defer keys.Close()
+	if err = r.collectKeys(keys); err != nil {
+		return nil, fmt.Errorf("rebuild commitment: collect keys: %w", err)
+	}
+	if err = keys.Load(nil, "", r.processKey, etl.TransformArgs{Quit: ctx.Done()}); err != nil {
+		return nil, fmt.Errorf("rebuild commitment: %w", err)
+	}
+	if err = r.processBatch(); err != nil {
+		return nil, fmt.Errorf("rebuild commitment: %w", err)
+	}
+
+	if rootHash, err = r.trie.RootHash(); err != nil {
+		return nil, err
+	}
+	a.commitment.patriciaTrie = r.trie
+	a.commitment.patriciaTrie.ResetFns(a.commitment.cachedBranchFn(a.defaultCtx.branchFn), a.defaultCtx.accountFn, a.defaultCtx.storageFn)
+	setTrieCodeFn(a.commitment.patriciaTrie, a.defaultCtx.codeFn)
+	if cfg.Variant != commitment.VariantBinPatriciaTrie {
+		if err = a.commitment.storeCommitmentState(a.blockNum, a.txNum, rootHash); err != nil {
+			return nil, err
+		}
+	}
+	if err = a.commitment.Delete(keyCommitmentRebuild, nil); err != nil {
+		return nil, err
+	}
+	log.Info("[commitment] rebuild done", "asOfTxNum", cfg.AsOfTxNum, "variant", cfg.Variant, "keys", r.checkpoint.keys, "root", fmt.Sprintf("%x", rootHash))
+	return rootHash, nil
+}
+
+type commitmentRebuild struct {
+	a          *Aggregator
+	ac         *AggregatorContext
+	cfg        RebuildCommitmentCfg
+	trie       commitment.Trie
+	keccak     hash.Hash
+	checkpoint rebuildCheckpoint
+
+	plainKeys, hashedKeys [][]byte
+	updates               []commitment.Update
+	prevKey               []byte
+
+	account      []byte // hashed key of the last checked account, accounts go before their storage
+	accountFound bool
+}
+
+// clearCommitment deletes everything from commitment domain except history of roots
+func (r *commitmentRebuild) clearCommitment(ctx context.Context) error {
+	keys := etl.NewCollector("commitment rebuild", r.a.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	defer keys.Close()
+	var err error
+	if iterErr := r.ac.commitment.IteratePrefix(nil, func(k, _ []byte) {
+		if err != nil || bytes.HasPrefix(k, keyCommitmentRootByBlock) || bytes.HasPrefix(k, keyCommitmentRootByTxNum) {
+			return
+		}
+		err = keys.Collect(k, nil)
+	}); iterErr != nil {
+		return iterErr
+	}
+	if err != nil {
+		return err
+	}
+	defer r.a.commitment.branchCache.Reset()
+	return keys.Load(nil, "", func(k, _ []byte, _ etl.CurrentTableReader, _ etl.LoadNextFunc) error {
+		return r.a.commitment.Delete(k, nil)
+	}, etl.TransformArgs{Quit: ctx.Done()})
+}
+
+// collectKeys puts all keys of plain state into the collector as hashedKey => plainKey. Keys may repeat.
+func (r *commitmentRebuild) collectKeys(keys *etl.Collector) error {
+	var err error
+	collect := func(k, _ []byte) {
+		if err == nil {
+			err = keys.Collect(r.a.commitment.hashAndNibblizeKey(k), k)
+		}
+	}
+	for _, dc := range []*DomainContext{r.ac.accounts, r.ac.code, r.ac.storage} {
+		if iterErr := dc.IteratePrefix(nil, collect); iterErr != nil {
+			return iterErr
+		}
+		if err != nil {
+			return err
+		}
+		if r.cfg.AsOfTxNum == 0 {
+			continue
+		}
+		// keys changed after AsOfTxNum: some of them were deleted and are not visible in latest state
+		changed, iterErr := dc.hc.HistoryRange(int(r.cfg.AsOfTxNum), -1, order.Asc, -1, r.a.rwTx)
+		if iterErr != nil {
+			return iterErr
+		}
+		for changed.HasNext() && err == nil {
+			var k []byte
+			if k, _, err = changed.Next(); err == nil {
+				collect(k, nil)
+			}
+		}
+		if casted, ok := changed.(iter.Closer); ok {
+			casted.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *commitmentRebuild) processKey(hashedKey, plainKey []byte, _ etl.CurrentTableReader, _ etl.LoadNextFunc) error {
+	if bytes.Equal(hashedKey, r.prevKey) || (r.checkpoint.lastKey != nil && bytes.Compare(hashedKey, r.checkpoint.lastKey) <= 0) {
+		return nil
+	}
+	r.prevKey = append(r.prevKey[:0], hashedKey...)
+
+	update, ok, err := r.update(plainKey)
+	if err != nil {
+		return err
+	}
+	if ok && len(plainKey) > length.Addr {
+		// storage left in the domain after deletion of the account is not part of the state
+		ok, err = r.accountExists(hashedKey[:length.Hash*2], plainKey[:length.Addr])
+		if err != nil {
+			return err
+		}
+	}
+	if !ok {
+		return nil
+	}
+	r.plainKeys = append(r.plainKeys, common.Copy(plainKey))
+	r.hashedKeys = append(r.hashedKeys, common.Copy(hashedKey))
+	r.updates = append(r.updates, update)
+	if len(r.plainKeys) < r.cfg.BatchSize {
+		return nil
+	}
+	return r.processBatch()
+}
+
+// processBatch evaluates collected keys, writes branch updates and saves checkpoint
+func (r *commitmentRebuild) processBatch() error {
+	if len(r.plainKeys) == 0 {
+		return nil
+	}
+	defer func(t time.Time) {
+		log.Debug("[commitment] rebuild batch", "keys", len(r.plainKeys), "processed", r.checkpoint.keys, "took", time.Since(t))
+	}(time.Now())
+
+	_, branchNodeUpdates, err := r.trie.ProcessUpdates(r.plainKeys, r.hashedKeys, r.updates)
+	if err != nil {
+		return err
+	}
+	if err = r.a.applyBranchNodeUpdates(branchNodeUpdates, false); err != nil {
+		return err
+	}
+	last := len(r.plainKeys) - 1
+	r.checkpoint.keys += uint64(len(r.plainKeys))
+	r.checkpoint.lastKey = append(r.checkpoint.lastKey[:0], r.hashedKeys[last]...)
+	r.checkpoint.trieState = r.checkpoint.trieState[:0]
+	if hph, ok := r.trie.(*commitment.HexPatriciaHashed); ok {
+		if r.checkpoint.trieState, err = hph.EncodeCurrentState(r.checkpoint.trieState); err != nil {
+			return err
+		}
+	}
+	r.plainKeys, r.hashedKeys, r.updates = r.plainKeys[:0], r.hashedKeys[:0], r.updates[:0]
+	if err = r.saveCheckpoint(); err != nil {
+		return err
+	}
+	if r.cfg.Checkpoint != nil {
+		return r.cfg.Checkpoint()
+	}
+	return nil
+}
+
+func (r *commitmentRebuild) saveCheckpoint() error {
+	return r.a.commitment.Put(keyCommitmentRebuild, nil, r.checkpoint.Encode())
+}
+
+func (r *commitmentRebuild) accountExists(hashedKey, addr []byte) (bool, error) {
+	if bytes.Equal(hashedKey, r.account) {
+		return r.accountFound, nil
+	}
+	enc, codeHash, err := r.readAccount(addr)
+	if err != nil {
+		return false, err
+	}
+	r.account = append(r.account[:0], hashedKey...)
+	r.accountFound = len(enc) > 0 || codeHash != nil
+	return r.accountFound, nil
+}
+
+// update reads value of plain key as of cfg.AsOfTxNum. ok=false if key was absent.
+func (r *commitmentRebuild) update(plainKey []byte) (u commitment.Update, ok bool, err error) {
+	if len(plainKey) == length.Addr {
+		enc, codeHash, err := r.readAccount(plainKey)
+		if err != nil || len(enc) == 0 && codeHash == nil {
+			return u, false, err
+		}
+		u.Flags = commitment.BalanceUpdate | commitment.NonceUpdate | commitment.CodeUpdate
+		copy(u.CodeHashOrStorage[:], commitment.EmptyCodeHash)
+		if len(enc) > 0 {
+			nonce, balance, chash := DecodeAccountBytes(enc)
+			u.Nonce = nonce
+			u.Balance.Set(balance)
+			if chash != nil {
+				copy(u.CodeHashOrStorage[:], chash)
+			}
+		}
+		if codeHash != nil {
+			copy(u.CodeHashOrStorage[:], codeHash)
+		}
+		return u, true, nil
+	}
+	value, err := r.readStorage(plainKey)
+	if err != nil || len(value) == 0 {
+		return u, false, err
+	}
+	u.Flags = commitment.StorageUpdate
+	u.ValLength = len(value)
+	copy(u.CodeHashOrStorage[:], value)
+	return u, true, nil
+}
+
+// readAccount returns encoded account and hash of it's code (nil if there is no code)
+func (r *commitmentRebuild) readAccount(addr []byte) (enc, codeHash []byte, err error) {
+	var code []byte
+	if r.cfg.AsOfTxNum == 0 {
+		if enc, err = r.ac.ReadAccountData(addr, r.a.rwTx); err != nil {
+			return nil, nil, err
+		}
+		code, err = r.ac.ReadAccountCode(addr, r.a.rwTx)
+	} else {
+		if enc, err = r.ac.ReadAccountDataBeforeTxNum(addr, r.cfg.AsOfTxNum, r.a.rwTx); err != nil {
+			return nil, nil, err
+		}
+		code, err = r.ac.ReadAccountCodeBeforeTxNum(addr, r.cfg.AsOfTxNum, r.a.rwTx)
+	}
+	if err != nil || len(code) == 0 {
+		return enc, nil, err
+	}
+	r.keccak.Reset()
+	r.keccak.Write(code)
+	return enc, r.keccak.Sum(nil), nil
+}
+
+func (r *commitmentRebuild) readCode(addr []byte) ([]byte, error) {
+	if r.cfg.AsOfTxNum == 0 {
+		return r.ac.ReadAccountCode(addr, r.a.rwTx)
+	}
+	return r.ac.ReadAccountCodeBeforeTxNum(addr, r.cfg.AsOfTxNum, r.a.rwTx)
+}
+
+func (r *commitmentRebuild) readStorage(plainKey []byte) ([]byte, error) {
+	if r.cfg.AsOfTxNum == 0 {
+		return r.ac.ReadAccountStorage(plainKey[:length.Addr], plainKey[length.Addr:], r.a.rwTx)
+	}
+	return r.ac.ReadAccountStorageBeforeTxNum(plainKey[:length.Addr], plainKey[length.Addr:], r.cfg.AsOfTxNum, r.a.rwTx)
+}
+
+func (r *commitmentRebuild) accountFn(plainKey []byte, cell *commitment.Cell) error {
+	u, ok, err := r.update(plainKey)
+	if err != nil {
+		return err
+	}
+	cell.Nonce = u.Nonce
+	cell.Balance.Set(&u.Balance)
+	copy(cell.CodeHash[:], commitment.EmptyCodeHash)
+	if ok {
+		copy(cell.CodeHash[:], u.CodeHashOrStorage[:])
+	}
+	cell.Delete = !ok
+	return nil
+}
+
+func (r *commitmentRebuild) storageFn(plainKey []byte, cell *commitment.Cell) error {
+	value, err := r.readStorage(plainKey)
+	if err != nil {
+		return err
+	}
+	cell.StorageLen = len(value)
+	copy(cell.Storage[:], value)
+	cell.Delete = cell.StorageLen == 0
+	return nil
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/gateway-fm/cdk-erigon-lib/common"
 	"github.com/gateway-fm/cdk-erigon-lib/common/dir"
 	"github.com/gateway-fm/cdk-erigon-lib/compress"
+	"github.com/gateway-fm/cdk-erigon-lib/etl"
 	"github.com/gateway-fm/cdk-erigon-lib/kv"
 	"github.com/gateway-fm/cdk-erigon-lib/kv/bitmapdb"
 	"github.com/gateway-fm/cdk-erigon-lib/recsplit"
@@ -54,6 +55,13 @@ type filesItem struct {
 	startTxNum   uint64
 	endTxNum     uint64
 
+	// optional value index of domain files, see `Domain.SetValueProjection`
+	valIdxDecomp *compress.Decompressor
+	valIdxBt     *BtIndex
+
+	// optional bloom filter over keys of file, see `InvertedIndex.EnableExistenceFilter`
+	existence *ExistenceFilter
+
 	// Frozen: file of size StepsInBiggestFile. Completely immutable.
 	// Cold: file of size < StepsInBiggestFile. Immutable, but can be closed/removed after merge to bigger file.
 	// Hot: Stored in DB. Providing Snapshot-Isolation by CopyOnWrite.
@@ -103,6 +111,30 @@ func (i *filesItem) closeFilesAndRemove() {
 		}
 		i.bindex = nil
 	}
+	if i.valIdxBt != nil {
+		if err := i.valIdxBt.Close(); err != nil {
+			log.Trace("close", "err", err, "file", i.valIdxBt.FileName())
+		}
+		if err := os.Remove(i.valIdxBt.FilePath()); err != nil {
+			log.Trace("close", "err", err, "file", i.valIdxBt.FileName())
+		}
+		i.valIdxBt = nil
+	}
+	if i.valIdxDecomp != nil {
+		if err := i.valIdxDecomp.Close(); err != nil {
+			log.Trace("close", "err", err, "file", i.valIdxDecomp.FileName())
+		}
+		if err := os.Remove(i.valIdxDecomp.FilePath()); err != nil {
+			log.Trace("close", "err", err, "file", i.valIdxDecomp.FileName())
+		}
+		i.valIdxDecomp = nil
+	}
+	if i.existence != nil {
+		if err := os.Remove(i.existence.FilePath); err != nil {
+			log.Trace("close", "err", err, "file", i.existence.FileName)
+		}
+		i.existence = nil
+	}
 }
 
 type DomainStats struct {
@@ -120,6 +152,13 @@ type DomainStats struct {
 	DataSize       uint64
 	IndexSize      uint64
 	FilesCount     uint64
+
+	// existence filters of .kv files: "maybe" answers (file was probed) and "no" answers (file was skipped)
+	FilterHits  uint64
+	FilterSkips uint64
+	// existence filters of history .ef files, same as above
+	HistoryFilterHits  uint64
+	HistoryFilterSkips uint64
 }
 
 func (ds *DomainStats) Accumulate(other DomainStats) {
@@ -129,6 +168,10 @@ func (ds *DomainStats) Accumulate(other DomainStats) {
 	ds.IndexSize += other.IndexSize
 	ds.DataSize += other.DataSize
 	ds.FilesCount += other.FilesCount
+	ds.FilterHits += other.FilterHits
+	ds.FilterSkips += other.FilterSkips
+	ds.HistoryFilterHits += other.HistoryFilterHits
+	ds.HistoryFilterSkips += other.HistoryFilterSkips
 }
 
 // Domain is a part of the state (examples are Accounts, Storage, Code)
@@ -144,6 +187,10 @@ type Domain struct {
 	valsTable   string // key + invertedStep -> values
 	stats       DomainStats
 	mergesCount uint64
+
+	valueProjection ValueProjection // if not nil - value index files are built, see domain_value_index.go
+	valueIdxTable   string          // projection of latest value -> key, DB part of value index. Needs to be table with DupSort
+	kvFilter        filterCounters  // existence filters of .kv files, see DomainStats
 }
 
 func NewDomain(dir, tmpdir string, aggregationStep uint64,
@@ -206,8 +253,10 @@ func (d *Domain) OpenFolder() error {
 func (d *Domain) GetAndResetStats() DomainStats {
 	r := d.stats
 	r.DataSize, r.IndexSize, r.FilesCount = d.collectFilesStats()
+	r.FilterHits, r.FilterSkips = d.kvFilter.hits.Swap(0), d.kvFilter.skips.Swap(0)
+	r.HistoryFilterHits, r.HistoryFilterSkips = d.efFilter.hits.Swap(0), d.efFilter.skips.Swap(0)
 
-	d.stats = DomainStats{}
+	d.stats = DomainStats{HistoryQueries: &atomic.Uint64{}, TotalQueries: &atomic.Uint64{}}
 	return r
 }
 
@@ -260,12 +309,18 @@ func (d *Domain) scanStateFiles(fileNames []string) (uselessFiles []string) {
 				uselessFiles = append(uselessFiles,
 					fmt.Sprintf("%s.%d-%d.kv", d.filenameBase, subSet.startTxNum/d.aggregationStep, subSet.endTxNum/d.aggregationStep),
 					fmt.Sprintf("%s.%d-%d.kvi", d.filenameBase, subSet.startTxNum/d.aggregationStep, subSet.endTxNum/d.aggregationStep),
+					fmt.Sprintf("%s.%d-%d.vk", d.filenameBase, subSet.startTxNum/d.aggregationStep, subSet.endTxNum/d.aggregationStep),
+					fmt.Sprintf("%s.%d-%d.vkt", d.filenameBase, subSet.startTxNum/d.aggregationStep, subSet.endTxNum/d.aggregationStep),
+					fmt.Sprintf("%s.%d-%d.kvei", d.filenameBase, subSet.startTxNum/d.aggregationStep, subSet.endTxNum/d.aggregationStep),
 				)
 			}
 			if superSet != nil {
 				uselessFiles = append(uselessFiles,
 					fmt.Sprintf("%s.%d-%d.kv", d.filenameBase, startStep, endStep),
 					fmt.Sprintf("%s.%d-%d.kvi", d.filenameBase, startStep, endStep),
+					fmt.Sprintf("%s.%d-%d.vk", d.filenameBase, startStep, endStep),
+					fmt.Sprintf("%s.%d-%d.vkt", d.filenameBase, startStep, endStep),
+					fmt.Sprintf("%s.%d-%d.kvei", d.filenameBase, startStep, endStep),
 				)
 				continue
 			}
@@ -282,6 +337,15 @@ func (d *Domain) openFiles() (err error) {
 	d.files.Walk(func(items []*filesItem) bool {
 		for _, item := range items {
 			if item.decompressor != nil {
+				// value index may be built after .kv file was opened
+				if err = d.openValueIdx(item); err != nil {
+					log.Debug("Domain.openFiles: %w, %s", err, item.decompressor.FileName())
+					return false
+				}
+				if err = d.openExistenceFilter(item, "kvei"); err != nil {
+					log.Debug("Domain.openFiles: %w, %s", err, item.decompressor.FileName())
+					return false
+				}
 				continue
 			}
 			fromStep, toStep := item.startTxNum/d.aggregationStep, item.endTxNum/d.aggregationStep
@@ -313,6 +377,14 @@ func (d *Domain) openFiles() (err error) {
 				}
 				//totalKeys += item.bindex.KeyCount()
 			}
+			if err = d.openValueIdx(item); err != nil {
+				log.Debug("Domain.openFiles: %w, %s", err, item.decompressor.FileName())
+				return false
+			}
+			if err = d.openExistenceFilter(item, "kvei"); err != nil {
+				log.Debug("Domain.openFiles: %w, %s", err, item.decompressor.FileName())
+				return false
+			}
 		}
 		return true
 	})
@@ -360,6 +432,19 @@ func (d *Domain) closeWhatNotInList(fNames []string) {
 			}
 			item.bindex = nil
 		}
+		if item.valIdxBt != nil {
+			if err := item.valIdxBt.Close(); err != nil {
+				log.Trace("close", "err", err, "file", item.valIdxBt.FileName())
+			}
+			item.valIdxBt = nil
+		}
+		if item.valIdxDecomp != nil {
+			if err := item.valIdxDecomp.Close(); err != nil {
+				log.Trace("close", "err", err, "file", item.valIdxDecomp.FileName())
+			}
+			item.valIdxDecomp = nil
+		}
+		item.existence = nil
 		d.files.Delete(item)
 	}
 }
@@ -441,6 +526,9 @@ func (d *Domain) Put(key1, key2, val []byte) error {
 	if err = d.update(key, original); err != nil {
 		return err
 	}
+	if err = d.updateValueIdx(key, original, val); err != nil {
+		return err
+	}
 	invertedStep := ^(d.txNum / d.aggregationStep)
 	keySuffix := make([]byte, len(key)+8)
 	copy(keySuffix, key)
@@ -469,6 +557,9 @@ func (d *Domain) Delete(key1, key2 []byte) error {
 	if err = d.update(key, original); err != nil {
 		return err
 	}
+	if err = d.updateValueIdx(key, original, nil); err != nil {
+		return err
+	}
 	invertedStep := ^(d.txNum / d.aggregationStep)
 	keySuffix := make([]byte, len(key)+8)
 	copy(keySuffix, key)
@@ -674,7 +765,7 @@ func (dc *DomainContext) IteratePrefix(prefix []byte, it func(k, v []byte)) erro
 	if k, v, err = keysCursor.Seek(prefix); err != nil {
 		return err
 	}
-	if bytes.HasPrefix(k, prefix) {
+	if k != nil && bytes.HasPrefix(k, prefix) {
 		keySuffix := make([]byte, len(k)+8)
 		copy(keySuffix, k)
 		copy(keySuffix[len(k):], v)
@@ -1025,10 +1116,14 @@ type StaticFiles struct {
 	valuesDecomp    *compress.Decompressor
 	valuesIdx       *recsplit.Index
 	valuesBt        *BtIndex
+	valIdxDecomp    *compress.Decompressor
+	valIdxBt        *BtIndex
+	valuesExistence *ExistenceFilter
 	historyDecomp   *compress.Decompressor
 	historyIdx      *recsplit.Index
 	efHistoryDecomp *compress.Decompressor
 	efHistoryIdx    *recsplit.Index
+	efExistence     *ExistenceFilter
 }
 
 func (sf StaticFiles) Close() {
@@ -1041,6 +1136,12 @@ func (sf StaticFiles) Close() {
 	if sf.valuesBt != nil {
 		sf.valuesBt.Close()
 	}
+	if sf.valIdxBt != nil {
+		sf.valIdxBt.Close()
+	}
+	if sf.valIdxDecomp != nil {
+		sf.valIdxDecomp.Close()
+	}
 	if sf.historyDecomp != nil {
 		sf.historyDecomp.Close()
 	}
@@ -1070,6 +1171,8 @@ func (d *Domain) buildFiles(ctx context.Context, step uint64, collation Collatio
 	valuesComp := collation.valuesComp
 	var valuesDecomp *compress.Decompressor
 	var valuesIdx *recsplit.Index
+	var valIdxDecomp *compress.Decompressor
+	var valIdxBt *BtIndex
 	closeComp := true
 	defer func() {
 		if closeComp {
@@ -1083,6 +1186,12 @@ func (d *Domain) buildFiles(ctx context.Context, step uint64, collation Collatio
 			if valuesIdx != nil {
 				valuesIdx.Close()
 			}
+			if valIdxBt != nil {
+				valIdxBt.Close()
+			}
+			if valIdxDecomp != nil {
+				valIdxDecomp.Close()
+			}
 		}
 	}()
 	if err = valuesComp.Compress(); err != nil {
@@ -1116,15 +1225,38 @@ func (d *Domain) buildFiles(ctx context.Context, step uint64, collation Collatio
 		}
 	}
 
+	if d.valueProjection != nil {
+		if valIdxDecomp, valIdxBt, err = d.buildValueIdx(ctx, valuesDecomp, step, step+1, ps); err != nil {
+			bt.Close()
+			return StaticFiles{}, err
+		}
+	}
+
+	var valuesExistence *ExistenceFilter
+	if d.withExistenceFilter {
+		if valuesExistence, err = d.buildExistence(ctx, valuesDecomp, step, step+1, "kvei", ps); err != nil {
+			bt.Close()
+			if valIdxBt != nil {
+				valIdxBt.Close()
+				valIdxDecomp.Close()
+			}
+			return StaticFiles{}, fmt.Errorf("build %s values existence filter: %w", d.filenameBase, err)
+		}
+	}
+
 	closeComp = false
 	return StaticFiles{
 		valuesDecomp:    valuesDecomp,
 		valuesIdx:       valuesIdx,
 		valuesBt:        bt,
+		valIdxDecomp:    valIdxDecomp,
+		valIdxBt:        valIdxBt,
+		valuesExistence: valuesExistence,
 		historyDecomp:   hStaticFiles.historyDecomp,
 		historyIdx:      hStaticFiles.historyIdx,
 		efHistoryDecomp: hStaticFiles.efHistoryDecomp,
 		efHistoryIdx:    hStaticFiles.efHistoryIdx,
+		efExistence:     hStaticFiles.efExistence,
 	}, nil
 }
 
@@ -1143,8 +1275,7 @@ func (d *Domain) missedIdxFiles() (l []*filesItem) {
 
 // BuildMissedIndices - produce .efi/.vi/.kvi from .ef/.v/.kv
 func (d *Domain) BuildMissedIndices(ctx context.Context, g *errgroup.Group, ps *background.ProgressSet) (err error) {
-	d.History.BuildMissedIndices(ctx, g, ps)
-	d.InvertedIndex.BuildMissedIndices(ctx, g, ps)
+	d.History.BuildMissedIndices(ctx, g, ps) // also builds missed files of InvertedIndex
 	for _, item := range d.missedIdxFiles() {
 		//TODO: build .kvi
 		fitem := item
@@ -1160,6 +1291,30 @@ func (d *Domain) BuildMissedIndices(ctx context.Context, g *errgroup.Group, ps *
 			return nil
 		})
 	}
+	for _, item := range d.missedValueIdxFiles() {
+		fitem := item
+		g.Go(func() error {
+			fromStep, toStep := fitem.startTxNum/d.aggregationStep, fitem.endTxNum/d.aggregationStep
+			valIdxDecomp, valIdxBt, err := d.buildValueIdx(ctx, fitem.decompressor, fromStep, toStep, ps)
+			if err != nil {
+				return fmt.Errorf("failed to build value index for %s:  %w", fitem.decompressor.FileName(), err)
+			}
+			// files are re-opened by `openFiles` after build
+			valIdxBt.Close()
+			valIdxDecomp.Close()
+			return nil
+		})
+	}
+	for _, item := range d.missedExistenceFilterFiles(d.files, "kvei") {
+		fitem := item
+		g.Go(func() error {
+			fromStep, toStep := fitem.startTxNum/d.aggregationStep, fitem.endTxNum/d.aggregationStep
+			if _, err := d.buildExistence(ctx, fitem.decompressor, fromStep, toStep, "kvei", ps); err != nil {
+				return fmt.Errorf("failed to build existence filter for %s:  %w", fitem.decompressor.FileName(), err)
+			}
+			return nil
+		})
+	}
 	return nil
 }
 
@@ -1232,6 +1387,7 @@ func (d *Domain) integrateFiles(sf StaticFiles, txNumFrom, txNumTo uint64) {
 		historyIdx:      sf.historyIdx,
 		efHistoryDecomp: sf.efHistoryDecomp,
 		efHistoryIdx:    sf.efHistoryIdx,
+		efExistence:     sf.efExistence,
 	}, txNumFrom, txNumTo)
 	d.files.Set(&filesItem{
 		frozen:       (txNumTo-txNumFrom)/d.aggregationStep == StepsInBiggestFile,
@@ -1240,6 +1396,9 @@ func (d *Domain) integrateFiles(sf StaticFiles, txNumFrom, txNumTo uint64) {
 		decompressor: sf.valuesDecomp,
 		index:        sf.valuesIdx,
 		bindex:       sf.valuesBt,
+		valIdxDecomp: sf.valIdxDecomp,
+		valIdxBt:     sf.valIdxBt,
+		existence:    sf.valuesExistence,
 	})
 	d.reCalcRoFiles()
 }
@@ -1373,6 +1532,84 @@ func (d *Domain) prune(ctx context.Context, step uint64, txFrom, txTo, limit uin
 	return nil
 }
 
+// unwind reverts changes made at txUnwindTo and later: values of changed keys are restored from history
+// and history of these changes is removed. Only data which is not in files yet can be unwound.
+func (d *Domain) unwind(ctx context.Context, txUnwindTo uint64) error {
+	if filesTxNum := d.endTxNumMinimax(); txUnwindTo < filesTxNum {
+		return fmt.Errorf("unwind %s to %d: data up to %d is already in files", d.filenameBase, txUnwindTo, filesTxNum)
+	}
+	idxC, err := d.tx.RwCursorDupSort(d.indexTable)
+	if err != nil {
+		return err
+	}
+	defer idxC.Close()
+
+	// history keeps values before each change, the oldest of them is the value as of txUnwindTo
+	restore := etl.NewCollector(d.filenameBase+" unwind", d.tmpdir, etl.NewOldestEntryBuffer(etl.BufferOptimalSize))
+	defer restore.Close()
+	var txKey [8]byte
+	if err = d.History.pruneF(txUnwindTo, math.MaxUint64, func(txNum uint64, k, v []byte) error {
+		binary.BigEndian.PutUint64(txKey[:], txNum)
+		if err := idxC.DeleteExact(k, txKey[:]); err != nil {
+			return err
+		}
+		return restore.Collect(k, v)
+	}); err != nil {
+		return fmt.Errorf("unwind %s history: %w", d.filenameBase, err)
+	}
+
+	keysC, err := d.tx.RwCursorDupSort(d.keysTable)
+	if err != nil {
+		return err
+	}
+	defer keysC.Close()
+	var invertedStep [8]byte
+	binary.BigEndian.PutUint64(invertedStep[:], ^(txUnwindTo / d.aggregationStep))
+	dc := d.MakeContext()
+	defer dc.Close()
+	return restore.Load(nil, "", func(k, v []byte, _ etl.CurrentTableReader, _ etl.LoadNextFunc) error {
+		if d.valueProjection != nil {
+			// value index has the latest value of the key, move it to the restored one
+			latest, _, err := dc.get(k, math.MaxUint64, d.tx)
+			if err != nil {
+				return err
+			}
+			if err = d.updateValueIdx(k, latest, v); err != nil {
+				return err
+			}
+		}
+		// drop values of the key written at the step of txUnwindTo and later
+		var steps [][]byte
+		var s []byte
+		for _, s, err = keysC.SeekExact(k); err == nil && s != nil && bytes.Compare(s, invertedStep[:]) <= 0; _, s, err = keysC.NextDup() {
+			steps = append(steps, common.Copy(s))
+		}
+		if err != nil {
+			return err
+		}
+		keySuffix := make([]byte, len(k)+8)
+		copy(keySuffix, k)
+		for _, s := range steps {
+			if err := keysC.DeleteExact(k, s); err != nil {
+				return err
+			}
+			copy(keySuffix[len(k):], s)
+			if err := d.tx.Delete(d.valsTable, keySuffix); err != nil {
+				return err
+			}
+		}
+		// restored value belongs to the step of txUnwindTo, empty value is stored as a deletion mark
+		if err := d.tx.Put(d.keysTable, k, invertedStep[:]); err != nil {
+			return err
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		copy(keySuffix[len(k):], invertedStep[:])
+		return d.tx.Put(d.valsTable, keySuffix, v)
+	}, etl.TransformArgs{Quit: ctx.Done()})
+}
+
 func (d *Domain) isEmpty(tx kv.Tx) (bool, error) {
 	k, err := kv.FirstKey(tx, d.keysTable)
 	if err != nil {
@@ -1451,6 +1688,9 @@ func (dc *DomainContext) readFromFiles(filekey []byte, fromTxNum uint64) ([]byte
 		if dc.files[i].endTxNum < fromTxNum {
 			break
 		}
+		if !dc.d.kvFilter.mayContain(dc.files[i].src, filekey) {
+			continue
+		}
 		reader := dc.statelessBtree(i)
 		if reader.Empty() {
 			continue
@@ -73,6 +73,9 @@ func ParseCommitmentMode(s string) CommitmentMode {
 	return mode
 }
 
+// DefaultBranchCacheSize - number of branches kept in commitment branch cache by default
+const DefaultBranchCacheSize = 16384
+
 type ValueMerger func(prev, current []byte) (merged []byte, err error)
 
 type DomainCommitted struct {
@@ -83,6 +86,7 @@ type DomainCommitted struct {
 	keccak       hash.Hash
 	patriciaTrie commitment.Trie
 	branchMerger *commitment.BranchMerger
+	branchCache  *commitment.BranchCache
 
 	comKeys uint64
 	comTook time.Duration
@@ -96,11 +100,17 @@ func NewCommittedDomain(d *Domain, mode CommitmentMode, trieVariant commitment.T
 		keccak:       sha3.NewLegacyKeccak256(),
 		mode:         mode,
 		branchMerger: commitment.NewHexBranchMerger(8192),
+		branchCache:  commitment.NewBranchCache(DefaultBranchCacheSize),
 	}
 }
 
 func (d *DomainCommitted) SetCommitmentMode(m CommitmentMode) { d.mode = m }
 
+// cachedBranchFn wraps branchFn with branch cache, cached branches are tagged with current step of the domain
+func (d *DomainCommitted) cachedBranchFn(branchFn func(prefix []byte) ([]byte, error)) func(prefix []byte) ([]byte, error) {
+	return d.branchCache.WrapBranchFn(branchFn, func() uint64 { return d.txNum / d.aggregationStep })
+}
+
 // TouchPlainKey marks plainKey as updated and applies different fn for different key types
 // (different behaviour for Code, Account and Storage key modifications).
 func (d *DomainCommitted) TouchPlainKey(key, val []byte, fn func(c *CommitmentItem, val []byte)) {
@@ -219,7 +229,7 @@ func (d *DomainCommitted) hashAndNibblizeKey(key []byte) []byte {
 	return nibblized
 }
 
-func (d *DomainCommitted) storeCommitmentState(blockNum, txNum uint64) error {
+func (d *DomainCommitted) storeCommitmentState(blockNum, txNum uint64, rootHash []byte) error {
 	var state []byte
 	var err error
 
@@ -229,6 +239,8 @@ func (d *DomainCommitted) storeCommitmentState(blockNum, txNum uint64) error {
 		if err != nil {
 			return err
 		}
+	case *commitment.SparseMerkleTrie:
+		// root branch is stored as regular branch, there is no trie state to keep
 	default:
 		return fmt.Errorf("unsupported state storing for patricia trie type: %T", d.patriciaTrie)
 	}
@@ -244,7 +256,42 @@ func (d *DomainCommitted) storeCommitmentState(blockNum, txNum uint64) error {
 	if err = d.Domain.Put(keyCommitmentState, stepbuf[:], encoded); err != nil {
 		return err
 	}
-	return nil
+	return d.storeCommitmentRoot(blockNum, txNum, rootHash)
+}
+
+var (
+	keyCommitmentRootByBlock = []byte("rootb")
+	keyCommitmentRootByTxNum = []byte("roott")
+)
+
+// storeCommitmentRoot puts root hash into history of roots, indexed both by block number and txNum.
+// Roots are regular records of the domain, so they are collated and merged together with branches.
+//   - rootb + blockNum(8 bytes) => txNum(8 bytes) + rootHash
+//   - roott + txNum(8 bytes) => blockNum(8 bytes) + rootHash
+func (d *DomainCommitted) storeCommitmentRoot(blockNum, txNum uint64, rootHash []byte) error {
+	var key [8]byte
+	val := make([]byte, 8+len(rootHash))
+	copy(val[8:], rootHash)
+
+	binary.BigEndian.PutUint64(key[:], blockNum)
+	binary.BigEndian.PutUint64(val, txNum)
+	if err := d.Domain.Put(keyCommitmentRootByBlock, key[:], val); err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint64(key[:], txNum)
+	binary.BigEndian.PutUint64(val, blockNum)
+	return d.Domain.Put(keyCommitmentRootByTxNum, key[:], val)
+}
+
+// decodeCommitmentRoot splits value of roots history record into number (txNum or blockNum) and root hash
+func decodeCommitmentRoot(v []byte) (rootHash []byte, num uint64, ok bool, err error) {
+	if len(v) == 0 {
+		return nil, 0, false, nil
+	}
+	if len(v) != 8+length.Hash {
+		return nil, 0, false, fmt.Errorf("invalid commitment root record size %d", len(v))
+	}
+	return common.Copy(v[8:]), binary.BigEndian.Uint64(v[:8]), true, nil
 }
 
 // nolint
@@ -583,13 +630,25 @@ func (d *DomainCommitted) ComputeCommitment(trace bool) (rootHash []byte, branch
 	return rootHash, branchNodeUpdates, err
 }
 
+// ProveAccount produces Merkle proof of account and it's storage slots from committed branch data
+// against the root of the last evaluated (or restored by SeekCommitment) commitment
+func (d *DomainCommitted) ProveAccount(addr []byte, storageKeys ...[]byte) (*commitment.AccountProof, error) {
+	hph, ok := d.patriciaTrie.(*commitment.HexPatriciaHashed)
+	if !ok {
+		return nil, fmt.Errorf("proofs are only supported by hex patricia trie")
+	}
+	return hph.ProveAccount(addr, storageKeys...)
+}
+
 var keyCommitmentState = []byte("state")
 
 // SeekCommitment searches for last encoded state from DomainCommitted
 // and if state found, sets it up to current domain
 func (d *DomainCommitted) SeekCommitment(aggStep, sinceTx uint64) (blockNum, txNum uint64, err error) {
-	if d.patriciaTrie.Variant() != commitment.VariantHexPatriciaTrie {
-		return 0, 0, fmt.Errorf("state storing is only supported hex patricia trie")
+	switch d.patriciaTrie.Variant() {
+	case commitment.VariantHexPatriciaTrie, commitment.VariantSparseMerkleTrie:
+	default:
+		return 0, 0, fmt.Errorf("state storing is only supported hex patricia trie and sparse merkle trie")
 	}
 	// todo add support of bin state dumping
 
@@ -633,6 +692,8 @@ func (d *DomainCommitted) SeekCommitment(aggStep, sinceTx uint64) (blockNum, txN
 		if err := hext.SetState(latest.trieState); err != nil {
 			return 0, 0, err
 		}
+	} else if smt, ok := d.patriciaTrie.(*commitment.SparseMerkleTrie); ok {
+		smt.Reset()
 	} else {
 		return 0, 0, fmt.Errorf("state storing is only supported hex patricia trie")
 	}
@@ -640,6 +701,61 @@ func (d *DomainCommitted) SeekCommitment(aggStep, sinceTx uint64) (blockNum, txN
 	return latest.blockNum, latest.txNum, nil
 }
 
+// restoreCommitmentState sets up the trie from the latest state saved before txNum and checks that the trie
+// evaluates the root saved together with that state. ok is false if there is no such state, trie is empty then.
+func (d *DomainCommitted) restoreCommitmentState(txNum uint64) (cs commitmentState, rootHash []byte, ok bool, err error) {
+	switch d.patriciaTrie.Variant() {
+	case commitment.VariantHexPatriciaTrie, commitment.VariantSparseMerkleTrie:
+	default:
+		return cs, nil, false, fmt.Errorf("state storing is only supported hex patricia trie and sparse merkle trie")
+	}
+	ctx := d.MakeContext()
+	defer ctx.Close()
+
+	var stepbuf [2]byte
+	var state []byte
+	for step := int(txNum / d.aggregationStep); step >= 0; step-- {
+		binary.BigEndian.PutUint16(stepbuf[:], uint16(step))
+		if state, err = ctx.Get(keyCommitmentState, stepbuf[:], d.tx); err != nil {
+			return cs, nil, false, err
+		}
+		if len(state) >= 8 && binary.BigEndian.Uint64(state) < txNum {
+			break
+		}
+		state = nil
+	}
+	d.patriciaTrie.Reset()
+	if state == nil {
+		return cs, nil, false, nil
+	}
+	if err = cs.Decode(state); err != nil {
+		return cs, nil, false, err
+	}
+	if hph, isHph := d.patriciaTrie.(*commitment.HexPatriciaHashed); isHph {
+		if err = hph.SetState(cs.trieState); err != nil {
+			return cs, nil, false, err
+		}
+	}
+	if rootHash, err = d.patriciaTrie.RootHash(); err != nil {
+		return cs, nil, false, err
+	}
+
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], cs.txNum)
+	v, err := ctx.Get(keyCommitmentRootByTxNum, key[:], d.tx)
+	if err != nil {
+		return cs, nil, false, err
+	}
+	savedRoot, _, found, err := decodeCommitmentRoot(v)
+	if err != nil {
+		return cs, nil, false, err
+	}
+	if found && !bytes.Equal(savedRoot, rootHash) {
+		return cs, nil, false, fmt.Errorf("commitment root %x restored at txNum %d differs from saved root %x", rootHash, cs.txNum, savedRoot)
+	}
+	return cs, rootHash, true, nil
+}
+
 type commitmentState struct {
 	txNum     uint64
 	blockNum  uint64
@@ -30,6 +30,7 @@ import (
 	"github.com/ledgerwatch/log/v3"
 	"github.com/stretchr/testify/require"
 	btree2 "github.com/tidwall/btree"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/gateway-fm/cdk-erigon-lib/kv"
 	"github.com/gateway-fm/cdk-erigon-lib/kv/mdbx"
@@ -46,6 +47,7 @@ func testDbAndDomain(t *testing.T) (string, kv.RwDB, *Domain) {
 	historyValsTable := "HistoryVals"
 	settingsTable := "Settings"
 	indexTable := "Index"
+	valueIdxTable := "ValueIdx"
 	db := mdbx.NewMDBX(logger).InMem(path).WithTableCfg(func(defaultBuckets kv.TableCfg) kv.TableCfg {
 		return kv.TableCfg{
 			keysTable:        kv.TableCfgItem{Flags: kv.DupSort},
@@ -54,6 +56,7 @@ func testDbAndDomain(t *testing.T) (string, kv.RwDB, *Domain) {
 			historyValsTable: kv.TableCfgItem{Flags: kv.DupSort},
 			settingsTable:    kv.TableCfgItem{},
 			indexTable:       kv.TableCfgItem{Flags: kv.DupSort},
+			valueIdxTable:    kv.TableCfgItem{Flags: kv.DupSort},
 		}
 	}).MustOpen()
 	t.Cleanup(db.Close)
@@ -771,3 +774,175 @@ func TestScanStaticFilesD(t *testing.T) {
 	require.Equal(t, "0-4", found[0])
 	require.Equal(t, "4-5", found[1])
 }
+
+func TestDomainValueIndex(t *testing.T) {
+	_, db, d := testDbAndDomain(t)
+	d.SetValueProjection(IdentityProjection, "ValueIdx")
+	ctx, require := context.Background(), require.New(t)
+	tx, err := db.BeginRw(ctx)
+	require.NoError(err)
+	defer tx.Rollback()
+	d.SetTx(tx)
+	d.StartWrites()
+	defer d.FinishWrites()
+
+	// keys are encodings of numbers 1..31
+	// each key changes value on every txNum which is multiple of the key, value is one of 3 tags or deletion
+	txs := uint64(400)
+	latest := map[string][]byte{}
+	for txNum := uint64(1); txNum <= txs; txNum++ {
+		d.SetTxNum(txNum)
+		for keyNum := uint64(1); keyNum <= 31; keyNum++ {
+			if txNum%keyNum != 0 {
+				continue
+			}
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], keyNum)
+			if (txNum/keyNum)%5 == 4 {
+				err = d.Delete(k[:], nil)
+				delete(latest, string(k[:]))
+			} else {
+				v := []byte{byte((txNum / keyNum) % 3)}
+				err = d.Put(k[:], nil, v)
+				latest[string(k[:])] = v
+			}
+			require.NoError(err)
+		}
+		if txNum%d.aggregationStep == 0 {
+			err = d.Rotate().Flush(ctx, tx)
+			require.NoError(err)
+		}
+	}
+	err = d.Rotate().Flush(ctx, tx)
+	require.NoError(err)
+	collateAndMerge(t, db, tx, d, txs)
+
+	checkIndex := func(latest map[string][]byte) {
+		t.Helper()
+		dc := d.MakeContext()
+		defer dc.Close()
+		for _, item := range dc.files {
+			require.NotNil(item.src.valIdxBt, "%d-%d", item.startTxNum, item.endTxNum)
+		}
+		for tag := byte(0); tag < 4; tag++ {
+			var expect []string
+			for k, v := range latest {
+				if v[0] == tag {
+					expect = append(expect, fmt.Sprintf("%x", k))
+				}
+			}
+			var got []string
+			err = dc.IterateKeysByValue([]byte{tag}, tx, func(k []byte) error {
+				got = append(got, fmt.Sprintf("%x", k))
+				return nil
+			})
+			require.NoError(err)
+			require.ElementsMatch(expect, got, "tag=%d", tag)
+		}
+	}
+	checkIndex(latest)
+
+	// new tag for some keys and deletion of others, then unwind of these changes
+	beforeUnwind := map[string][]byte{}
+	for k, v := range latest {
+		beforeUnwind[k] = v
+	}
+	d.SetTxNum(txs + 1)
+	for keyNum := uint64(1); keyNum <= 31; keyNum++ {
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], keyNum)
+		if keyNum%3 == 0 {
+			err = d.Delete(k[:], nil)
+			delete(latest, string(k[:]))
+		} else {
+			err = d.Put(k[:], nil, []byte{3})
+			latest[string(k[:])] = []byte{3}
+		}
+		require.NoError(err)
+	}
+	err = d.Rotate().Flush(ctx, tx)
+	require.NoError(err)
+	checkIndex(latest)
+
+	err = d.unwind(ctx, txs+1)
+	require.NoError(err)
+	checkIndex(beforeUnwind)
+}
+
+func TestDomainExistenceFilter(t *testing.T) {
+	_, db, d, txs := filledDomain(t)
+	d.EnableExistenceFilter()
+	ctx := context.Background()
+	collateAndMerge(t, db, nil, d, txs)
+	checkHistory(t, db, d, txs)
+	require.NotZero(t, d.GetAndResetStats().HistoryFilterHits)
+
+	checkFilters := func() {
+		t.Helper()
+		roTx, err := db.BeginRo(ctx)
+		require.NoError(t, err)
+		defer roTx.Rollback()
+		dc := d.MakeContext()
+		defer dc.Close()
+		require.NotEmpty(t, dc.files)
+		for _, item := range dc.files {
+			require.NotNil(t, item.src.existence, "%d-%d", item.startTxNum, item.endTxNum)
+		}
+		for _, item := range dc.hc.ic.files {
+			require.NotNil(t, item.src.existence, "%d-%d", item.startTxNum, item.endTxNum)
+		}
+		// keys which were never written must be skipped by filters most of the time
+		for keyNum := uint64(100); keyNum < 200; keyNum++ {
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], keyNum)
+			// from txNum 0 - to probe all files
+			val, found, err := dc.get(k[:], 0, roTx)
+			require.NoError(t, err)
+			require.False(t, found)
+			require.Nil(t, val)
+		}
+		stats := d.GetAndResetStats()
+		require.Greater(t, stats.FilterSkips, stats.FilterHits)
+		require.Zero(t, stats.HistoryFilterHits+stats.HistoryFilterSkips)
+
+		// history lookups probe .ef filters, which are counted separately from .kv filters
+		for keyNum := uint64(100); keyNum < 200; keyNum++ {
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], keyNum)
+			val, err := dc.GetBeforeTxNum(k[:], 1, roTx)
+			require.NoError(t, err)
+			require.Nil(t, val)
+		}
+		stats = d.GetAndResetStats()
+		require.Zero(t, stats.FilterHits+stats.FilterSkips)
+		require.Greater(t, stats.HistoryFilterSkips, stats.HistoryFilterHits)
+	}
+	checkFilters()
+
+	// filters are persisted and opened with files
+	d.closeWhatNotInList([]string{})
+	d.History.closeWhatNotInList([]string{})
+	d.InvertedIndex.closeWhatNotInList([]string{})
+	require.NoError(t, d.OpenFolder())
+	checkFilters()
+
+	// missed filters are re-built
+	for _, files := range []*btree2.BTreeG[*filesItem]{d.files, d.InvertedIndex.files} {
+		files.Walk(func(items []*filesItem) bool {
+			for _, item := range items {
+				require.NoError(t, os.Remove(item.existence.FilePath))
+			}
+			return true
+		})
+	}
+	d.closeWhatNotInList([]string{})
+	d.History.closeWhatNotInList([]string{})
+	d.InvertedIndex.closeWhatNotInList([]string{})
+	require.NoError(t, d.OpenFolder())
+	g := &errgroup.Group{}
+	require.NoError(t, d.BuildMissedIndices(ctx, g, background.NewProgressSet()))
+	require.NoError(t, g.Wait())
+	require.NoError(t, d.OpenFolder())
+	checkFilters()
+	checkHistory(t, db, d, txs)
+}
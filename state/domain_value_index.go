@@ -0,0 +1,271 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common/background"
+	"github.com/gateway-fm/cdk-erigon-lib/common/dir"
+	"github.com/gateway-fm/cdk-erigon-lib/compress"
+	"github.com/gateway-fm/cdk-erigon-lib/etl"
+	"github.com/gateway-fm/cdk-erigon-lib/kv"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// Value index (reverse index) - optional file type of Domain, which maps projection of value to keys having such value.
+// Built from .kv file during collation and merge:
+//   - .vk file: sorted pairs `len(projection) + projection + key => key`
+//   - .vkt file: BtIndex over .vk file
+//
+// Files are immutable and don't know about newer values of keys, so every key found in files
+// is checked against latest value of domain before it's returned to user.
+// Keys written to DB are indexed by DB table `projection of latest value -> key`, which is updated
+// by Put/Delete/unwind. Prune moves values to files, but doesn't change latest values - so table stays valid.
+
+// ValueProjection - maps value of domain to the key of value index. Values with nil projection are not indexed.
+type ValueProjection func(v []byte) []byte
+
+// IdentityProjection - indexes values as-is
+func IdentityProjection(v []byte) []byte { return v }
+
+// SetValueProjection - enables value index for domain. Must be called before opening files.
+// valueIdxTable - DupSort table for the DB part of index
+func (d *Domain) SetValueProjection(f ValueProjection, valueIdxTable string) {
+	d.valueProjection, d.valueIdxTable = f, valueIdxTable
+}
+
+func valueIndexPrefix(projection []byte) []byte {
+	prefix := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(projection))
+	n := binary.PutUvarint(prefix, uint64(len(projection)))
+	return append(prefix[:n], projection...)
+}
+
+// updateValueIdx - moves key from projection of it's previous latest value to projection of the new one (nil - deleted)
+func (d *Domain) updateValueIdx(key, original, val []byte) error {
+	if d.valueProjection == nil {
+		return nil
+	}
+	// prefixes are computed before any write, because `original` may be invalidated by it
+	var from, to []byte
+	if len(original) > 0 {
+		if projection := d.valueProjection(original); projection != nil {
+			from = valueIndexPrefix(projection)
+		}
+	}
+	if len(val) > 0 {
+		if projection := d.valueProjection(val); projection != nil {
+			to = valueIndexPrefix(projection)
+		}
+	}
+	if bytes.Equal(from, to) {
+		return nil
+	}
+	c, err := d.tx.RwCursorDupSort(d.valueIdxTable)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if from != nil {
+		// original value may come from files, then key is not in the table
+		if _, v, err := c.SeekBothExact(from, key); err != nil {
+			return err
+		} else if v != nil {
+			if err = c.DeleteCurrent(); err != nil {
+				return err
+			}
+		}
+	}
+	if to != nil {
+		return c.Put(to, key)
+	}
+	return nil
+}
+
+func (d *Domain) valueIdxFilePaths(fromStep, toStep uint64) (datPath, btPath string) {
+	datPath = filepath.Join(d.dir, fmt.Sprintf("%s.%d-%d.vk", d.filenameBase, fromStep, toStep))
+	btPath = filepath.Join(d.dir, fmt.Sprintf("%s.%d-%d.vkt", d.filenameBase, fromStep, toStep))
+	return datPath, btPath
+}
+
+// buildValueIdx - produces .vk and .vkt files from .kv file
+func (d *Domain) buildValueIdx(ctx context.Context, valuesDecomp *compress.Decompressor, fromStep, toStep uint64, ps *background.ProgressSet) (*compress.Decompressor, *BtIndex, error) {
+	datPath, btPath := d.valueIdxFilePaths(fromStep, toStep)
+	_, datFileName := filepath.Split(datPath)
+	p := ps.AddNew(datFileName, uint64(valuesDecomp.Count()/2))
+	defer ps.Delete(p)
+
+	collector := etl.NewCollector("value idx "+datFileName, d.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize/8))
+	defer collector.Close()
+	collector.LogLvl(log.LvlTrace)
+
+	g := valuesDecomp.MakeGetter()
+	g.Reset(0)
+	var k, v []byte
+	for g.HasNext() {
+		k, _ = g.Next(k[:0])
+		if !g.HasNext() {
+			return nil, nil, fmt.Errorf("build %s value idx: no value for key %x", d.filenameBase, k)
+		}
+		v, _ = g.Next(v[:0])
+		p.Processed.Add(1)
+		if len(v) == 0 { // deleted
+			continue
+		}
+		projection := d.valueProjection(v)
+		if projection == nil {
+			continue
+		}
+		if err := collector.Collect(append(valueIndexPrefix(projection), k...), k); err != nil {
+			return nil, nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		default:
+		}
+	}
+
+	comp, err := compress.NewCompressor(ctx, "value idx", datPath, d.tmpdir, compress.MinPatternScore, 1, log.LvlTrace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create %s value idx compressor: %w", d.filenameBase, err)
+	}
+	defer comp.Close()
+	if err = collector.Load(nil, "", func(k, v []byte, _ etl.CurrentTableReader, _ etl.LoadNextFunc) error {
+		if err := comp.AddUncompressedWord(k); err != nil {
+			return err
+		}
+		return comp.AddUncompressedWord(v)
+	}, etl.TransformArgs{Quit: ctx.Done()}); err != nil {
+		return nil, nil, fmt.Errorf("collect %s value idx: %w", d.filenameBase, err)
+	}
+	if err = comp.Compress(); err != nil {
+		return nil, nil, fmt.Errorf("compress %s value idx: %w", d.filenameBase, err)
+	}
+	comp.Close()
+
+	decomp, err := compress.NewDecompressor(datPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open %s value idx decompressor: %w", d.filenameBase, err)
+	}
+	bt, err := CreateBtreeIndexWithDecompressor(btPath, DefaultBtreeM, decomp, p)
+	if err != nil {
+		decomp.Close()
+		return nil, nil, fmt.Errorf("build %s value idx bt: %w", d.filenameBase, err)
+	}
+	return decomp, bt, nil
+}
+
+// openValueIdx - opens value index of file, if it exists on disk
+func (d *Domain) openValueIdx(item *filesItem) (err error) {
+	if d.valueProjection == nil || item.valIdxDecomp != nil {
+		return nil
+	}
+	fromStep, toStep := item.startTxNum/d.aggregationStep, item.endTxNum/d.aggregationStep
+	datPath, btPath := d.valueIdxFilePaths(fromStep, toStep)
+	if !dir.FileExist(datPath) || !dir.FileExist(btPath) {
+		return nil
+	}
+	if item.valIdxDecomp, err = compress.NewDecompressor(datPath); err != nil {
+		return err
+	}
+	if item.valIdxBt, err = OpenBtreeIndexWithDecompressor(btPath, DefaultBtreeM, item.valIdxDecomp); err != nil {
+		item.valIdxDecomp.Close()
+		item.valIdxDecomp = nil
+		return err
+	}
+	return nil
+}
+
+func (d *Domain) missedValueIdxFiles() (l []*filesItem) {
+	if d.valueProjection == nil {
+		return nil
+	}
+	d.files.Walk(func(items []*filesItem) bool { // don't run slow logic while iterating on btree
+		for _, item := range items {
+			if item.decompressor != nil && item.valIdxDecomp == nil {
+				l = append(l, item)
+			}
+		}
+		return true
+	})
+	return l
+}
+
+// IterateKeysByValue - calls `it` for every key which latest value has given projection.
+// Recent keys are taken from DB, older ones from value index files. Order of keys is not defined.
+func (dc *DomainContext) IterateKeysByValue(projection []byte, roTx kv.Tx, it func(k []byte) error) error {
+	if dc.d.valueProjection == nil {
+		return fmt.Errorf("%s: value index is not enabled", dc.d.filenameBase)
+	}
+	seen := map[string]struct{}{}
+	prefix := valueIndexPrefix(projection)
+
+	idxCursor, err := roTx.CursorDupSort(dc.d.valueIdxTable)
+	if err != nil {
+		return err
+	}
+	defer idxCursor.Close()
+	for k, key, err := idxCursor.SeekExact(prefix); k != nil; k, key, err = idxCursor.NextDup() {
+		if err != nil {
+			return err
+		}
+		seen[string(key)] = struct{}{}
+		if err = it(key); err != nil {
+			return err
+		}
+	}
+
+	for i := len(dc.files) - 1; i >= 0; i-- {
+		bt := dc.files[i].src.valIdxBt
+		if bt == nil || bt.Empty() {
+			continue
+		}
+		cur, err := bt.Seek(prefix)
+		if err != nil {
+			if errors.Is(err, ErrBtIndexLookupBounds) { // all keys of file are less than prefix
+				continue
+			}
+			return fmt.Errorf("%s value idx seek: %w", bt.FileName(), err)
+		}
+		for bytes.HasPrefix(cur.Key(), prefix) {
+			k := cur.Value()
+			if _, ok := seen[string(k)]; !ok {
+				seen[string(k)] = struct{}{}
+				// key may have newer value in DB or in newer files
+				v, _, err := dc.get(k, dc.d.txNum, roTx)
+				if err != nil {
+					return err
+				}
+				if len(v) > 0 && bytes.Equal(dc.d.valueProjection(v), projection) {
+					if err = it(k); err != nil {
+						return err
+					}
+				}
+			}
+			if !cur.Next() {
+				break
+			}
+		}
+	}
+	return nil
+}
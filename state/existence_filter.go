@@ -0,0 +1,241 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package state
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common/background"
+	"github.com/gateway-fm/cdk-erigon-lib/common/dir"
+	"github.com/gateway-fm/cdk-erigon-lib/compress"
+	"github.com/gateway-fm/cdk-erigon-lib/etl"
+	"github.com/spaolacci/murmur3"
+	btree2 "github.com/tidwall/btree"
+)
+
+// Existence filter - optional bloom filter over keys of .kv/.ef file. Allows to skip file without
+// touching it's index and decompressor if key is definitely not there.
+// File format:
+//   - 8 bytes: amount of bits in filter (multiple of 64)
+//   - 1 byte: amount of hash functions
+//   - bits, as little-endian uint64 words
+
+const (
+	existenceFilterBitsPerKey = 10 // ~1% false-positives
+	existenceFilterHashes     = 7
+)
+
+type ExistenceFilter struct {
+	words    []uint64
+	bitsN    uint64
+	hashesN  uint8
+	FileName string
+	FilePath string
+}
+
+func NewExistenceFilter(keysCount uint64, filePath string) *ExistenceFilter {
+	bitsN := (keysCount*existenceFilterBitsPerKey + 63) / 64 * 64
+	if bitsN == 0 {
+		bitsN = 64
+	}
+	_, fileName := filepath.Split(filePath)
+	return &ExistenceFilter{
+		words:    make([]uint64, bitsN/64),
+		bitsN:    bitsN,
+		hashesN:  existenceFilterHashes,
+		FileName: fileName,
+		FilePath: filePath,
+	}
+}
+
+func (b *ExistenceFilter) AddKey(key []byte) {
+	h1, h2 := murmur3.Sum128(key)
+	for i := uint64(0); i < uint64(b.hashesN); i++ {
+		pos := (h1 + i*h2) % b.bitsN
+		b.words[pos/64] |= 1 << (pos % 64)
+	}
+}
+
+// ContainsKey - false means key is definitely not in file, true means key may be in file
+func (b *ExistenceFilter) ContainsKey(key []byte) bool {
+	h1, h2 := murmur3.Sum128(key)
+	for i := uint64(0); i < uint64(b.hashesN); i++ {
+		pos := (h1 + i*h2) % b.bitsN
+		if b.words[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Build - writes filter to .tmp file, fsync it and renames to final name
+func (b *ExistenceFilter) Build() error {
+	tmpFilePath := b.FilePath + ".tmp"
+	f, err := os.Create(tmpFilePath)
+	if err != nil {
+		return fmt.Errorf("create existence filter %s: %w", b.FileName, err)
+	}
+	defer f.Close()
+	w := bufio.NewWriterSize(f, etl.BufIOSize)
+	var numBuf [8]byte
+	binary.BigEndian.PutUint64(numBuf[:], b.bitsN)
+	if _, err = w.Write(numBuf[:]); err != nil {
+		return err
+	}
+	if err = w.WriteByte(b.hashesN); err != nil {
+		return err
+	}
+	for _, word := range b.words {
+		binary.LittleEndian.PutUint64(numBuf[:], word)
+		if _, err = w.Write(numBuf[:]); err != nil {
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFilePath, b.FilePath)
+}
+
+func OpenExistenceFilter(filePath string) (*ExistenceFilter, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	_, fileName := filepath.Split(filePath)
+	if len(data) < 9 {
+		return nil, fmt.Errorf("existence filter %s: file too short", fileName)
+	}
+	b := &ExistenceFilter{
+		bitsN:    binary.BigEndian.Uint64(data),
+		hashesN:  data[8],
+		FileName: fileName,
+		FilePath: filePath,
+	}
+	data = data[9:]
+	if b.bitsN == 0 || b.bitsN%64 != 0 || uint64(len(data)) != b.bitsN/8 || b.hashesN == 0 {
+		return nil, fmt.Errorf("existence filter %s: corrupted header", fileName)
+	}
+	b.words = make([]uint64, b.bitsN/64)
+	for i := range b.words {
+		b.words[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	return b, nil
+}
+
+// buildExistenceFilter - produces filter from keys of `key, value` pairs file
+func buildExistenceFilter(ctx context.Context, d *compress.Decompressor, filePath string, p *background.Progress) (*ExistenceFilter, error) {
+	defer d.EnableMadvNormal().DisableReadAhead()
+
+	_, fileName := filepath.Split(filePath)
+	p.Name.Store(&fileName)
+	p.Total.Store(uint64(d.Count() / 2))
+
+	filter := NewExistenceFilter(uint64(d.Count()/2), filePath)
+	word := make([]byte, 0, 256)
+	g := d.MakeGetter()
+	g.Reset(0)
+	for g.HasNext() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		word, _ = g.Next(word[:0])
+		filter.AddKey(word)
+		g.Skip() // value
+		p.Processed.Add(1)
+	}
+	if err := filter.Build(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// EnableExistenceFilter - build existence filters for new files (and for existing files by `BuildMissedIndices`).
+// Domain builds them for .kv files, InvertedIndex and History - for .ef files.
+func (ii *InvertedIndex) EnableExistenceFilter() { ii.withExistenceFilter = true }
+
+func (ii *InvertedIndex) existenceFilterPath(fromStep, toStep uint64, ext string) string {
+	return filepath.Join(ii.dir, fmt.Sprintf("%s.%d-%d.%s", ii.filenameBase, fromStep, toStep, ext))
+}
+
+// openExistenceFilter - opens filter of file, if it exists on disk
+func (ii *InvertedIndex) openExistenceFilter(item *filesItem, ext string) (err error) {
+	if !ii.withExistenceFilter || item.existence != nil {
+		return nil
+	}
+	fPath := ii.existenceFilterPath(item.startTxNum/ii.aggregationStep, item.endTxNum/ii.aggregationStep, ext)
+	if !dir.FileExist(fPath) {
+		return nil
+	}
+	item.existence, err = OpenExistenceFilter(fPath)
+	return err
+}
+
+func (ii *InvertedIndex) buildExistence(ctx context.Context, d *compress.Decompressor, fromStep, toStep uint64, ext string, ps *background.ProgressSet) (*ExistenceFilter, error) {
+	p := &background.Progress{}
+	ps.Add(p)
+	defer ps.Delete(p)
+	return buildExistenceFilter(ctx, d, ii.existenceFilterPath(fromStep, toStep, ext), p)
+}
+
+func (ii *InvertedIndex) missedExistenceFilterFiles(files *btree2.BTreeG[*filesItem], ext string) (l []*filesItem) {
+	if !ii.withExistenceFilter {
+		return nil
+	}
+	files.Walk(func(items []*filesItem) bool { // don't run slow logic while iterating on btree
+		for _, item := range items {
+			if item.decompressor == nil || item.existence != nil {
+				continue
+			}
+			if !dir.FileExist(ii.existenceFilterPath(item.startTxNum/ii.aggregationStep, item.endTxNum/ii.aggregationStep, ext)) {
+				l = append(l, item)
+			}
+		}
+		return true
+	})
+	return l
+}
+
+// filterCounters - answers of existence filters of one kind of files, see DomainStats
+type filterCounters struct {
+	hits, skips atomic.Uint64
+}
+
+// mayContain - consults existence filter of file (if any) and updates hit/skip counters
+func (fc *filterCounters) mayContain(item *filesItem, key []byte) bool {
+	if item.existence == nil {
+		return true
+	}
+	if !item.existence.ContainsKey(key) {
+		fc.skips.Add(1)
+		return false
+	}
+	fc.hits.Add(1)
+	return true
+}
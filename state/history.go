@@ -743,6 +743,7 @@ type HistoryFiles struct {
 	historyIdx      *recsplit.Index
 	efHistoryDecomp *compress.Decompressor
 	efHistoryIdx    *recsplit.Index
+	efExistence     *ExistenceFilter
 }
 
 func (sf HistoryFiles) Close() {
@@ -873,6 +874,12 @@ func (h *History) buildFiles(ctx context.Context, step uint64, collation History
 	if efHistoryIdx, err = buildIndexThenOpen(ctx, efHistoryDecomp, efHistoryIdxPath, h.tmpdir, len(keys), false /* values */, p); err != nil {
 		return HistoryFiles{}, fmt.Errorf("build %s ef history idx: %w", h.filenameBase, err)
 	}
+	var efExistence *ExistenceFilter
+	if h.withExistenceFilter {
+		if efExistence, err = h.buildExistence(ctx, efHistoryDecomp, step, step+1, "efei", ps); err != nil {
+			return HistoryFiles{}, fmt.Errorf("build %s ef history existence filter: %w", h.filenameBase, err)
+		}
+	}
 	if rs, err = recsplit.NewRecSplit(recsplit.RecSplitArgs{
 		KeyCount:   collation.historyCount,
 		Enums:      false,
@@ -926,13 +933,15 @@ func (h *History) buildFiles(ctx context.Context, step uint64, collation History
 		historyIdx:      historyIdx,
 		efHistoryDecomp: efHistoryDecomp,
 		efHistoryIdx:    efHistoryIdx,
+		efExistence:     efExistence,
 	}, nil
 }
 
 func (h *History) integrateFiles(sf HistoryFiles, txNumFrom, txNumTo uint64) {
 	h.InvertedIndex.integrateFiles(InvertedFiles{
-		decomp: sf.efHistoryDecomp,
-		index:  sf.efHistoryIdx,
+		decomp:    sf.efHistoryDecomp,
+		index:     sf.efHistoryIdx,
+		existence: sf.efExistence,
 	}, txNumFrom, txNumTo)
 	h.files.Set(&filesItem{
 		frozen:       (txNumTo-txNumFrom)/h.aggregationStep == StepsInBiggestFile,
@@ -1295,6 +1304,9 @@ func (hc *HistoryContext) GetNoState(key []byte, txNum uint64) ([]byte, bool, er
 	var foundStartTxNum uint64
 	var found bool
 	var findInFile = func(item ctxItem) bool {
+		if !hc.h.efFilter.mayContain(item.src, key) {
+			return true
+		}
 		reader := hc.ic.statelessIdxReader(item.i)
 		if reader.Empty() {
 			return true
@@ -1998,7 +2010,7 @@ func (hi *HistoryChangesIterF) advance() error {
 		if !ok {
 			continue
 		}
-		if int(n) >= hi.endTxNum {
+		if hi.endTxNum >= 0 && int(n) >= hi.endTxNum {
 			continue
 		}
 
@@ -2249,6 +2261,72 @@ func (hi *HistoryChangesIterDBDup) Next() ([]byte, []byte, error) {
 	return hi.k, hi.v, nil
 }
 
+// LatestStateReader - returns latest (current) value of key. Used by StateDiffIter for keys
+// which have no changes after the end of diff window: their value as of `toTxNum` is the latest one.
+type LatestStateReader func(key []byte) ([]byte, error)
+
+// StateDiff - returns keys changed in [fromTxNum, toTxNum) with their values as of `fromTxNum` (before)
+// and as of `toTxNum` (after). Empty value means key did not exist at that moment.
+// Keys and before-values come from range of the window, after-value is looked up in history for each key,
+// `latest` is called only for keys which have no changes at or after `toTxNum`.
+func (hc *HistoryContext) StateDiff(fromTxNum, toTxNum uint64, latest LatestStateReader, roTx kv.Tx) (*StateDiffIter, error) {
+	if fromTxNum > toTxNum {
+		return nil, fmt.Errorf("StateDiff: fromTxNum %d > toTxNum %d", fromTxNum, toTxNum)
+	}
+	before, err := hc.HistoryRange(int(fromTxNum), int(toTxNum), order.Asc, -1, roTx)
+	if err != nil {
+		return nil, err
+	}
+	return &StateDiffIter{before: before, hc: hc, toTxNum: toTxNum, latest: latest, roTx: roTx}, nil
+}
+
+// StateDiffIter - iterates over keys changed in diff window with their values as of beginning of the window,
+// and looks up value of every key as of end of the window
+type StateDiffIter struct {
+	before  iter.KV
+	hc      *HistoryContext
+	toTxNum uint64
+	latest  LatestStateReader
+	roTx    kv.Tx
+	err     error
+}
+
+func (it *StateDiffIter) HasNext() bool { return it.err != nil || it.before.HasNext() }
+
+// Next - returns key, it's value as of `fromTxNum` and it's value as of `toTxNum`
+func (it *StateDiffIter) Next() (k, before, after []byte, err error) {
+	if it.err != nil {
+		return nil, nil, nil, it.err
+	}
+	if k, before, err = it.before.Next(); err != nil {
+		it.err = err
+		return nil, nil, nil, err
+	}
+	after, ok, err := it.hc.GetNoStateWithRecent(k, it.toTxNum, it.roTx)
+	if err != nil {
+		it.err = err
+		return nil, nil, nil, err
+	}
+	if ok {
+		return k, before, after, nil
+	}
+	if it.latest == nil {
+		it.err = fmt.Errorf("StateDiffIter: no latest state reader for key %x", k)
+		return nil, nil, nil, it.err
+	}
+	if after, err = it.latest(k); err != nil {
+		it.err = err
+		return nil, nil, nil, err
+	}
+	return k, before, after, nil
+}
+
+func (it *StateDiffIter) Close() {
+	if casted, ok := it.before.(iter.Closer); ok {
+		casted.Close()
+	}
+}
+
 func (h *History) DisableReadAhead() {
 	h.InvertedIndex.DisableReadAhead()
 	h.files.Walk(func(items []*filesItem) bool {
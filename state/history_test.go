@@ -792,6 +792,67 @@ func TestIterateChanged2(t *testing.T) {
 	})
 }
 
+func TestHistoryStateDiff(t *testing.T) {
+	ctx := context.Background()
+
+	valueAt := func(keyNum, txNum uint64) []byte {
+		if txNum < keyNum {
+			return []byte{}
+		}
+		var v [8]byte
+		binary.BigEndian.PutUint64(v[:], txNum/keyNum)
+		v[0] = 0xff
+		return v[:]
+	}
+	test := func(t *testing.T, h *History, db kv.RwDB, txs uint64) {
+		t.Helper()
+		require := require.New(t)
+		collateAndMergeHistory(t, db, h, txs)
+
+		tx, err := db.BeginRo(ctx)
+		require.NoError(err)
+		defer tx.Rollback()
+		hc := h.MakeContext()
+		defer hc.Close()
+
+		latest := func(k []byte) ([]byte, error) {
+			return valueAt(binary.BigEndian.Uint64(k)&0xffffffffffffff, txs), nil
+		}
+		for _, r := range [][2]uint64{{2, 20}, {30, 300}, {950, 990}, {995, 1000}, {990, txs + 1}} {
+			from, to := r[0], r[1]
+			label := fmt.Sprintf("from=%d, to=%d", from, to)
+			it, err := hc.StateDiff(from, to, latest, tx)
+			require.NoError(err, label)
+			var keyNums []uint64
+			for it.HasNext() {
+				k, before, after, err := it.Next()
+				require.NoError(err, label)
+				keyNum := binary.BigEndian.Uint64(k) & 0xffffffffffffff
+				keyNums = append(keyNums, keyNum)
+				require.Equal(fmt.Sprintf("%x", valueAt(keyNum, from-1)), fmt.Sprintf("%x", before), label)
+				require.Equal(fmt.Sprintf("%x", valueAt(keyNum, to-1)), fmt.Sprintf("%x", after), label)
+			}
+			it.Close()
+
+			var expectKeyNums []uint64
+			for keyNum := uint64(1); keyNum <= 31; keyNum++ {
+				if (to-1)/keyNum > (from-1)/keyNum {
+					expectKeyNums = append(expectKeyNums, keyNum)
+				}
+			}
+			require.Equal(expectKeyNums, keyNums, label)
+		}
+	}
+	t.Run("large_values", func(t *testing.T) {
+		_, db, h, txs := filledHistory(t, true)
+		test(t, h, db, txs)
+	})
+	t.Run("small_values", func(t *testing.T) {
+		_, db, h, txs := filledHistory(t, false)
+		test(t, h, db, txs)
+	})
+}
+
 func TestScanStaticFilesH(t *testing.T) {
 	h := &History{InvertedIndex: &InvertedIndex{filenameBase: "test", aggregationStep: 1},
 		files: btree2.NewBTreeG[*filesItem](filesItemLess),
@@ -0,0 +1,411 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common/background"
+	"github.com/gateway-fm/cdk-erigon-lib/compress"
+	"github.com/gateway-fm/cdk-erigon-lib/recsplit"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// Index overlay - small mutable index next to static index (recsplit .kvi or BtIndex .bt) of data file.
+// It holds keys which were appended to data file after static index was built (see compress.NewAppendCompressor),
+// so recent data doesn't need to stay in DB until full file with static index is built.
+// Keys are kept in memory and persisted in write-ahead file (.ovl), replayed on open.
+// File format - sequence of records:
+//   - uvarint: length of key
+//   - key
+//   - 8 bytes: offset in data file
+//   - 4 bytes: crc32 of all previous fields of record
+//
+// Record which is cut or has wrong checksum (write interrupted by crash) is truncated together with everything after it.
+// Compaction builds new static index with snapshot of overlay (keys and size of write-ahead file), Put is not blocked meanwhile.
+// After the new index is opened - records of the snapshot are dropped: records added after it are rewritten to new
+// write-ahead file (.tmp + rename). If crash happens before that, overlay keeps keys which are also in static index -
+// it's harmless: overlay is consulted first and has same offsets.
+
+const IndexOverlayFileExt = ".ovl"
+
+type IndexOverlay struct {
+	lock     sync.RWMutex
+	offsets  map[string]uint64
+	file     *os.File
+	w        *bufio.Writer
+	buf      []byte
+	size     int64 // of write-ahead file, including records not flushed yet
+	FileName string
+	FilePath string
+}
+
+// OpenIndexOverlay - opens (or creates) write-ahead file of overlay and replays it
+func OpenIndexOverlay(filePath string) (*IndexOverlay, error) {
+	_, fileName := filepath.Split(filePath)
+	o := &IndexOverlay{FilePath: filePath, FileName: fileName, offsets: map[string]uint64{}}
+	data, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	valid := o.replay(data)
+	o.size = int64(valid)
+	if valid < len(data) {
+		log.Warn("[index] overlay has torn record, truncating", "file", fileName, "size", len(data), "valid", valid)
+		if err = os.Truncate(filePath, int64(valid)); err != nil {
+			return nil, err
+		}
+	}
+	if o.file, err = os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return nil, err
+	}
+	o.w = bufio.NewWriter(o.file)
+	return o, nil
+}
+
+// replay - applies records of write-ahead file, returns size of the valid part of it
+func (o *IndexOverlay) replay(data []byte) int {
+	var pos int
+	for pos < len(data) {
+		keyLen, n := binary.Uvarint(data[pos:])
+		if n <= 0 || keyLen > uint64(len(data)) {
+			break
+		}
+		end := pos + n + int(keyLen) + 8 + 4
+		if end > len(data) {
+			break
+		}
+		record := data[pos : end-4]
+		if crc32.ChecksumIEEE(record) != binary.BigEndian.Uint32(data[end-4:end]) {
+			break
+		}
+		key := record[n : n+int(keyLen)]
+		o.offsets[string(key)] = binary.BigEndian.Uint64(record[n+int(keyLen):])
+		pos = end
+	}
+	return pos
+}
+
+// Put - adds key with offset of it in data file (replacing previous offset of the key, if any).
+// Record is durable only after Sync
+func (o *IndexOverlay) Put(key []byte, offset uint64) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.buf = binary.AppendUvarint(o.buf[:0], uint64(len(key)))
+	o.buf = append(o.buf, key...)
+	o.buf = binary.BigEndian.AppendUint64(o.buf, offset)
+	o.buf = binary.BigEndian.AppendUint32(o.buf, crc32.ChecksumIEEE(o.buf))
+	if _, err := o.w.Write(o.buf); err != nil {
+		return err
+	}
+	o.size += int64(len(o.buf))
+	o.offsets[string(key)] = offset
+	return nil
+}
+
+// Sync - flushes added records to write-ahead file and fsyncs it
+func (o *IndexOverlay) Sync() error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if err := o.w.Flush(); err != nil {
+		return err
+	}
+	return o.file.Sync()
+}
+
+// Get - returns offset of the key, if it was added to overlay
+func (o *IndexOverlay) Get(key []byte) (uint64, bool) {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	offset, ok := o.offsets[string(key)]
+	return offset, ok
+}
+
+func (o *IndexOverlay) Count() int {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	return len(o.offsets)
+}
+
+// OverlaySnapshot - keys of overlay at the moment compaction started and size of write-ahead file which has them
+type OverlaySnapshot struct {
+	offsets map[string]uint64
+	size    int64
+}
+
+func (s *OverlaySnapshot) Count() int { return len(s.offsets) }
+
+func (o *IndexOverlay) snapshot() *OverlaySnapshot {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	s := &OverlaySnapshot{offsets: make(map[string]uint64, len(o.offsets)), size: o.size}
+	for key, offset := range o.offsets {
+		s.offsets[key] = offset
+	}
+	return s
+}
+
+// DropSnapshot - removes records of snapshot from overlay, keys added after it stay.
+// Must be called only after index built by compaction is opened
+func (o *IndexOverlay) DropSnapshot(s *OverlaySnapshot) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if err := o.w.Flush(); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(o.FilePath)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != o.size || s.size > o.size {
+		return fmt.Errorf("overlay %s: size %d, expected %d, snapshot %d", o.FileName, len(data), o.size, s.size)
+	}
+	rest := data[s.size:]
+
+	tmpPath := o.FilePath + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", tmpPath, err)
+	}
+	defer f.Close()
+	if _, err = f.Write(rest); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, o.FilePath); err != nil {
+		return err
+	}
+	d, err := os.Open(filepath.Dir(o.FilePath))
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err = d.Sync(); err != nil {
+		return err
+	}
+
+	if err = o.file.Close(); err != nil {
+		return err
+	}
+	if o.file, err = os.OpenFile(o.FilePath, os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return err
+	}
+	o.w.Reset(o.file)
+	o.offsets = map[string]uint64{}
+	o.size = int64(o.replay(rest))
+	return nil
+}
+
+func (o *IndexOverlay) Close() error {
+	if o == nil || o.file == nil {
+		return nil
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if err := o.w.Flush(); err != nil {
+		return err
+	}
+	err := o.file.Close()
+	o.file = nil
+	return err
+}
+
+// forEach - calls f for every key of snapshot
+func (s *OverlaySnapshot) forEach(f func(key []byte, offset uint64) error) error {
+	for key, offset := range s.offsets {
+		if err := f([]byte(key), offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompactRecSplit - builds recsplit index at idxPath over first staticKeys key/value pairs of kv (which are indexed by current
+// static index) and keys of overlay snapshot. Offsets are positions of keys (like .kvi of Domain). Keys of overlay replace same
+// keys of static part. Returned snapshot must be dropped (see DropSnapshot) after the built index is opened
+func (o *IndexOverlay) CompactRecSplit(ctx context.Context, kv *compress.Decompressor, staticKeys uint64, idxPath, tmpDir string, p *background.Progress) (*OverlaySnapshot, error) {
+	s := o.snapshot()
+	// Static keys replaced by overlay are not added to the index, count them first
+	count := uint64(s.Count())
+	if err := forEachPair(ctx, kv, staticKeys, func(key []byte, _ uint64) error {
+		if _, ok := s.offsets[string(key)]; !ok {
+			count++
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	rs, err := recsplit.NewRecSplit(recsplit.RecSplitArgs{
+		KeyCount:   int(count),
+		Enums:      false,
+		BucketSize: 2000,
+		LeafSize:   8,
+		TmpDir:     tmpDir,
+		IndexFile:  idxPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create recsplit: %w", err)
+	}
+	defer rs.Close()
+	rs.LogLvl(log.LvlTrace)
+	for {
+		if err = forEachPair(ctx, kv, staticKeys, func(key []byte, offset uint64) error {
+			if _, ok := s.offsets[string(key)]; ok {
+				return nil
+			}
+			p.Processed.Add(1)
+			return rs.AddKey(key, offset)
+		}); err != nil {
+			return nil, fmt.Errorf("add idx key: %w", err)
+		}
+		if err = s.forEach(rs.AddKey); err != nil {
+			return nil, fmt.Errorf("add overlay key: %w", err)
+		}
+		if err = rs.Build(); err != nil {
+			if rs.Collision() {
+				log.Info("Building recsplit. Collision happened. It's ok. Restarting...")
+				rs.ResetNextSalt()
+				continue
+			}
+			return nil, fmt.Errorf("build idx: %w", err)
+		}
+		return s, nil
+	}
+}
+
+// CompactBtIndex - builds BtIndex at idxPath with keys of bt and keys of overlay snapshot, keys of overlay replace same keys of bt.
+// Returned snapshot must be dropped (see DropSnapshot) after the built index is opened
+func (o *IndexOverlay) CompactBtIndex(ctx context.Context, bt *BtIndex, idxPath, tmpDir string, p *background.Progress) (*OverlaySnapshot, error) {
+	s := o.snapshot()
+	iw, err := NewBtIndexWriter(BtIndexWriterArgs{IndexFile: idxPath, TmpDir: tmpDir})
+	if err != nil {
+		return nil, err
+	}
+	defer iw.Close()
+	for di := uint64(0); bt != nil && di < bt.KeyCount(); di++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		key, _, err := bt.dataLookup(di)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.offsets[string(key)]; ok {
+			continue
+		}
+		offset, err := bt.offset(di)
+		if err != nil {
+			return nil, err
+		}
+		if err = iw.AddKey(key, offset); err != nil {
+			return nil, err
+		}
+		p.Processed.Add(1)
+	}
+	if err = s.forEach(iw.AddKey); err != nil {
+		return nil, err
+	}
+	if err = iw.Build(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// forEachPair - calls f with first n keys of key/value pairs of kv and positions of these keys
+func forEachPair(ctx context.Context, kv *compress.Decompressor, n uint64, f func(key []byte, offset uint64) error) error {
+	key := make([]byte, 0, 256)
+	var keyPos uint64
+	g := kv.MakeGetter()
+	for i := uint64(0); i < n && g.HasNext(); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		key, _ = g.Next(key[:0])
+		if err := f(key, keyPos); err != nil {
+			return err
+		}
+		keyPos = g.Skip()
+	}
+	return nil
+}
+
+// OverlayIndexReader - looks key up in overlay first, then in static index
+type OverlayIndexReader struct {
+	overlay *IndexOverlay
+	static  func(key []byte) (uint64, bool)
+}
+
+// NewOverlayRecSplitReader - static index can be nil. For keys which are not in overlay, result is as good as
+// recsplit.IndexReader.TryLookup: offset of absent key must be checked against data file
+func NewOverlayRecSplitReader(overlay *IndexOverlay, idx *recsplit.Index) *OverlayIndexReader {
+	r := &OverlayIndexReader{overlay: overlay}
+	if idx != nil {
+		r.static = recsplit.NewIndexReader(idx).TryLookup
+	}
+	return r
+}
+
+// NewOverlayBtIndexReader - static index can be nil
+func NewOverlayBtIndexReader(overlay *IndexOverlay, bt *BtIndex) *OverlayIndexReader {
+	r := &OverlayIndexReader{overlay: overlay}
+	if bt != nil {
+		r.static = bt.lookupOffset
+	}
+	return r
+}
+
+// Lookup - returns offset of key in data file, ok=false if key is definitely absent
+func (r *OverlayIndexReader) Lookup(key []byte) (uint64, bool) {
+	if r.overlay != nil {
+		if offset, ok := r.overlay.Get(key); ok {
+			return offset, true
+		}
+	}
+	if r.static == nil {
+		return 0, false
+	}
+	return r.static(key)
+}
+
+// lookupOffset - returns offset of the pair with exactly given key in data file
+func (b *BtIndex) lookupOffset(key []byte) (uint64, bool) {
+	if b.Empty() || b.alloc == nil {
+		return 0, false
+	}
+	cursor, err := b.alloc.Seek(key)
+	if err != nil || cursor == nil || !bytes.Equal(cursor.Key(), key) {
+		return 0, false
+	}
+	offset, err := b.offset(cursor.Ordinal())
+	if err != nil {
+		return 0, false
+	}
+	return offset, true
+}
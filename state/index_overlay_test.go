@@ -0,0 +1,306 @@
+package state
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gateway-fm/cdk-erigon-lib/common/background"
+	"github.com/gateway-fm/cdk-erigon-lib/compress"
+	"github.com/gateway-fm/cdk-erigon-lib/recsplit"
+)
+
+func TestIndexOverlay_Replay(t *testing.T) {
+	fPath := filepath.Join(t.TempDir(), "accounts.0-1.kvi"+IndexOverlayFileExt)
+	o, err := OpenIndexOverlay(fPath)
+	require.NoError(t, err)
+	require.Zero(t, o.Count())
+	for i := 0; i < 100; i++ {
+		require.NoError(t, o.Put([]byte(fmt.Sprintf("key %d", i)), uint64(i*17)))
+	}
+	require.NoError(t, o.Put([]byte("key 7"), 1_000_000))
+	require.NoError(t, o.Sync())
+	require.NoError(t, o.Put([]byte("key 100"), 100*17)) // flushed by Close
+	require.NoError(t, o.Close())
+
+	// Torn record at the end of the file - write interrupted by crash
+	st, err := os.Stat(fPath)
+	require.NoError(t, err)
+	f, err := os.OpenFile(fPath, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{5, 'k', 'e'})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	o, err = OpenIndexOverlay(fPath)
+	require.NoError(t, err)
+	defer o.Close()
+	require.Equal(t, 101, o.Count())
+	for i := 0; i <= 100; i++ {
+		offset, ok := o.Get([]byte(fmt.Sprintf("key %d", i)))
+		require.True(t, ok)
+		if i == 7 {
+			require.EqualValues(t, 1_000_000, offset)
+			continue
+		}
+		require.EqualValues(t, i*17, offset)
+	}
+	_, ok := o.Get([]byte("key 101"))
+	require.False(t, ok)
+	st2, err := os.Stat(fPath)
+	require.NoError(t, err)
+	require.Equal(t, st.Size(), st2.Size())
+
+	require.NoError(t, o.Put([]byte("key 101"), 101*17))
+	require.NoError(t, o.DropSnapshot(o.snapshot()))
+	require.Zero(t, o.Count())
+	require.NoError(t, o.Put([]byte("key 102"), 102*17))
+	require.NoError(t, o.Close())
+	o, err = OpenIndexOverlay(fPath)
+	require.NoError(t, err)
+	require.Equal(t, 1, o.Count())
+	offset, ok := o.Get([]byte("key 102"))
+	require.True(t, ok)
+	require.EqualValues(t, 102*17, offset)
+	require.NoError(t, o.Close())
+}
+
+// appendPairs - appends key/value pairs to kv file and adds them to overlay, some keys replace keys of the file
+func appendPairs(t *testing.T, dataPath string, o *IndexOverlay, staticKeys int) map[string][]byte {
+	t.Helper()
+	d, err := compress.NewDecompressor(dataPath)
+	require.NoError(t, err)
+	replaced := map[string][]byte{}
+	g := d.MakeGetter()
+	for i := 0; i < staticKeys && g.HasNext(); i++ {
+		key, _ := g.Next(nil)
+		if i%100 == 0 {
+			replaced[string(key)] = []byte(fmt.Sprintf("new value %d", i))
+		}
+		g.Skip()
+	}
+	d.Close()
+
+	c, err := compress.NewAppendCompressor(context.Background(), "overlay", dataPath, t.TempDir(), 1, log.LvlDebug)
+	require.NoError(t, err)
+	defer c.Close()
+	for key, val := range replaced {
+		require.NoError(t, c.AddWord([]byte(key)))
+		require.NoError(t, c.AddWord(val))
+	}
+	for i := 0; i < 50; i++ {
+		key := make([]byte, 52)
+		binary.BigEndian.PutUint64(key[44:], uint64(1_000_000+i))
+		val := []byte(fmt.Sprintf("appended value %d", i))
+		replaced[string(key)] = val
+		require.NoError(t, c.AddWord(key))
+		require.NoError(t, c.AddWord(val))
+	}
+	require.NoError(t, c.Compress())
+
+	// Offsets of appended pairs are known only after compression
+	d, err = compress.NewDecompressor(dataPath)
+	require.NoError(t, err)
+	defer d.Close()
+	require.True(t, d.HasTail())
+	var i int
+	require.NoError(t, forEachPair(context.Background(), d, uint64(d.Count()/2), func(key []byte, offset uint64) error {
+		i++
+		if i <= staticKeys {
+			return nil
+		}
+		return o.Put(key, offset)
+	}))
+	require.NoError(t, o.Sync())
+	return replaced
+}
+
+func checkOverlayReader(t *testing.T, dataPath string, r *OverlayIndexReader, replaced map[string][]byte, staticKeys int) {
+	t.Helper()
+	d, err := compress.NewDecompressor(dataPath)
+	require.NoError(t, err)
+	defer d.Close()
+	g := d.MakeGetter()
+	for i := 0; i < staticKeys; i++ {
+		key, _ := g.Next(nil)
+		g.Skip()
+		if _, ok := replaced[string(key)]; ok {
+			continue
+		}
+		offset, ok := r.Lookup(key)
+		require.True(t, ok)
+		g2 := d.MakeGetter()
+		g2.Reset(offset)
+		k, _ := g2.Next(nil)
+		require.Equal(t, key, k)
+	}
+	for key, val := range replaced {
+		offset, ok := r.Lookup([]byte(key))
+		require.True(t, ok)
+		g.Reset(offset)
+		k, _ := g.Next(nil)
+		require.Equal(t, key, string(k))
+		v, _ := g.Next(nil)
+		require.Equal(t, val, v)
+	}
+}
+
+func TestIndexOverlay_BtIndex(t *testing.T) {
+	tmp := t.TempDir()
+	keyCount, M := 1000, uint64(32)
+	dataPath := generateCompressedKV(t, tmp, 52, 180, keyCount)
+	o, err := OpenIndexOverlay(filepath.Join(tmp, "overlay.bt"+IndexOverlayFileExt))
+	require.NoError(t, err)
+	defer o.Close()
+	replaced := appendPairs(t, dataPath, o, keyCount)
+
+	d, err := compress.NewDecompressor(dataPath)
+	require.NoError(t, err)
+	defer d.Close()
+
+	// Static index covers only pairs which were in the file before append
+	staticPath := filepath.Join(tmp, "static.bt")
+	iw, err := NewBtIndexWriter(BtIndexWriterArgs{IndexFile: staticPath, TmpDir: tmp})
+	require.NoError(t, err)
+	require.NoError(t, forEachPair(context.Background(), d, uint64(keyCount), iw.AddKey))
+	require.NoError(t, iw.Build())
+	iw.Close()
+	bt, err := OpenBtreeIndexWithDecompressor(staticPath, M, d)
+	require.NoError(t, err)
+	defer bt.Close()
+
+	r := NewOverlayBtIndexReader(o, bt)
+	checkOverlayReader(t, dataPath, r, replaced, keyCount)
+	_, ok := r.Lookup(make([]byte, 52))
+	require.False(t, ok)
+
+	compactedPath := filepath.Join(tmp, "compacted.bt")
+	s, err := o.CompactBtIndex(context.Background(), bt, compactedPath, tmp, &background.Progress{})
+	require.NoError(t, err)
+	compacted, err := OpenBtreeIndexWithDecompressor(compactedPath, M, d)
+	require.NoError(t, err)
+	defer compacted.Close()
+	require.EqualValues(t, keyCount+50, compacted.KeyCount())
+	require.NoError(t, o.DropSnapshot(s))
+	require.Zero(t, o.Count())
+	checkOverlayReader(t, dataPath, NewOverlayBtIndexReader(o, compacted), replaced, keyCount)
+}
+
+func TestIndexOverlay_RecSplit(t *testing.T) {
+	tmp := t.TempDir()
+	keyCount := 1000
+	dataPath := generateCompressedKV(t, tmp, 52, 180, keyCount)
+	d, err := compress.NewDecompressor(dataPath)
+	require.NoError(t, err)
+	staticPath := filepath.Join(tmp, "static.kvi")
+	require.NoError(t, buildIndex(context.Background(), d, staticPath, tmp, keyCount, false, &background.Progress{}))
+	d.Close()
+	idx, err := recsplit.OpenIndex(staticPath)
+	require.NoError(t, err)
+	defer idx.Close()
+
+	o, err := OpenIndexOverlay(staticPath + IndexOverlayFileExt)
+	require.NoError(t, err)
+	defer o.Close()
+	replaced := appendPairs(t, dataPath, o, keyCount)
+	checkOverlayReader(t, dataPath, NewOverlayRecSplitReader(o, idx), replaced, keyCount)
+
+	d, err = compress.NewDecompressor(dataPath)
+	require.NoError(t, err)
+	defer d.Close()
+	compactedPath := filepath.Join(tmp, "compacted.kvi")
+	s, err := o.CompactRecSplit(context.Background(), d, uint64(keyCount), compactedPath, tmp, &background.Progress{})
+	require.NoError(t, err)
+	compacted, err := recsplit.OpenIndex(compactedPath)
+	require.NoError(t, err)
+	defer compacted.Close()
+	require.EqualValues(t, keyCount+50, compacted.KeyCount())
+	require.NoError(t, o.DropSnapshot(s))
+	require.Zero(t, o.Count())
+	checkOverlayReader(t, dataPath, NewOverlayRecSplitReader(o, compacted), replaced, keyCount)
+}
+
+func TestIndexOverlay_PutDuringCompaction(t *testing.T) {
+	tmp := t.TempDir()
+	keyCount := 1000
+	dataPath := generateCompressedKV(t, tmp, 52, 180, keyCount)
+	d, err := compress.NewDecompressor(dataPath)
+	require.NoError(t, err)
+	staticPath := filepath.Join(tmp, "static.kvi")
+	require.NoError(t, buildIndex(context.Background(), d, staticPath, tmp, keyCount, false, &background.Progress{}))
+	d.Close()
+
+	overlayPath := staticPath + IndexOverlayFileExt
+	o, err := OpenIndexOverlay(overlayPath)
+	require.NoError(t, err)
+	defer func() { o.Close() }()
+	replaced := appendPairs(t, dataPath, o, keyCount)
+
+	key := func(i int) []byte { return []byte(fmt.Sprintf("concurrent key %d", i)) }
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	var put int
+	var putErr error
+	go func() {
+		defer close(stopped)
+		for ; ; put++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if putErr = o.Put(key(put), uint64(put)); putErr != nil {
+				return
+			}
+		}
+	}()
+
+	d, err = compress.NewDecompressor(dataPath)
+	require.NoError(t, err)
+	defer d.Close()
+	compactedPath := filepath.Join(tmp, "compacted.kvi")
+	s, err := o.CompactRecSplit(context.Background(), d, uint64(keyCount), compactedPath, tmp, &background.Progress{})
+	close(stop)
+	<-stopped
+	require.NoError(t, err)
+	require.NoError(t, putErr)
+	// keys added after compaction, but before its snapshot is dropped
+	for i := put; i < put+10; i++ {
+		require.NoError(t, o.Put(key(i), uint64(i)))
+	}
+	put += 10
+	compacted, err := recsplit.OpenIndex(compactedPath)
+	require.NoError(t, err)
+	defer compacted.Close()
+	require.EqualValues(t, s.Count()+keyCount-len(replaced)+50, compacted.KeyCount())
+	require.NoError(t, o.DropSnapshot(s))
+
+	check := func() {
+		r := NewOverlayRecSplitReader(o, compacted)
+		checkOverlayReader(t, dataPath, r, replaced, keyCount)
+		var inOverlay int
+		for i := 0; i < put; i++ {
+			offset, ok := r.Lookup(key(i))
+			require.True(t, ok)
+			require.EqualValues(t, i, offset)
+			if _, ok = s.offsets[string(key(i))]; !ok {
+				_, ok = o.Get(key(i))
+				require.True(t, ok, "key added during compaction must stay in overlay")
+				inOverlay++
+			}
+		}
+		require.Equal(t, inOverlay, o.Count())
+	}
+	check()
+	require.NoError(t, o.Put(key(put), uint64(put)))
+	put++
+	require.NoError(t, o.Close())
+	o, err = OpenIndexOverlay(overlayPath)
+	require.NoError(t, err)
+	check()
+}
@@ -66,6 +66,8 @@ type InvertedIndex struct {
 	integrityFileExtensions []string
 	withLocalityIndex       bool
 	localityIndex           *LocalityIndex
+	withExistenceFilter     bool
+	efFilter                filterCounters // existence filters of .ef files
 	tx                      kv.RwTx
 
 	// fields for history write
@@ -283,6 +285,14 @@ func (ii *InvertedIndex) BuildMissedIndices(ctx context.Context, g *errgroup.Gro
 			return ii.buildEfi(ctx, item, p)
 		})
 	}
+	for _, item := range ii.missedExistenceFilterFiles(ii.files, "efei") {
+		item := item
+		g.Go(func() error {
+			fromStep, toStep := item.startTxNum/ii.aggregationStep, item.endTxNum/ii.aggregationStep
+			_, err := ii.buildExistence(ctx, item.decompressor, fromStep, toStep, "efei", ps)
+			return err
+		})
+	}
 }
 
 func (ii *InvertedIndex) openFiles() error {
@@ -292,6 +302,11 @@ func (ii *InvertedIndex) openFiles() error {
 	ii.files.Walk(func(items []*filesItem) bool {
 		for _, item := range items {
 			if item.decompressor != nil {
+				// existence filter may be built after .ef file was opened
+				if err = ii.openExistenceFilter(item, "efei"); err != nil {
+					log.Debug("InvertedIndex.openFiles: %w, %s", err, item.decompressor.FileName())
+					return false
+				}
 				continue
 			}
 			fromStep, toStep := item.startTxNum/ii.aggregationStep, item.endTxNum/ii.aggregationStep
@@ -317,6 +332,10 @@ func (ii *InvertedIndex) openFiles() error {
 				}
 				totalKeys += item.index.KeyCount()
 			}
+			if err = ii.openExistenceFilter(item, "efei"); err != nil {
+				log.Debug("InvertedIndex.openFiles: %w, %s", err, datPath)
+				return false
+			}
 		}
 		return true
 	})
@@ -358,6 +377,7 @@ func (ii *InvertedIndex) closeWhatNotInList(fNames []string) {
 			}
 			item.index = nil
 		}
+		item.existence = nil
 		ii.files.Delete(item)
 	}
 }
@@ -1146,8 +1166,9 @@ func (ii *InvertedIndex) collate(ctx context.Context, txFrom, txTo uint64, roTx
 }
 
 type InvertedFiles struct {
-	decomp *compress.Decompressor
-	index  *recsplit.Index
+	decomp    *compress.Decompressor
+	index     *recsplit.Index
+	existence *ExistenceFilter
 }
 
 func (sf InvertedFiles) Close() {
@@ -1229,8 +1250,14 @@ func (ii *InvertedIndex) buildFiles(ctx context.Context, step uint64, bitmaps ma
 	if index, err = buildIndexThenOpen(ctx, decomp, idxPath, ii.tmpdir, len(keys), false /* values */, p); err != nil {
 		return InvertedFiles{}, fmt.Errorf("build %s efi: %w", ii.filenameBase, err)
 	}
+	var existence *ExistenceFilter
+	if ii.withExistenceFilter {
+		if existence, err = ii.buildExistence(ctx, decomp, step, step+1, "efei", ps); err != nil {
+			return InvertedFiles{}, fmt.Errorf("build %s existence filter: %w", ii.filenameBase, err)
+		}
+	}
 	closeComp = false
-	return InvertedFiles{decomp: decomp, index: index}, nil
+	return InvertedFiles{decomp: decomp, index: index, existence: existence}, nil
 }
 
 func (ii *InvertedIndex) integrateFiles(sf InvertedFiles, txNumFrom, txNumTo uint64) {
@@ -1240,6 +1267,7 @@ func (ii *InvertedIndex) integrateFiles(sf InvertedFiles, txNumFrom, txNumTo uin
 		endTxNum:     txNumTo,
 		decompressor: sf.decomp,
 		index:        sf.index,
+		existence:    sf.existence,
 	})
 	ii.reCalcRoFiles()
 }
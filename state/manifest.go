@@ -0,0 +1,193 @@
+/*
+   Copyright 2022 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ledgerwatch/log/v3"
+)
+
+// Manifest - authoritative set of data files (.v, .ef, ...) of each component (History/InvertedIndex) in directory.
+// Files are first written to disk, then integrated in memory, then manifest is atomically replaced.
+// So after crash (`kill -9`) file of known component which is not in manifest, but overlaps with it's files, is orphan:
+// result of interrupted merge or small file which was already replaced by merged one.
+// OpenFolder of the writer removes orphans instead of guessing by filenames which files are useful, other processes skip them.
+// Old files replaced by merge are deleted only after manifest without them was written.
+
+const (
+	ManifestFileName = "manifest.json"
+	manifestVersion  = 1
+)
+
+type Manifest struct {
+	Version    uint64              `json:"version"`
+	Components map[string][]string `json:"components"` // filenameBase -> sorted list of data files
+}
+
+func NewManifest() *Manifest {
+	return &Manifest{Version: manifestVersion, Components: map[string][]string{}}
+}
+
+// Add - adds data files of component, index files are derived from data files names
+func (m *Manifest) Add(component string, fileNames ...string) {
+	files := append(m.Components[component], fileNames...)
+	slices.Sort(files)
+	m.Components[component] = slices.Compact(files)
+}
+
+// ReadManifest - returns nil if directory has no manifest (created by older version or empty)
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	m := &Manifest{}
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", ManifestFileName, err)
+	}
+	if m.Version != manifestVersion {
+		return nil, fmt.Errorf("%s: unsupported version %d, expected %d", ManifestFileName, m.Version, manifestVersion)
+	}
+	if m.Components == nil {
+		m.Components = map[string][]string{}
+	}
+	return m, nil
+}
+
+// Write - replaces manifest in directory: write to .tmp file, fsync, rename, fsync directory
+func (m *Manifest) Write(dir string) error {
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	fPath := filepath.Join(dir, ManifestFileName)
+	tmpPath := fPath + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", tmpPath, err)
+	}
+	defer f.Close()
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, fPath); err != nil {
+		return err
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// name.from-to.ext, where ext - data file or file derived from it
+var manifestFileRe = regexp.MustCompile(`^([a-z]+)\.([0-9]+)-([0-9]+)\.(v|vi|ef|efi|efei|kv|kvi|bt|kvei|vk|vkt)$`)
+
+var manifestDataExt = map[string]string{
+	"v": "v", "vi": "v",
+	"ef": "ef", "efi": "ef", "efei": "ef",
+	"kv": "kv", "kvi": "kv", "bt": "kv", "kvei": "kv", "vk": "kv", "vkt": "kv",
+}
+
+// isOrphan - file belongs to component of manifest, it's data file is not listed in manifest, but it's range
+// overlaps with files of manifest. Files which don't overlap with manifest (for example downloaded ones) are not orphans.
+func (m *Manifest) isOrphan(fName string) bool {
+	subs := manifestFileRe.FindStringSubmatch(fName)
+	if len(subs) != 5 {
+		return false
+	}
+	files, ok := m.Components[subs[1]]
+	if !ok {
+		return false
+	}
+	dataFile := fmt.Sprintf("%s.%s-%s.%s", subs[1], subs[2], subs[3], manifestDataExt[subs[4]])
+	if _, found := slices.BinarySearch(files, dataFile); found {
+		return false
+	}
+	from, to, ok := parseManifestRange(subs)
+	if !ok {
+		return false
+	}
+	for _, listed := range files {
+		lFrom, lTo, ok := parseManifestRange(manifestFileRe.FindStringSubmatch(listed))
+		if ok && from < lTo && lFrom < to {
+			return true
+		}
+	}
+	return false
+}
+
+func parseManifestRange(subs []string) (from, to uint64, ok bool) {
+	if len(subs) != 5 {
+		return 0, 0, false
+	}
+	var err error
+	if from, err = strconv.ParseUint(subs[2], 10, 64); err != nil {
+		return 0, 0, false
+	}
+	if to, err = strconv.ParseUint(subs[3], 10, 64); err != nil {
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+// skipOrphans - returns names of files which are not orphans, files on disk are not touched
+func (m *Manifest) skipOrphans(fNames []string) (res []string) {
+	res = make([]string, 0, len(fNames))
+	for _, fName := range fNames {
+		if !m.isOrphan(fName) {
+			res = append(res, fName)
+		}
+	}
+	return res
+}
+
+// removeOrphans - deletes orphan files from dir, returns names of remaining files
+func (m *Manifest) removeOrphans(dir string, fNames []string) (res []string) {
+	res = make([]string, 0, len(fNames))
+	for _, fName := range fNames {
+		if !m.isOrphan(fName) {
+			res = append(res, fName)
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, fName)); err != nil {
+			log.Warn("[snapshots] remove orphan file", "file", fName, "err", err)
+			continue
+		}
+		log.Debug("[snapshots] removed orphan file", "file", fName)
+	}
+	return res
+}
@@ -0,0 +1,248 @@
+package state
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
+
+	"github.com/gateway-fm/cdk-erigon-lib/kv"
+	"github.com/gateway-fm/cdk-erigon-lib/kv/mdbx"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	m, err := ReadManifest(dir)
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	m = NewManifest()
+	m.Add("accounts", "accounts.1-2.v", "accounts.0-1.v", "accounts.0-1.ef", "accounts.1-2.ef")
+	m.Add("code")
+	require.NoError(t, m.Write(dir))
+
+	m, err = ReadManifest(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"accounts.0-1.ef", "accounts.0-1.v", "accounts.1-2.ef", "accounts.1-2.v"}, m.Components["accounts"])
+	require.Contains(t, m.Components, "code")
+
+	onDisk := []string{
+		"accounts.0-1.v", "accounts.0-1.vi", "accounts.0-1.ef", "accounts.0-1.efi", "accounts.1-2.v", "accounts.1-2.ef",
+		"accounts.0-2.v", "accounts.0-2.vi", "accounts.0-2.ef", // interrupted merge
+		"accounts.2-3.ef", "accounts.2-3.efi", // doesn't overlap with manifest: downloaded or built before manifest
+		"code.0-1.v",      // component without files in manifest
+		"storage.0-2.v",   // component not in manifest
+		"accounts.0-2.li", // not a file of component
+		ManifestFileName,
+	}
+	for _, fName := range onDisk {
+		if fName == ManifestFileName {
+			continue
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fName), nil, 0644))
+	}
+	kept := m.removeOrphans(dir, onDisk)
+	require.Equal(t, []string{
+		"accounts.0-1.v", "accounts.0-1.vi", "accounts.0-1.ef", "accounts.0-1.efi", "accounts.1-2.v", "accounts.1-2.ef",
+		"accounts.2-3.ef", "accounts.2-3.efi",
+		"code.0-1.v",
+		"storage.0-2.v",
+		"accounts.0-2.li",
+		ManifestFileName,
+	}, kept)
+	for _, fName := range []string{"accounts.0-2.v", "accounts.0-2.vi", "accounts.0-2.ef"} {
+		_, err = os.Stat(filepath.Join(dir, fName))
+		require.ErrorIs(t, err, os.ErrNotExist, fName)
+	}
+
+	// unknown version must not be silently ignored
+	m.Version = manifestVersion + 1
+	require.NoError(t, m.Write(dir))
+	_, err = ReadManifest(dir)
+	require.Error(t, err)
+}
+
+func TestAggregatorV3_OpenFolderWritesManifest(t *testing.T) {
+	dir := t.TempDir()
+	agg, err := NewAggregatorV3(context.Background(), dir, t.TempDir(), 16, nil)
+	require.NoError(t, err)
+	defer agg.Close()
+	// only files writer touches manifest
+	require.NoError(t, agg.OpenFolder())
+	_, err = os.Stat(filepath.Join(dir, ManifestFileName))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	agg.SetFilesWriter(true)
+	require.NoError(t, agg.OpenFolder())
+
+	m, err := ReadManifest(dir)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	for _, component := range []string{"accounts", "storage", "code", "logaddrs", "logtopics", "tracesfrom", "tracesto"} {
+		require.Contains(t, m.Components, component)
+		require.Empty(t, m.Components[component])
+	}
+}
+
+// testAggregatorV3WithFiles - aggregator (files writer) with history of `steps` steps in files, which are not merged yet
+func testAggregatorV3WithFiles(t *testing.T, steps uint64) (string, kv.RwDB, *AggregatorV3) {
+	t.Helper()
+	ctx, path := context.Background(), t.TempDir()
+	db := mdbx.NewMDBX(log.New()).InMem(filepath.Join(path, "db")).WithTableCfg(func(defaultBuckets kv.TableCfg) kv.TableCfg {
+		return kv.ChaindataTablesCfg
+	}).MustOpen()
+	t.Cleanup(db.Close)
+	dir := filepath.Join(path, "snapshots")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	agg, err := NewAggregatorV3(ctx, dir, t.TempDir(), 16, db)
+	require.NoError(t, err)
+	t.Cleanup(agg.Close)
+	agg.SetFilesWriter(true)
+	require.NoError(t, agg.OpenFolder())
+
+	tx, err := db.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	agg.SetTx(tx)
+	agg.StartWrites()
+	for txNum := uint64(0); txNum < (steps+1)*agg.aggregationStep; txNum++ {
+		agg.SetTxNum(txNum)
+		addr, prev := make([]byte, 20), make([]byte, 8)
+		binary.BigEndian.PutUint64(addr[12:], txNum%7)
+		binary.BigEndian.PutUint64(prev, txNum)
+		require.NoError(t, agg.AddAccountPrev(addr, prev))
+		require.NoError(t, agg.AddLogAddr(addr))
+	}
+	require.NoError(t, agg.Flush(ctx, tx))
+	agg.FinishWrites()
+	require.NoError(t, tx.Commit())
+
+	for step := uint64(0); step < steps; step++ {
+		require.NoError(t, agg.buildFilesInBackground(ctx, step))
+	}
+	return dir, db, agg
+}
+
+func copyDir(t *testing.T, from, to string) {
+	t.Helper()
+	entries, err := os.ReadDir(from)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(to, 0755))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(from, e.Name()))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(to, e.Name()), data, 0644))
+	}
+}
+
+func openAggregatorV3(t *testing.T, dir string, writer bool) *AggregatorV3 {
+	t.Helper()
+	agg, err := NewAggregatorV3(context.Background(), dir, t.TempDir(), 16, nil)
+	require.NoError(t, err)
+	t.Cleanup(agg.Close)
+	agg.SetFilesWriter(writer)
+	require.NoError(t, agg.OpenFolder())
+	return agg
+}
+
+func TestAggregatorV3_CrashDuringMerge(t *testing.T) {
+	ctx := context.Background()
+	dir, _, agg := testAggregatorV3WithFiles(t, 4)
+	small := agg.Files()
+	manifestBefore, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	require.NoError(t, err)
+
+	// reader holds replaced files - they stay on disk after merge is integrated, like in the moment of crash
+	ac := agg.MakeContext()
+	require.NoError(t, agg.MergeLoop(ctx, 1))
+	merged := agg.Files()
+	require.NotEqual(t, small, merged)
+	crashed := filepath.Join(t.TempDir(), "crashed")
+	copyDir(t, dir, crashed)
+	ac.Close()
+	for _, fName := range small {
+		if !slices.Contains(merged, fName) {
+			_, err = os.Stat(filepath.Join(dir, fName))
+			require.ErrorIs(t, err, os.ErrNotExist, fName)
+		}
+	}
+
+	// crash after manifest was written: replaced files are orphans
+	afterManifest := filepath.Join(t.TempDir(), "after")
+	copyDir(t, crashed, afterManifest)
+	reader := openAggregatorV3(t, afterManifest, false)
+	require.Equal(t, merged, reader.Files())
+	for _, fName := range small {
+		_, err = os.Stat(filepath.Join(afterManifest, fName))
+		require.NoError(t, err, "reader must not remove files")
+	}
+	require.Equal(t, merged, openAggregatorV3(t, afterManifest, true).Files())
+	for _, fName := range small {
+		if !slices.Contains(merged, fName) {
+			_, err = os.Stat(filepath.Join(afterManifest, fName))
+			require.ErrorIs(t, err, os.ErrNotExist, fName)
+		}
+	}
+
+	// crash before manifest was written: merged files are orphans
+	beforeManifest := filepath.Join(t.TempDir(), "before")
+	copyDir(t, crashed, beforeManifest)
+	require.NoError(t, os.WriteFile(filepath.Join(beforeManifest, ManifestFileName), manifestBefore, 0644))
+	require.Equal(t, small, openAggregatorV3(t, beforeManifest, true).Files())
+	for _, fName := range merged {
+		if !slices.Contains(small, fName) {
+			_, err = os.Stat(filepath.Join(beforeManifest, fName))
+			require.ErrorIs(t, err, os.ErrNotExist, fName)
+		}
+	}
+}
+
+func TestAggregatorV3_ManifestWriteFailure(t *testing.T) {
+	ctx := context.Background()
+	dir, db, agg := testAggregatorV3WithFiles(t, 4)
+	small := agg.Files()
+
+	// manifest can't be written while it's temporary file is a directory
+	blocker := filepath.Join(dir, ManifestFileName+".tmp")
+	require.NoError(t, os.Mkdir(blocker, 0755))
+	ac := agg.MakeContext()
+	require.Error(t, agg.MergeLoop(ctx, 1))
+	ac.Close()
+	for _, fName := range small {
+		_, err := os.Stat(filepath.Join(dir, fName))
+		require.NoError(t, err, "replaced files must stay until manifest is written")
+	}
+
+	// prune must not remove data from DB before manifest lists files which have it
+	tx, err := db.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	agg.SetTx(tx)
+	require.Error(t, agg.Prune(ctx, 1_000))
+	require.NoError(t, os.Remove(blocker))
+	require.NoError(t, agg.Prune(ctx, 1_000))
+	m, err := ReadManifest(dir)
+	require.NoError(t, err)
+	require.Equal(t, agg.manifest(), m)
+
+	// replaced files are deleted once manifest is written
+	merged := agg.Files()
+	var replaced int
+	for _, fName := range small {
+		if slices.Contains(merged, fName) {
+			continue
+		}
+		replaced++
+		_, err := os.Stat(filepath.Join(dir, fName))
+		require.ErrorIs(t, err, os.ErrNotExist, fName)
+	}
+	require.NotZero(t, replaced)
+	require.Equal(t, agg.Files(), openAggregatorV3(t, dir, true).Files())
+}
@@ -481,6 +481,12 @@ func (d *Domain) mergeFiles(ctx context.Context, valuesFiles, indexFiles, histor
 				if valuesIn.bindex != nil {
 					valuesIn.bindex.Close()
 				}
+				if valuesIn.valIdxBt != nil {
+					valuesIn.valIdxBt.Close()
+				}
+				if valuesIn.valIdxDecomp != nil {
+					valuesIn.valIdxDecomp.Close()
+				}
 			}
 		}
 	}()
@@ -630,6 +636,19 @@ func (d *Domain) mergeFiles(ctx context.Context, valuesFiles, indexFiles, histor
 			return nil, nil, nil, fmt.Errorf("merge %s btindex2 [%d-%d]: %w", d.filenameBase, r.valuesStartTxNum, r.valuesEndTxNum, err)
 		}
 		valuesIn.bindex = bt
+
+		if d.valueProjection != nil {
+			fromStep, toStep := r.valuesStartTxNum/d.aggregationStep, r.valuesEndTxNum/d.aggregationStep
+			if valuesIn.valIdxDecomp, valuesIn.valIdxBt, err = d.buildValueIdx(ctx, valuesIn.decompressor, fromStep, toStep, ps); err != nil {
+				return nil, nil, nil, fmt.Errorf("merge %s value idx [%d-%d]: %w", d.filenameBase, r.valuesStartTxNum, r.valuesEndTxNum, err)
+			}
+		}
+		if d.withExistenceFilter {
+			fromStep, toStep := r.valuesStartTxNum/d.aggregationStep, r.valuesEndTxNum/d.aggregationStep
+			if valuesIn.existence, err = d.buildExistence(ctx, valuesIn.decompressor, fromStep, toStep, "kvei", ps); err != nil {
+				return nil, nil, nil, fmt.Errorf("merge %s existence filter [%d-%d]: %w", d.filenameBase, r.valuesStartTxNum, r.valuesEndTxNum, err)
+			}
+		}
 	}
 	closeItem = false
 	d.stats.MergesCount++
@@ -771,6 +790,11 @@ func (ii *InvertedIndex) mergeFiles(ctx context.Context, files []*filesItem, sta
 	if outItem.index, err = buildIndexThenOpen(ctx, outItem.decompressor, idxPath, ii.tmpdir, keyCount, false /* values */, p); err != nil {
 		return nil, fmt.Errorf("merge %s buildIndex [%d-%d]: %w", ii.filenameBase, startTxNum, endTxNum, err)
 	}
+	if ii.withExistenceFilter {
+		if outItem.existence, err = ii.buildExistence(ctx, outItem.decompressor, startTxNum/ii.aggregationStep, endTxNum/ii.aggregationStep, "efei", ps); err != nil {
+			return nil, fmt.Errorf("merge %s existence filter [%d-%d]: %w", ii.filenameBase, startTxNum, endTxNum, err)
+		}
+	}
 	closeItem = false
 	return outItem, nil
 }